@@ -1,21 +1,35 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/devfacet/gocmd/v3"
 
 	"subscalpelmkv/internal/batch"
 	"subscalpelmkv/internal/cli"
 	"subscalpelmkv/internal/config"
+	"subscalpelmkv/internal/docs"
 	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/history"
+	"subscalpelmkv/internal/hook"
+	"subscalpelmkv/internal/i18n"
+	"subscalpelmkv/internal/lock"
 	"subscalpelmkv/internal/mkv"
 	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/naming"
+	"subscalpelmkv/internal/notify"
+	"subscalpelmkv/internal/postprocess"
+	"subscalpelmkv/internal/subtitle"
 	"subscalpelmkv/internal/util"
 )
 
@@ -27,7 +41,8 @@ const (
 var Version = "1.1.0"
 
 // processFile handles the actual subtitle extraction logic
-func processFile(inputFileName, languageFilter, exclusionFilter string, showFilterMessage bool, outputConfig model.OutputConfig, dryRun bool) error {
+func processFile(ctx context.Context, inputFileName, languageFilter, exclusionFilter string, showFilterMessage bool, outputConfig model.OutputConfig, dryRun bool) (model.FileResult, error) {
+	var result model.FileResult
 	var selection model.TrackSelection
 	if languageFilter != "" {
 		selection = cli.ParseTrackSelection(languageFilter)
@@ -35,44 +50,270 @@ func processFile(inputFileName, languageFilter, exclusionFilter string, showFilt
 
 	// Parse exclusions if provided
 	if exclusionFilter != "" {
-		selection.Exclusions = cli.ParseTrackExclusion(exclusionFilter)
+		selection.Exclusions = model.MergeTrackExclusions(selection.Exclusions, cli.ParseTrackExclusion(exclusionFilter))
 	}
+	selection.Filter = outputConfig.TrackFilter
 
-	// Display unified filter message
-	if showFilterMessage {
+	// Display unified filter message; with a recipe it follows once the recipe's tracks are known
+	if showFilterMessage && outputConfig.Recipe == nil {
 		displayFilterMessage(selection, selection.Exclusions)
 	}
 
 	if _, statErr := os.Stat(inputFileName); os.IsNotExist(statErr) {
 		format.PrintError(fmt.Sprintf("File does not exist: %s", inputFileName))
-		return statErr
+		return result, statErr
 	}
 	if !util.IsMKVFile(inputFileName) {
-		format.PrintError(fmt.Sprintf("File is not an MKV file: %s", inputFileName))
-		return errors.New("file is not an MKV file")
+		format.PrintError(fmt.Sprintf("File is not an MKV or WebM file: %s", inputFileName))
+		return result, errors.New("file is not an MKV or WebM file")
+	}
+
+	// Files still being copied or downloaded would be analyzed half-written
+	if outputConfig.StableFor > 0 && !dryRun && outputConfig.SourceURL == "" {
+		if err := util.WaitUntilStable(ctx, inputFileName, outputConfig.StableFor); err != nil {
+			format.PrintError(fmt.Sprintf("Error waiting for %s: %v", filepath.Base(inputFileName), err))
+			return result, err
+		}
+	}
+
+	// Hooks see the original URL of downloaded inputs rather than the temporary file
+	sourceName := inputFileName
+	if outputConfig.SourceURL != "" {
+		sourceName = outputConfig.SourceURL
 	}
 
 	// Step 0: Get original track information to preserve track numbers
-	originalMkvInfo, err := mkv.GetTrackInfo(inputFileName)
+	originalMkvInfo, err := mkv.GetTrackInfo(ctx, inputFileName)
 	if err != nil {
 		format.PrintError(fmt.Sprintf("Error analyzing original file: %v", err))
-		return err
+		return result, err
+	}
+
+	// skipTrack leaves out a track the selection matched, recording why for the batch dry-run tree
+	skipTrack := func(track model.MKVTrack, reason string) {
+		result.Plan = append(result.Plan, model.TrackPlan{Track: track, Reason: reason})
+		if !outputConfig.PlanOnly {
+			format.PrintInfo(fmt.Sprintf("Skipping track %d (%s): %s", track.Properties.Number, track.Properties.Language, reason))
+		}
+		selection.Exclusions.TrackNumbers = append(selection.Exclusions.TrackNumbers, track.Properties.Number)
+	}
+
+	// Coverage compares each track with this file's duration, so its filter is built per file
+	var coverageFilter *model.TrackFilter
+	containerDuration := time.Duration(originalMkvInfo.Container.Properties.Duration)
+	if outputConfig.MinCoverage > 0 {
+		coverageFilter = model.MinCoverageFilter(outputConfig.MinCoverage, containerDuration)
+	}
+
+	// Tracks without a language tag meet language filters as --und-policy says
+	undPolicy, undLanguage := model.ParseUndPolicy(outputConfig.UndPolicy)
+	switch undPolicy {
+	case model.UndPolicyInclude:
+		selection.IncludeUntagged = true
+	case model.UndPolicyTreatAs:
+		for i, track := range originalMkvInfo.Tracks {
+			if track.IsUntagged() {
+				originalMkvInfo.Tracks[i].Properties.Language = undLanguage
+				originalMkvInfo.Tracks[i].Properties.LanguageIETF = ""
+			}
+		}
+	}
+
+	// A recipe picks the tracks itself; exclusions and --filter still narrow them
+	if outputConfig.Recipe != nil {
+		recipeTracks, missing := outputConfig.Recipe.Resolve(originalMkvInfo.Tracks)
+		for _, entry := range missing {
+			format.PrintWarning(fmt.Sprintf("Recipe track %s has no match in %s", entry, filepath.Base(inputFileName)))
+		}
+		if len(recipeTracks) == 0 {
+			format.PrintError("None of the recipe's tracks were found")
+			return result, errors.New("none of the recipe's tracks were found")
+		}
+		selection.LanguageCodes, selection.FormatFilters, selection.StatusFilters, selection.TrackNumbers = nil, nil, nil, nil
+		for _, track := range recipeTracks {
+			selection.TrackNumbers = append(selection.TrackNumbers, track.Properties.Number)
+		}
+		if showFilterMessage {
+			displayFilterMessage(selection, selection.Exclusions)
+		}
 	}
 
 	// Create an ordered list of original tracks that match the selection criteria
 	// This preserves the order in which tracks appear in the original file
 	var selectedOriginalTracks []model.MKVTrack
+	skippedExisting := 0
+	filtered := 0 // Tracks dropped by --min-coverage, --per-language and --max-per-language
 	for _, track := range originalMkvInfo.Tracks {
-		if track.Type == "subtitles" && util.MatchesTrackSelection(track, selection) {
+		if track.Type != "subtitles" && outputConfig.AllTracks {
+			// --all-tracks demuxes video and audio too; the selection only narrows the subtitles
 			selectedOriginalTracks = append(selectedOriginalTracks, track)
+			continue
+		}
+		if track.Type != "subtitles" {
+			continue
+		}
+		if !util.MatchesTrackSelection(track, selection) {
+			if undPolicy == model.UndPolicyPrompt && !outputConfig.PlanOnly && util.MatchesUntaggedTrack(track, selection) && cli.AskIncludeUntagged(track) {
+				selectedOriginalTracks = append(selectedOriginalTracks, track)
+				continue
+			}
+			result.Plan = append(result.Plan, model.TrackPlan{Track: track, Reason: util.SelectionMissReason(track, selection)})
+			continue
+		}
+		if coverageFilter != nil && !coverageFilter.Matches(track) {
+			trackDuration, _ := track.TagDuration()
+			skipTrack(track, fmt.Sprintf("covers only %d%% of the file", int(trackDuration*100/containerDuration)))
+			filtered++
+			continue
+		}
+		// Skip tracks already covered by an external subtitle file
+		if outputConfig.RespectExisting {
+			if existing := util.FindExistingSidecar(inputFileName, track, outputConfig); existing != "" {
+				skipTrack(track, fmt.Sprintf("found existing %s", filepath.Base(existing)))
+				skippedExisting++
+				continue
+			}
+		}
+		selectedOriginalTracks = append(selectedOriginalTracks, track)
+	}
+
+	if outputConfig.PerLanguage != "" {
+		var dropped []model.MKVTrack
+		selectedOriginalTracks, dropped = util.PickPerLanguage(selectedOriginalTracks, outputConfig.PerLanguage)
+		for _, track := range dropped {
+			skipTrack(track, fmt.Sprintf("not picked by --per-language %s", outputConfig.PerLanguage))
+			filtered++
+		}
+	}
+
+	// The cap applies last, so it counts only the tracks every other filter let through
+	if outputConfig.MaxPerLanguage > 0 {
+		var dropped []model.MKVTrack
+		selectedOriginalTracks, dropped = util.CapPerLanguage(selectedOriginalTracks, outputConfig.MaxPerLanguage)
+		for _, track := range dropped {
+			skipTrack(track, fmt.Sprintf("over --max-per-language %d", outputConfig.MaxPerLanguage))
+			filtered++
+		}
+	}
+
+	if outputConfig.SaveRecipe != "" {
+		recipe := model.NewRecipe(filepath.Base(sourceName), originalMkvInfo.Tracks, selectedOriginalTracks)
+		if err := util.SaveRecipe(outputConfig.SaveRecipe, recipe); err != nil {
+			format.PrintError(err.Error())
+			return result, err
+		}
+		format.PrintSuccess(fmt.Sprintf("Saved a recipe of %d track(s) to %s", len(recipe.Tracks), outputConfig.SaveRecipe))
+	}
+
+	// Another instance working on the same file would collide on the temporary .mks and the outputs.
+	// The lock is taken before the existing outputs and the history are checked, so an instance that
+	// finishes in between cannot have its outputs overwritten without --force.
+	if !dryRun {
+		fileLock, lockErr := lock.Acquire(inputFileName)
+		var heldErr *lock.HeldError
+		if errors.As(lockErr, &heldErr) {
+			format.PrintWarning(fmt.Sprintf("Skipping %s: %v", filepath.Base(inputFileName), heldErr))
+			result.Skipped = filtered + skippedExisting + len(selectedOriginalTracks)
+			result.AddPlan(selectedOriginalTracks, heldErr.Error())
+			return result, nil
+		}
+		if lockErr != nil {
+			format.PrintWarning(fmt.Sprintf("Could not lock %s, processing it anyway: %v", filepath.Base(inputFileName), lockErr))
+		} else {
+			defer fileLock.Release()
+		}
+	}
+
+	// Existing files at the output paths are only replaced with --force, since they may have been edited.
+	// Tracks the template names alike, as naming presets do for tracks of one language, would overwrite
+	// each other, so the first of them is kept.
+	conflicts := 0
+	firstTrackByName := make(map[string]int)
+	var kept []model.MKVTrack
+	for _, track := range selectedOriginalTracks {
+		outFileName := util.BuildSubtitlesFileNameWithConfig(inputFileName, track, outputConfig)
+		if first, taken := firstTrackByName[outFileName]; taken {
+			skipTrack(track, fmt.Sprintf("would overwrite the output of track %d (%s)", first, filepath.Base(outFileName)))
+			conflicts++
+			continue
+		}
+		firstTrackByName[outFileName] = track.Properties.Number
+		if !outputConfig.Force {
+			if existing := util.ExistingOutput(inputFileName, track, outputConfig); existing != "" {
+				skipTrack(track, fmt.Sprintf("%s already exists (--force overwrites it)", filepath.Base(existing)))
+				conflicts++
+				continue
+			}
+		}
+		kept = append(kept, track)
+	}
+	selectedOriginalTracks = kept
+
+	result.Skipped = filtered + skippedExisting + conflicts
+
+	if skippedExisting > 0 && len(selectedOriginalTracks) == 0 {
+		format.PrintInfo("All selected tracks already have external subtitle files - nothing to extract")
+		return result, nil
+	}
+	if conflicts > 0 && len(selectedOriginalTracks) == 0 {
+		format.PrintInfo("All selected tracks would overwrite existing files - nothing to extract (use --force to overwrite)")
+		return result, nil
+	}
+
+	// Skip files whose selected tracks were all extracted by an earlier run, if the file is unchanged.
+	// Downloads are never recorded since their temporary file is gone after the run.
+	if outputConfig.SkipProcessed && outputConfig.SourceURL == "" && len(selectedOriginalTracks) > 0 {
+		trackNumbers := make([]int, len(selectedOriginalTracks))
+		for i, track := range selectedOriginalTracks {
+			trackNumbers[i] = track.Properties.Number
+		}
+
+		entries, historyErr := history.Load()
+		if historyErr != nil {
+			format.PrintWarning(fmt.Sprintf("Could not read processing history: %v", historyErr))
+		} else if entry, found := history.FindProcessed(entries, inputFileName, trackNumbers); found {
+			reason := fmt.Sprintf("already processed on %s", entry.ProcessedAt.Local().Format("2006-01-02 15:04"))
+			format.PrintInfo(fmt.Sprintf("Skipping %s: %s", filepath.Base(inputFileName), reason))
+			result.Skipped += len(selectedOriginalTracks)
+			result.AddPlan(selectedOriginalTracks, reason)
+			return result, nil
+		}
+	}
+
+	// --stdout has room for exactly one file
+	if outputConfig.Stdout {
+		if len(selectedOriginalTracks) != 1 {
+			format.PrintError(fmt.Sprintf("--stdout needs a selection matching exactly one subtitle track, but %d match", len(selectedOriginalTracks)))
+			return result, errors.New("--stdout needs exactly one track")
+		}
+		if selectedOriginalTracks[0].Properties.CodecId == "S_VOBSUB" {
+			format.PrintError("VobSub tracks are written as two files (.idx and .sub) and cannot be sent to stdout")
+			return result, errors.New("--stdout does not support VobSub tracks")
 		}
 	}
 
 	// For dry run mode, show what would be extracted without actually doing it
 	if dryRun {
+		result.Extracted = len(selectedOriginalTracks) // Planned rather than extracted
+		unknownSizes := 0
+		for _, track := range selectedOriginalTracks {
+			if size, ok := track.EstimatedOutputSize(); ok {
+				result.OutputBytes += size
+			} else {
+				unknownSizes++
+			}
+		}
+		if outputConfig.PlanOnly {
+			// The batch summary shows the plans of all files as one tree
+			for _, track := range selectedOriginalTracks {
+				result.Plan = append(result.Plan, model.TrackPlan{Track: track, OutFileName: util.BuildSubtitlesFileNameWithConfig(inputFileName, track, outputConfig)})
+			}
+			return result, nil
+		}
+
 		if len(selectedOriginalTracks) == 0 {
 			format.PrintWarning("No subtitle tracks match the selection criteria")
-			return nil
+			return result, nil
 		}
 
 		format.PrintSubSection("Dry Run")
@@ -80,10 +321,11 @@ func processFile(inputFileName, languageFilter, exclusionFilter string, showFilt
 
 		for _, track := range selectedOriginalTracks {
 			outFileName := util.BuildSubtitlesFileNameWithConfig(inputFileName, track, outputConfig)
+			result.Plan = append(result.Plan, model.TrackPlan{Track: track, OutFileName: outFileName})
 
 			// Get codec type for display
 			codecType := "Unknown"
-			if ext, exists := model.SubtitleExtensionByCodec[track.Properties.CodecId]; exists {
+			if ext := model.TrackExtension(track.Properties.CodecId); ext != "" {
 				codecType = strings.ToUpper(ext)
 			}
 
@@ -106,74 +348,301 @@ func processFile(inputFileName, languageFilter, exclusionFilter string, showFilt
 			format.BaseHighlight.Print("▪")
 			fmt.Print(" ")
 			format.BaseFg.Println(fmt.Sprintf("%s [%s]", trackDetails, strings.Join(attributes, ", ")))
-			format.PrintExample(fmt.Sprintf("    → %s", outFileName))
+			if size, ok := track.EstimatedOutputSize(); ok {
+				format.PrintExample(fmt.Sprintf("    → %s (≈ %s)", outFileName, util.FormatSize(size)))
+			} else {
+				format.PrintExample(fmt.Sprintf("    → %s", outFileName))
+			}
+			if outputConfig.PostHook != "" {
+				hookCtx := hook.NewPostHookContext(sourceName, track, outFileName)
+				format.PrintExample(fmt.Sprintf("    ↳ %s", hook.BuildPostHookCommand(outputConfig.PostHook, hookCtx)))
+			}
 		}
 
-		return nil
+		// Estimates come from the statistics tags mkvmerge writes; older files may lack them
+		switch {
+		case unknownSizes == 0:
+			format.PrintInfo(fmt.Sprintf("Estimated output size: %s", util.FormatSize(result.OutputBytes)))
+		case unknownSizes < len(selectedOriginalTracks):
+			format.PrintInfo(fmt.Sprintf("Estimated output size: at least %s (%d track(s) without statistics tags)", util.FormatSize(result.OutputBytes), unknownSizes))
+		}
+
+		if outputConfig.Backup {
+			existing := 0
+			for _, track := range selectedOriginalTracks {
+				if util.ExistingOutput(inputFileName, track, outputConfig) != "" {
+					existing++
+				}
+			}
+			if existing > 0 {
+				format.PrintInfo(fmt.Sprintf("%d existing file(s) would be backed up before being overwritten", existing))
+			}
+		}
+		if len(outputConfig.CleanupRules) > 0 {
+			format.PrintInfo(fmt.Sprintf("Cues matching %d cleanup rule(s) would be removed from text tracks ('subscalpelmkv cleanup --dry-run <file>' previews existing files)", len(outputConfig.CleanupRules)))
+		}
+		if outputConfig.StripHI == postprocess.StripHICopy {
+			format.PrintInfo(fmt.Sprintf("A %s copy without hearing-impaired annotations would be written next to each text track", postprocess.StripHISuffix))
+		} else if outputConfig.StripHI == postprocess.StripHIReplace {
+			format.PrintInfo("Hearing-impaired annotations would be removed from text tracks")
+		}
+		if outputConfig.FixLanguages {
+			format.PrintInfo("The language of text tracks would be checked against their tags, and und tracks tagged with the detected language in the source file")
+		} else if outputConfig.VerifyLanguage {
+			format.PrintInfo("The language of text tracks would be checked against their tags")
+		}
+		if outputConfig.SyncToAudio {
+			format.PrintInfo("Text tracks would be synchronized to the speech of the default audio track")
+		}
+		if outputConfig.ConvertTo == postprocess.ConvertASS {
+			if outputConfig.StyleTemplate != "" {
+				format.PrintInfo(fmt.Sprintf("SRT tracks would be converted to ASS using the styles of %s", outputConfig.StyleTemplate))
+			} else {
+				format.PrintInfo("SRT tracks would be converted to ASS")
+			}
+		}
+		if outputConfig.SplitByChapters {
+			chapterCount := 0
+			for _, edition := range originalMkvInfo.Chapters {
+				chapterCount += edition.NumEntries
+			}
+			if chapterCount > 0 {
+				format.PrintInfo(fmt.Sprintf("Text tracks would be split into up to %d chapter file(s) each", chapterCount))
+			} else {
+				format.PrintWarning("The file has no chapters, subtitles would be kept whole")
+			}
+		}
+		if outputConfig.PreHook != "" {
+			format.PrintInfo("The pre-hook would run before extraction and could skip this file")
+		}
+		if outputConfig.AllTracks {
+			if len(originalMkvInfo.Attachments) > 0 {
+				format.PrintInfo(fmt.Sprintf("%d attachment(s) would be extracted to an attachments/ directory", len(originalMkvInfo.Attachments)))
+			}
+			if len(originalMkvInfo.Chapters) > 0 {
+				format.PrintInfo("Chapters would be extracted to " + naming.TrimExtension(filepath.Base(inputFileName)) + ".chapters.xml")
+			}
+		} else if !outputConfig.NoFonts && postprocess.HasFontAttachments(originalMkvInfo) {
+			for _, track := range selectedOriginalTracks {
+				trackFormat := model.GetSubtitleFormatFromCodec(track.Properties.CodecId)
+				if trackFormat == "ass" || trackFormat == "ssa" {
+					format.PrintInfo("Font attachments would be extracted to a fonts/ directory next to the ASS/SSA files")
+					break
+				}
+			}
+		}
+
+		return result, nil
+	}
+
+	// Give the pre-hook a chance to veto processing of this file
+	if outputConfig.PreHook != "" && len(selectedOriginalTracks) > 0 {
+		payload := hook.PreHookPayload{Source: sourceName}
+		for _, track := range selectedOriginalTracks {
+			outFileName := util.BuildSubtitlesFileNameWithConfig(inputFileName, track, outputConfig)
+			payload.Tracks = append(payload.Tracks, hook.NewPreHookTrack(track, outFileName))
+		}
+
+		proceed, hookErr := hook.RunPreHook(ctx, outputConfig.PreHook, payload)
+		if hookErr != nil {
+			format.PrintError(hookErr.Error())
+			return result, hookErr
+		}
+		if !proceed {
+			format.PrintWarning(fmt.Sprintf("Skipped by pre-hook: %s", filepath.Base(inputFileName)))
+			result.Skipped += len(selectedOriginalTracks)
+			result.AddPlan(selectedOriginalTracks, "skipped by pre-hook")
+			return result, nil
+		}
+	}
+
+	// Keep one previous version of the files --force is about to overwrite
+	if outputConfig.Backup {
+		for _, track := range selectedOriginalTracks {
+			backups, backupErr := util.BackupExistingOutput(inputFileName, track, outputConfig)
+			if backupErr != nil {
+				format.PrintError(fmt.Sprintf("Error backing up existing output: %v", backupErr))
+				return result, backupErr
+			}
+			for _, backup := range backups {
+				format.PrintInfo(fmt.Sprintf("Backed up existing file to %s", backup))
+			}
+		}
+	}
+
+	var jobs, mediaJobs []model.ExtractionJob
+	if outputConfig.AllTracks {
+		// Demuxing everything gains nothing from the subtitle-only .mks, so extract from the source directly
+		fmt.Println()
+		format.PrintStep(1, "Demuxing all tracks, attachments and chapters...")
+		demuxJobs, demuxErr := mkv.DemuxAllTracks(ctx, inputFileName, originalMkvInfo, selectedOriginalTracks, outputConfig)
+		if demuxErr != nil {
+			return result, demuxErr
+		}
+		// Subtitle post-processing below only sees the subtitle tracks
+		for _, job := range demuxJobs {
+			if job.OriginalTrack.Type == "subtitles" {
+				jobs = append(jobs, job)
+			} else {
+				mediaJobs = append(mediaJobs, job)
+			}
+		}
+	} else {
+		extractedJobs, extractErr := extractSubtitleTracks(ctx, inputFileName, selectedOriginalTracks, outputConfig)
+		if extractErr != nil {
+			return result, extractErr
+		}
+		jobs = extractedJobs
+	}
+	result.Extracted = len(mediaJobs) + len(jobs)
+
+	step := 3
+
+	// Files written by the post-processing steps below use the requested line endings and BOM
+	subtitle.SetTextFormat(subtitle.TextFormat{CRLF: outputConfig.LineEndings == model.LineEndingsCRLF, BOM: outputConfig.BOM})
+
+	// Run the post-processors over the extracted subtitles, in the order of post_processors
+	pipeline := &postprocess.Pipeline{InputFileName: inputFileName, MKVInfo: originalMkvInfo, Config: outputConfig, Jobs: jobs}
+	step, err = pipeline.Run(ctx, step)
+	if err != nil {
+		return result, err
+	}
+	jobs = pipeline.Jobs
+
+	// Hooks and the history cover the demuxed video and audio tracks too
+	jobs = append(mediaJobs, jobs...)
+
+	// Measure the outputs before the post-hook, which may move them
+	result.Outputs = outputFiles(jobs)
+	for _, output := range result.Outputs {
+		result.OutputBytes += output.Bytes
+	}
+
+	// Run the post-extraction hook for each extracted file
+	if outputConfig.PostHook != "" {
+		fmt.Println()
+		format.PrintStep(step, "Running post-extraction hook...")
+		if hookErr := hook.RunPostHooks(ctx, outputConfig.PostHook, sourceName, jobs); hookErr != nil {
+			format.PrintError(hookErr.Error())
+			return result, hookErr
+		}
+	}
+
+	// Remember the file so later runs can skip it while it is unchanged; stdout leaves no files to remember
+	if outputConfig.SourceURL == "" && !outputConfig.Stdout {
+		if entry, historyErr := history.NewEntry(inputFileName, jobs); historyErr == nil {
+			historyErr = history.Record(entry)
+			if historyErr != nil {
+				format.PrintWarning(fmt.Sprintf("Could not update processing history: %v", historyErr))
+			}
+		}
 	}
 
+	return result, nil
+}
+
+// extractSubtitleTracks remuxes the selected subtitle tracks into a temporary .mks and extracts them from it,
+// which is much faster than extracting from the full MKV
+func extractSubtitleTracks(ctx context.Context, inputFileName string, selectedOriginalTracks []model.MKVTrack, outputConfig model.OutputConfig) ([]model.ExtractionJob, error) {
 	fmt.Println()
 	// Step 1: Create .mks file with only selected subtitle tracks
-	mksFileName, mksErr := mkv.CreateSubtitlesMKS(inputFileName, selection, util.MatchesTrackSelection, outputConfig)
+	mksFileName, mksTracks, mksErr := mkv.CreateSubtitlesMKS(ctx, inputFileName, selectedOriginalTracks, outputConfig)
 	if mksErr != nil {
-		return mksErr
+		return nil, mksErr
 	}
 	// Ensure cleanup of temporary .mks file
 	defer mkv.CleanupTempFile(mksFileName)
 
-	// Step 2: Get track information from the temporary .mks file
-	mkvInfo, err := mkv.GetTrackInfo(mksFileName)
-	if err != nil {
-		format.PrintError(fmt.Sprintf("Error analyzing subtitle tracks: %v", err))
-		return err
-	}
-
 	fmt.Println()
 	// Step 2: Extract subtitles
 	format.PrintStep(2, "Extracting subtitle tracks...")
 
 	var jobs []model.ExtractionJob
-	mksTrackIndex := 0
-
-	for _, track := range mkvInfo.Tracks {
-		if track.Type == "subtitles" {
-			// Use the corresponding original track based on order
-			// The .mks file should contain tracks in the same order as they were selected
-			var originalTrack model.MKVTrack
-			if mksTrackIndex < len(selectedOriginalTracks) {
-				originalTrack = selectedOriginalTracks[mksTrackIndex]
-			} else {
-				format.PrintWarning(fmt.Sprintf("Track index mismatch, using renumbered track info for track %d", track.Id))
-				originalTrack = track
-			}
-			mksTrackIndex++
-
-			outFileName := util.BuildSubtitlesFileNameWithConfig(inputFileName, originalTrack, outputConfig)
-
-			jobs = append(jobs, model.ExtractionJob{
-				Track:         track,
-				OriginalTrack: originalTrack,
-				OutFileName:   outFileName,
-				MksFileName:   mksFileName,
-			})
-		}
+	for i, originalTrack := range selectedOriginalTracks {
+		jobs = append(jobs, model.ExtractionJob{
+			Track:         mksTracks[i],
+			OriginalTrack: originalTrack,
+			OutFileName:   util.BuildSubtitlesFileNameWithConfig(inputFileName, originalTrack, outputConfig),
+			MksFileName:   mksFileName,
+		})
 	}
 
 	// Execute optimized extraction using single mkvextract call per input file
-	extractErr := mkv.ProcessTracks(jobs)
-	if extractErr != nil {
-		return extractErr
+	if extractErr := mkv.ProcessTracks(ctx, jobs); extractErr != nil {
+		return nil, extractErr
+	}
+	return jobs, nil
+}
+
+// writeToStdout copies the subtitle extracted for --stdout to out and removes the temporary directory
+// it was extracted to
+func writeToStdout(out *os.File, result model.FileResult, tempDir string) error {
+	defer os.RemoveAll(tempDir)
+	if len(result.Outputs) == 0 {
+		format.PrintError("No subtitle was extracted")
+		return errors.New("no subtitle was extracted")
 	}
 
+	file, err := os.Open(result.Outputs[0].Path)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Could not read the extracted subtitle: %v", err))
+		return err
+	}
+	defer file.Close()
+	if _, err := io.Copy(out, file); err != nil {
+		format.PrintError(fmt.Sprintf("Could not write to stdout: %v", err))
+		return err
+	}
 	return nil
 }
 
+// outputFiles returns the extracted files with their sizes, including the .idx of VobSub tracks
+func outputFiles(jobs []model.ExtractionJob) []model.OutputFile {
+	var outputs []model.OutputFile
+	for _, job := range jobs {
+		files := []string{job.OutFileName}
+		if strings.EqualFold(filepath.Ext(job.OutFileName), ".sub") {
+			files = append(files, strings.TrimSuffix(job.OutFileName, filepath.Ext(job.OutFileName))+".idx")
+		}
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil {
+				outputs = append(outputs, model.OutputFile{Path: file, Bytes: info.Size(), Track: job.OriginalTrack})
+			}
+		}
+	}
+	return outputs
+}
+
 // processBatch handles batch processing of multiple MKV files
-func processBatch(pattern, languageFilter, exclusionFilter string, showFilterMessage bool, outputConfig model.OutputConfig, dryRun bool) error {
-	files, err := filepath.Glob(pattern)
-	if err != nil {
-		format.PrintError(fmt.Sprintf("Invalid glob pattern: %v", err))
-		return err
+func processBatch(ctx context.Context, pattern, languageFilter, exclusionFilter string, showFilterMessage bool, outputConfig model.OutputConfig, dryRun bool, processFunc batch.ProcessFileFunc) error {
+	var files []string
+	var err error
+	isFileList := util.IsFileList(pattern)
+	if isFileList {
+		// A list file hands over its paths as they are, one per line
+		if files, err = util.ReadFileList(pattern); err != nil {
+			format.PrintError(err.Error())
+			return err
+		}
+		for _, file := range files {
+			if _, statErr := os.Stat(file); statErr != nil {
+				format.PrintWarning(fmt.Sprintf("Listed file not found: %s", file))
+			}
+		}
+	} else {
+		expandedPatterns, expandErr := util.ExpandBraces(pattern)
+		if expandErr != nil {
+			format.PrintError(fmt.Sprintf("Invalid batch pattern: %v", expandErr))
+			return expandErr
+		}
+		for _, expandedPattern := range expandedPatterns {
+			matches, globErr := filepath.Glob(expandedPattern)
+			if globErr != nil {
+				format.PrintError(fmt.Sprintf("Invalid glob pattern: %v", globErr))
+				return globErr
+			}
+			files = append(files, matches...)
+		}
 	}
 
 	if len(files) == 0 {
@@ -188,6 +657,10 @@ func processBatch(pattern, languageFilter, exclusionFilter string, showFilterMes
 		return err
 	}
 
+	// A list file's order is kept unless --sort asks for another
+	if !isFileList || outputConfig.SortOrder != "" || outputConfig.SortReverse {
+		util.SortFiles(mkvFiles, outputConfig.SortOrder, outputConfig.SortReverse)
+	}
 	format.PrintInfo(fmt.Sprintf("Found %d MKV file(s) to process", len(mkvFiles)))
 
 	// Display unified filter message for batch mode
@@ -197,19 +670,28 @@ func processBatch(pattern, languageFilter, exclusionFilter string, showFilterMes
 			selection = cli.ParseTrackSelection(languageFilter)
 		}
 		if exclusionFilter != "" {
-			selection.Exclusions = cli.ParseTrackExclusion(exclusionFilter)
+			selection.Exclusions = model.MergeTrackExclusions(selection.Exclusions, cli.ParseTrackExclusion(exclusionFilter))
 		}
+		selection.Filter = outputConfig.TrackFilter
 		displayFilterMessage(selection, selection.Exclusions)
 	}
 
 	// Use the new batch processor
 	processor := batch.NewProcessor(mkvFiles, outputConfig, dryRun)
-	result, err := processor.Process(processFile, languageFilter, exclusionFilter)
+	start := time.Now()
+	result, err := processor.Process(ctx, processFunc, languageFilter, exclusionFilter)
 	if err != nil {
 		return err
 	}
 
 	processor.PrintSummary(result)
+	if !dryRun {
+		summary := notify.Summary{Mode: "batch", Duration: time.Since(start)}
+		for _, file := range result.Files {
+			summary.AddFile(file.FileName, file.Result, file.Err)
+		}
+		sendNotifications(ctx, outputConfig.Notifications, summary)
+	}
 
 	if result.ErrorCount > 0 {
 		return fmt.Errorf("batch processing completed with %d errors", result.ErrorCount)
@@ -218,12 +700,31 @@ func processBatch(pattern, languageFilter, exclusionFilter string, showFilterMes
 	return nil
 }
 
+// porcelainProcessFunc wraps a batch's processing function to write each file's porcelain records
+func porcelainProcessFunc(w io.Writer, processFunc batch.ProcessFileFunc) batch.ProcessFileFunc {
+	return func(ctx context.Context, inputFileName, languageFilter, exclusionFilter string, showFilterMessage bool, outputConfig model.OutputConfig, dryRun bool) (model.FileResult, error) {
+		result, err := processFunc(ctx, inputFileName, languageFilter, exclusionFilter, showFilterMessage, outputConfig, dryRun)
+		cli.PrintPorcelainResult(w, inputFileName, result, err)
+		return result, err
+	}
+}
+
+// sendNotifications posts a run's summary to the configured sinks, warning about sinks that failed
+func sendNotifications(ctx context.Context, sinks []model.NotificationConfig, summary notify.Summary) {
+	if len(sinks) == 0 {
+		return
+	}
+	for _, err := range notify.Send(ctx, sinks, summary) {
+		format.PrintWarning(err.Error())
+	}
+}
+
 // handleBatchDragAndDrop handles drag-and-drop of multiple MKV files
-func handleBatchDragAndDrop(mkvFiles []string, outputConfig model.OutputConfig) error {
+func handleBatchDragAndDrop(ctx context.Context, mkvFiles []string, outputConfig model.OutputConfig) error {
 	format.PrintInfo(fmt.Sprintf("Batch drag-and-drop detected: %d MKV files", len(mkvFiles)))
 
 	// Analyze each file to gather subtitle information
-	batchFileInfos := batch.AnalyzeFiles(mkvFiles)
+	batchFileInfos := batch.AnalyzeFiles(ctx, mkvFiles)
 
 	// Display all files using the same visual style as subtitle tracks
 	cli.DisplayBatchFiles(batchFileInfos)
@@ -234,11 +735,13 @@ func handleBatchDragAndDrop(mkvFiles []string, outputConfig model.OutputConfig)
 	// Collect all available track numbers from all files for validation
 	var allAvailableTracks []int
 	trackSet := make(map[int]bool)
+	mkvInfos := make(map[string]*model.MKVInfo)
 	for _, fileInfo := range batchFileInfos {
 		if !fileInfo.HasError {
 			// Get track info for this file
-			mkvInfo, err := mkv.GetTrackInfo(fileInfo.FilePath)
+			mkvInfo, err := mkv.GetTrackInfo(ctx, fileInfo.FilePath)
 			if err == nil {
+				mkvInfos[fileInfo.FilePath] = mkvInfo
 				for _, track := range mkvInfo.Tracks {
 					if track.Type == "subtitles" {
 						if !trackSet[track.Properties.Number] {
@@ -252,9 +755,9 @@ func handleBatchDragAndDrop(mkvFiles []string, outputConfig model.OutputConfig)
 	}
 
 	// Process selection and exclusion using the shared function
-	selectionResult, err := cli.ProcessSelectionAndExclusion(extractAll, allAvailableTracks)
+	selectionResult, err := cli.ProcessSelectionAndExclusion(extractAll, allAvailableTracks, nil)
 	if err != nil {
-		fmt.Println("Press enter to exit...")
+		fmt.Println(i18n.T("Press enter to exit..."))
 		fmt.Scanln()
 		return nil
 	}
@@ -269,17 +772,35 @@ func handleBatchDragAndDrop(mkvFiles []string, outputConfig model.OutputConfig)
 
 	if len(validFiles) == 0 {
 		format.PrintError("No valid MKV files to process")
-		fmt.Println("Press enter to exit...")
+		fmt.Println(i18n.T("Press enter to exit..."))
 		fmt.Scanln()
 		return fmt.Errorf("no valid files to process")
 	}
 
+	// Files the selection matches differently from the rest can get their own selection or be left out
+	refinements := cli.RefineBatchSelection(validFiles, mkvInfos, selectionResult.Selection)
+	validFiles = slices.DeleteFunc(validFiles, func(file string) bool {
+		return refinements[file].Skip
+	})
+	if len(validFiles) == 0 {
+		format.PrintWarning("All files were skipped")
+		fmt.Println(i18n.T("Press enter to exit..."))
+		fmt.Scanln()
+		return nil
+	}
+	processFunc := func(ctx context.Context, inputFileName, languageFilter, exclusionFilter string, showFilterMessage bool, outputConfig model.OutputConfig, dryRun bool) (model.FileResult, error) {
+		if refinement, found := refinements[inputFileName]; found {
+			languageFilter, exclusionFilter = refinement.LanguageFilter, refinement.ExclusionFilter
+		}
+		return processFile(ctx, inputFileName, languageFilter, exclusionFilter, showFilterMessage, outputConfig, dryRun)
+	}
+
 	// Use the batch processor for consistent handling
 	processor := batch.NewProcessor(validFiles, outputConfig, false)
-	result, _ := processor.Process(processFile, selectionResult.LanguageFilter, selectionResult.ExclusionFilter)
+	result, _ := processor.Process(ctx, processFunc, selectionResult.LanguageFilter, selectionResult.ExclusionFilter)
 	processor.PrintSummary(result)
 
-	fmt.Println("Press enter to exit...")
+	fmt.Println(i18n.T("Press enter to exit..."))
 	fmt.Scanln()
 
 	if result.ErrorCount > 0 {
@@ -292,8 +813,8 @@ func handleBatchDragAndDrop(mkvFiles []string, outputConfig model.OutputConfig)
 // displayFilterMessage shows a unified filter message for selections and exclusions
 func displayFilterMessage(selection model.TrackSelection, exclusion model.TrackExclusion) {
 	// Check if we have any filters at all
-	hasSelectionFilters := len(selection.LanguageCodes) > 0 || len(selection.TrackNumbers) > 0 || len(selection.FormatFilters) > 0
-	hasExclusionFilters := len(exclusion.LanguageCodes) > 0 || len(exclusion.TrackNumbers) > 0 || len(exclusion.FormatFilters) > 0
+	hasSelectionFilters := len(selection.LanguageCodes) > 0 || len(selection.TrackNumbers) > 0 || len(selection.FormatFilters) > 0 || len(selection.StatusFilters) > 0 || selection.Filter != nil
+	hasExclusionFilters := len(exclusion.LanguageCodes) > 0 || len(exclusion.TrackNumbers) > 0 || len(exclusion.FormatFilters) > 0 || len(exclusion.StatusFilters) > 0
 
 	if !hasSelectionFilters && !hasExclusionFilters {
 		format.PrintInfo("No filter - extracting all subtitle tracks")
@@ -319,6 +840,12 @@ func displayFilterMessage(selection model.TrackSelection, exclusion model.TrackE
 		if len(selection.FormatFilters) > 0 {
 			selectionParts = append(selectionParts, fmt.Sprintf("formats: %s", strings.Join(selection.FormatFilters, ", ")))
 		}
+		if len(selection.StatusFilters) > 0 {
+			selectionParts = append(selectionParts, fmt.Sprintf("status: %s", strings.Join(selection.StatusFilters, ", ")))
+		}
+		if selection.Filter != nil {
+			selectionParts = append(selectionParts, fmt.Sprintf("filter: %s", selection.Filter.Expression))
+		}
 
 		if len(selectionParts) > 0 {
 			messageParts = append(messageParts, fmt.Sprintf("Selecting tracks matching %s", strings.Join(selectionParts, "; ")))
@@ -341,6 +868,9 @@ func displayFilterMessage(selection model.TrackSelection, exclusion model.TrackE
 		if len(exclusion.FormatFilters) > 0 {
 			exclusionParts = append(exclusionParts, fmt.Sprintf("formats: %s", strings.Join(exclusion.FormatFilters, ", ")))
 		}
+		if len(exclusion.StatusFilters) > 0 {
+			exclusionParts = append(exclusionParts, fmt.Sprintf("status: %s", strings.Join(exclusion.StatusFilters, ", ")))
+		}
 
 		if len(exclusionParts) > 0 {
 			if hasSelectionFilters {
@@ -357,22 +887,502 @@ func displayFilterMessage(selection model.TrackSelection, exclusion model.TrackE
 	}
 }
 
+// commandFlags declares the command line options for gocmd. --help and the docs command render
+// the same tags, so option descriptions live only here.
+type commandFlags struct {
+	Extract         string `short:"x" long:"extract" value:"<file>" group:"selection" description:"Extract subtitles from an MKV file (local path or http(s) URL)"`
+	Batch           string `short:"b" long:"batch" value:"<pattern>" group:"selection" description:"Extract subtitles from multiple MKV files using a glob pattern (e.g., '*.mkv', 'Season 1/*.mkv', '/path/to/*.mkv', 'Season {1..3}/*.{mkv,mks}') or a .txt/.m3u file listing one path per line"`
+	Info            string `short:"i" long:"info" value:"<file>" group:"selection" description:"Display subtitle track information (local path or http(s) URL)"`
+	Select          string `short:"s" long:"select" value:"<selection>" group:"selection" description:"Select subtitle tracks by a comma-separated mix of language codes, track IDs, subtitle formats and track status (e.g., 'eng,14,srt,sup'). If not specified, all subtitle tracks are extracted"`
+	Exclude         string `short:"e" long:"exclude" value:"<exclusion>" group:"selection" description:"Exclude subtitle tracks using the same values as --select. Exclusions are applied after selections (e.g., 'chi,15,sup,disabled')"`
+	NameContains    string `long:"name-contains" value:"<text>" group:"selection" description:"Only extract tracks whose name contains the text, ignoring case (e.g., 'signs')"`
+	ExcludeName     string `long:"exclude-name-contains" value:"<text>" group:"selection" description:"Skip tracks whose name contains the text, ignoring case (e.g., 'commentary')"`
+	MinEntries      int    `long:"min-entries" value:"<n>" group:"selection" description:"Skip tracks with fewer than n index entries (roughly cues), such as forced tracks when full dialogue is wanted. Tracks without a reported count are kept"`
+	MinCoverage     int    `long:"min-coverage" value:"<percent>" group:"selection" description:"Skip tracks whose duration covers less than this percentage of the file, such as partial or credits-only tracks. Needs the track statistics tags most muxers write"`
+	PerLanguage     string `long:"per-language" value:"<first|last|n>" group:"selection" description:"Keep one selected track per language: the first, the last or the nth in file order (e.g., 2). Languages with fewer tracks than n are skipped"`
+	UndPolicy       string `long:"und-policy" value:"<policy>" group:"selection" description:"How tracks without a language tag meet language selections: exclude (default), include, prompt or treat-as:<lang> (e.g., treat-as:eng)"`
+	Recipe          string `long:"recipe" value:"<file>" group:"selection" description:"Select the tracks of a recipe saved with --save-recipe in place of --select, matched by track UID or by language, format, name and role, so remuxed and re-released files yield the same tracks"`
+	SaveRecipe      string `long:"save-recipe" value:"<file>" group:"selection" description:"Save the tracks selected from the file (-x) as a recipe for --recipe"`
+	MaxPerLanguage  int    `long:"max-per-language" value:"<n>" group:"selection" description:"Extract at most n tracks per language, the first ones in file order, after all other filters"`
+	Filter          string `long:"filter" value:"<expression>" group:"selection" description:"Only extract tracks matching an expression over lang, format, name, codec, track, entries and the flags forced, default, enabled, text and image (e.g., 'lang==eng && !forced && format in (srt,ass)')"`
+	OutputDir       string `short:"o" long:"output-dir" value:"[dir]" description:"Output directory for extracted subtitle files (default: same directory as the input file). Without a directory, -o creates {basename}-subtitles. The directory is created if it doesn't exist"`
+	OutputLayout    string `long:"output-layout" value:"<flat|per-file>" description:"With -o <dir>, 'per-file' puts each source's subtitles in <dir>/<basename>/ instead of directly in <dir> (default: flat)"`
+	OutputTemplate  string `short:"f" long:"format" value:"<template>" description:"Custom filename template with placeholders: {basename}, {language}, {languagename}, {trackno}, {trackname}, {forced}, {default}, {extension}, {date}, {time}, {timestamp}, {hash}, {chapter}; append :lower, :upper or :slug to transform a value"`
+	Naming          string `long:"naming" value:"<preset>" description:"Name the files the way a media center expects instead of with a template: kodi (movie.eng.srt, movie.eng.forced.srt)"`
+	FieldSeparator  string `long:"field-separator" value:"<sep>" description:"Separator between the fields of the default filename template: dot (default), underscore, dash or space. Separators left by empty fields are removed"`
+	LineEndings     string `long:"line-endings" value:"<lf|crlf>" description:"Line endings of the extracted text subtitles. Some TVs and older players need crlf"`
+	BOM             bool   `long:"bom" description:"Start the extracted text subtitles with a UTF-8 byte order mark, which some TVs and older players need to detect UTF-8"`
+	SafeNames       string `long:"safe-names" value:"<fs>" description:"Make output names valid on another filesystem: windows (NTFS, SMB shares; also renames reserved names such as CON) or exfat (USB drives)"`
+	Lang            string `long:"lang" value:"<language>" description:"Interface language: en, es or de (default: from LC_ALL, LC_MESSAGES or LANG)"`
+	LangStyle       string `long:"lang-style" value:"<style>" description:"Code style of {language}: iso639-1 (en, de), iso639-2b (eng, ger), iso639-2t (eng, deu) or name (English, German). Default: the code as tagged in the file"`
+	LangNameLocale  string `long:"lang-name-locale" value:"<locale>" description:"Language of {languagename}: 'native' (Español, Français) or a locale such as 'fr' or 'de' (default: English)"`
+	PreHook         string `long:"pre-hook" value:"<command>" description:"Command run before each file with the planned tracks as JSON on stdin. A non-zero exit skips the file"`
+	PostProcessors  string `long:"post-processors" value:"<list>" description:"Order of the post-processing steps, e.g. cleanup,strip-hi,convert. Unlisted steps follow in their default order"`
+	NameCommand     string `long:"name-command" value:"<command>" description:"Command naming each output file: it receives the track as JSON on stdin and prints the file name"`
+	PostHook        string `long:"post-hook" value:"<command>" description:"Command run once per extracted file. Placeholders: {output}, {language}, {format}, {source}"`
+	Translate       string `long:"translate" value:"<lang>" description:"Also write a machine-translated copy of each extracted SRT track in the given language (backend configured under 'translation:')"`
+	Cleanup         bool   `long:"cleanup" description:"Remove advertising and credit cues (\"Downloaded from...\", URLs, fansub credits) from extracted text subtitles, using the cleanup_rules of the configuration file or built-in rules"`
+	NoFonts         bool   `long:"no-fonts" description:"Do not extract font attachments into a fonts/ directory when ASS/SSA tracks are extracted"`
+	StripASS        string `long:"strip-ass" value:"<ass|srt>" description:"Reduce extracted ASS tracks to dialogue only (drops signs, karaoke, positioning). 'srt' also converts them to SRT"`
+	StripHI         string `long:"strip-hi" value:"<copy|replace>" description:"Remove [sound descriptions], (annotations), SPEAKER: labels and ♪ music lines from extracted text subtitles. 'copy' writes a .nonsdh variant next to the original, 'replace' rewrites the original"`
+	VerifyLanguage  bool   `long:"verify-language" description:"Detect the language of extracted text subtitles and warn when it disagrees with the track's tag, marking the file name with .mismatch-<language>. Untagged (und) tracks are named after the detected language"`
+	FixLanguageTags bool   `long:"fix-language-tags" description:"With language detection as in --verify-language, write the detected language of und tracks into the source file with mkvpropedit. Mismatched tags are only reported"`
+	SyncToAudio     bool   `long:"sync-to-audio" description:"Shift and stretch extracted text subtitles to match the speech of the default audio track (up to 60 seconds and common frame-rate mismatches). Requires ffmpeg"`
+	Convert         string `long:"convert" value:"<ass>" description:"Convert extracted SRT tracks to ASS, turning <i>, <b>, <u> and font colors into ASS tags"`
+	Style           string `long:"style" value:"<file>" description:"ASS script whose [Script Info] and styles the --convert ass output uses (its Default style, or else its first style)"`
+	MergeLanguages  string `long:"merge-languages" value:"<a+b>" description:"Merge two extracted text tracks into one bilingual subtitle, secondary language below the primary (e.g., 'eng+jpn')"`
+	MergeFormat     string `long:"merge-format" value:"<fmt>" description:"Bilingual output format: srt (default) or ass, where the secondary language is shown at the top of the screen"`
+	SplitByChapters bool   `long:"split-by-chapters" description:"Split extracted text subtitles into one file per chapter (e.g. per episode), with times relative to the chapter start. Use {chapter} in the template to place the chapter number"`
+	Stdout          bool   `long:"stdout" description:"Write the extracted subtitle to stdout instead of a file, for piping into other tools. The selection must match exactly one text or PGS track; messages go to stderr"`
+	AllTracks       bool   `long:"all-tracks" description:"Demux every track (video, audio and subtitles), all attachments and the chapters. --select and --exclude still narrow the subtitle tracks"`
+	Force           bool   `long:"force" description:"Overwrite existing files at the output paths. Without it, tracks whose output file already exists are skipped"`
+	Backup          bool   `long:"backup" description:"With --force, move each file about to be overwritten to <name>.bak first, replacing an older backup"`
+	BackupDir       string `long:"backup-dir" value:"<dir>" description:"With --force, move files about to be overwritten into this directory instead of to <name>.bak (implies --backup)"`
+	RespectExisting bool   `long:"respect-existing" description:"Skip tracks that already have a matching external subtitle next to the MKV (e.g. movie.en.srt)"`
+	SkipProcessed   bool   `long:"skip-processed" description:"Skip files whose selected tracks were all extracted by an earlier run and that have not been modified since"`
+	StableFor       string `long:"stable-for" value:"<duration>" description:"Wait until each input file has gone unmodified for this long (e.g. 30s, 2m) before analyzing it, so files still being copied or downloaded are not processed half-written"`
+	Sort            string `long:"sort" value:"<name|size|mtime>" description:"Order in which batch files are processed: by name (default), size or modification time, smallest and oldest first"`
+	Reverse         bool   `long:"reverse" description:"Process batch files in reverse --sort order, e.g. newest first with --sort mtime"`
+	Notify          bool   `long:"notify" description:"Post a summary to the Discord, Slack or webhook sinks under 'notifications' in the configuration file when the run finishes"`
+	Porcelain       bool   `long:"porcelain" description:"Write the results to stdout as stable tab-separated records for scripts (track, output, planned and file records; see the README); messages go to stderr"`
+	ProgressJSON    bool   `long:"progress-json" description:"Write progress events to stdout as JSON lines (stage, file, percent, track) for GUI wrappers and scripts; messages go to stderr"`
+	ProgressFile    string `long:"progress-json-file" value:"<path>" description:"Write the JSON progress events to this file or FIFO instead of stdout"`
+	LowPriority     bool   `long:"low-priority" description:"Run mkvmerge and mkvextract with reduced CPU and I/O priority (nice and ionice, or the below-normal priority class on Windows), so long runs don't slow down playback on the same machine"`
+	DryRun          bool   `short:"d" long:"dry-run" description:"Show what would be extracted without performing extraction"`
+	UseConfig       bool   `short:"c" long:"config" description:"Use the configuration file. Profiles with match patterns are applied automatically to files whose path they fit"`
+	Profile         string `short:"p" long:"profile" value:"<name>" description:"Use named configuration profile"`
+	Version         bool   `short:"v" long:"version" description:"Show version information, MKVToolNix versions and the config file in use (include this in bug reports)"`
+}
+
+// commandOptions returns the documented options: the flags plus --help, which is handled before gocmd
+func commandOptions() []docs.Option {
+	return append(docs.OptionsFromFlags(commandFlags{}), docs.HelpOption)
+}
+
+// uiLanguageFromArgs returns the value of --lang, which is read before flag parsing so the title and
+// interactive prompts are already localized. An empty result selects the language from the environment.
+func uiLanguageFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--lang" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(arg, "--lang=") {
+			return strings.TrimPrefix(arg, "--lang=")
+		}
+	}
+	return ""
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// buildOutputConfig collects the output settings from the flags
+func buildOutputConfig(flags commandFlags, hasOutputFlagWithoutValue, isBatchMode bool, translationConfig model.TranslationConfig) model.OutputConfig {
+	template := flags.OutputTemplate
+	if flags.Naming != "" {
+		template = model.NamingTemplates[flags.Naming]
+	}
+	outputConfig := util.BuildOutputConfig(flags.OutputDir, template, hasOutputFlagWithoutValue, isBatchMode)
+	outputConfig.OutputLayout = flags.OutputLayout
+	outputConfig.FieldSeparator = model.FieldSeparators[flags.FieldSeparator]
+	outputConfig.SafeNames = flags.SafeNames
+	outputConfig.LineEndings = flags.LineEndings
+	outputConfig.BOM = flags.BOM
+	outputConfig.PreHook = flags.PreHook
+	outputConfig.PostHook = flags.PostHook
+	outputConfig.PostProcessors = splitList(flags.PostProcessors)
+	if flags.NameCommand != "" {
+		outputConfig.Namer = hook.NewCommandNamer(flags.NameCommand)
+	}
+	outputConfig.TranslateTo = flags.Translate
+	outputConfig.Force = flags.Force
+	outputConfig.Backup = flags.Backup || flags.BackupDir != ""
+	outputConfig.BackupDir = flags.BackupDir
+	outputConfig.RespectExisting = flags.RespectExisting
+	outputConfig.SkipProcessed = flags.SkipProcessed
+	// Validated in main before the output configuration is built
+	outputConfig.StableFor, _ = time.ParseDuration(flags.StableFor)
+	var filters []*model.TrackFilter
+	if flags.Filter != "" {
+		// Validated in main before the output configuration is built
+		filter, _ := model.ParseTrackFilter(flags.Filter)
+		filters = append(filters, filter)
+	}
+	if flags.NameContains != "" {
+		filters = append(filters, model.NameContainsFilter(flags.NameContains, false))
+	}
+	if flags.ExcludeName != "" {
+		filters = append(filters, model.NameContainsFilter(flags.ExcludeName, true))
+	}
+	outputConfig.MinCoverage = flags.MinCoverage
+	outputConfig.PerLanguage = flags.PerLanguage
+	outputConfig.MaxPerLanguage = flags.MaxPerLanguage
+	outputConfig.UndPolicy = flags.UndPolicy
+	outputConfig.SaveRecipe = flags.SaveRecipe
+	outputConfig.SortOrder = flags.Sort
+	outputConfig.SortReverse = flags.Reverse
+	if flags.MinEntries > 0 {
+		filters = append(filters, model.MinEntriesFilter(flags.MinEntries))
+	}
+	outputConfig.TrackFilter = model.CombineTrackFilters(filters...)
+	outputConfig.LanguageNameLocale = flags.LangNameLocale
+	outputConfig.LanguageStyle = flags.LangStyle
+	outputConfig.NoFonts = flags.NoFonts
+	outputConfig.AllTracks = flags.AllTracks
+	outputConfig.Stdout = flags.Stdout
+	outputConfig.SplitByChapters = flags.SplitByChapters
+	outputConfig.StripASS = flags.StripASS
+	outputConfig.StripHI = flags.StripHI
+	outputConfig.VerifyLanguage = flags.VerifyLanguage || flags.FixLanguageTags
+	outputConfig.FixLanguages = flags.FixLanguageTags
+	outputConfig.SyncToAudio = flags.SyncToAudio
+	outputConfig.ConvertTo = flags.Convert
+	outputConfig.StyleTemplate = flags.Style
+	outputConfig.MergeLanguages = flags.MergeLanguages
+	outputConfig.MergeFormat = flags.MergeFormat
+	outputConfig.Translation = translationConfig
+	return outputConfig
+}
+
+// profileProcessFunc wraps processFile so that each batch file whose path fits a profile's match
+// patterns is processed with that profile; other files keep the batch-wide settings
+func profileProcessFunc(cfg *config.Config, cliFlags commandFlags, hasOutputFlagWithoutValue bool, translationConfig model.TranslationConfig) batch.ProcessFileFunc {
+	return func(ctx context.Context, inputFileName, languageFilter, exclusionFilter string, showFilterMessage bool, outputConfig model.OutputConfig, dryRun bool) (model.FileResult, error) {
+		profileName := matchProfile(cfg, inputFileName)
+		if profileName == "" {
+			return processFile(ctx, inputFileName, languageFilter, exclusionFilter, showFilterMessage, outputConfig, dryRun)
+		}
+
+		fileFlags := cliFlags
+		if err := applyConfiguration(&fileFlags, cfg, profileName); err != nil {
+			return model.FileResult{}, err
+		}
+		fileOutputConfig := applyProfileOutput(outputConfig, buildOutputConfig(fileFlags, hasOutputFlagWithoutValue, true, translationConfig))
+		return processFile(ctx, inputFileName, cli.BuildSelectionFilter(fileFlags.Select), fileFlags.Exclude, true, fileOutputConfig, dryRun)
+	}
+}
+
+// validateOutputFlags checks the naming and output settings, which a profile matched by path can
+// change for single batch files
+func validateOutputFlags(flags commandFlags) error {
+	if err := model.ValidateLanguageNameLocale(flags.LangNameLocale); err != nil {
+		return fmt.Errorf("invalid --lang-name-locale value '%s': use 'native' or a locale such as 'fr' or 'pt-BR'", flags.LangNameLocale)
+	}
+	if err := model.ValidateOutputTemplate(flags.OutputTemplate); err != nil {
+		return fmt.Errorf("invalid --format value '%s': %v", flags.OutputTemplate, err)
+	}
+	if err := model.ValidateNaming(flags.Naming); err != nil {
+		return fmt.Errorf("invalid --naming value '%s': must be %s", flags.Naming, model.NamingKodi)
+	}
+	if flags.Naming != "" && flags.OutputTemplate != "" {
+		return fmt.Errorf("--naming cannot be combined with --format")
+	}
+	if err := model.ValidateOutputLayout(flags.OutputLayout); err != nil {
+		return fmt.Errorf("invalid --output-layout value '%s': must be %s or %s", flags.OutputLayout, model.OutputLayoutFlat, model.OutputLayoutPerFile)
+	}
+	if flags.OutputLayout == model.OutputLayoutPerFile && flags.OutputDir == "" {
+		return fmt.Errorf("--output-layout per-file requires an output directory (-o <dir>)")
+	}
+	if err := model.ValidateFieldSeparator(flags.FieldSeparator); err != nil {
+		return fmt.Errorf("invalid --field-separator value '%s': must be dot, underscore, dash or space", flags.FieldSeparator)
+	}
+	if err := model.ValidateSafeNames(flags.SafeNames); err != nil {
+		return fmt.Errorf("invalid --safe-names value '%s': must be %s or %s", flags.SafeNames, model.SafeNamesWindows, model.SafeNamesExFAT)
+	}
+	if err := model.ValidateLineEndings(flags.LineEndings); err != nil {
+		return fmt.Errorf("invalid --line-endings value '%s': must be %s or %s", flags.LineEndings, model.LineEndingsLF, model.LineEndingsCRLF)
+	}
+	for _, name := range splitList(flags.PostProcessors) {
+		if err := postprocess.ValidateProcessorName(name); err != nil {
+			return fmt.Errorf("invalid --post-processors value: %v", err)
+		}
+	}
+	return nil
+}
+
+// validateMatchedProfiles applies each profile that batch files can pick by path to the flags
+// given on the command line and checks the result, so a bad profile fails before any file is processed
+func validateMatchedProfiles(cfg *config.Config, cliFlags commandFlags) error {
+	profileNames := make([]string, 0, len(cfg.Profiles))
+	for profileName, profile := range cfg.Profiles {
+		if len(profile.Match) > 0 {
+			profileNames = append(profileNames, profileName)
+		}
+	}
+	slices.Sort(profileNames)
+
+	for _, profileName := range profileNames {
+		profileFlags := cliFlags
+		if err := applyConfiguration(&profileFlags, cfg, profileName); err != nil {
+			return fmt.Errorf("error applying profile '%s': %v", profileName, err)
+		}
+		if err := validateOutputFlags(profileFlags); err != nil {
+			return fmt.Errorf("profile '%s': %v", profileName, err)
+		}
+	}
+	return nil
+}
+
+// applyProfileOutput returns the batch-wide output configuration with the settings a profile can
+// change taken from profileConfig, so run-wide settings such as notifications carry over
+func applyProfileOutput(base, profileConfig model.OutputConfig) model.OutputConfig {
+	outputConfig := base
+	outputConfig.OutputDir = profileConfig.OutputDir
+	outputConfig.Template = profileConfig.Template
+	outputConfig.CreateDir = profileConfig.CreateDir
+	outputConfig.OutputLayout = profileConfig.OutputLayout
+	outputConfig.FieldSeparator = profileConfig.FieldSeparator
+	outputConfig.SafeNames = profileConfig.SafeNames
+	outputConfig.LineEndings = profileConfig.LineEndings
+	outputConfig.BOM = profileConfig.BOM
+	outputConfig.PreHook = profileConfig.PreHook
+	outputConfig.PostHook = profileConfig.PostHook
+	outputConfig.PostProcessors = profileConfig.PostProcessors
+	outputConfig.Namer = profileConfig.Namer
+	outputConfig.LanguageNameLocale = profileConfig.LanguageNameLocale
+	return outputConfig
+}
+
+// applyConfiguration fills the flags not given on the command line from the configuration,
+// using the named profile or, when profileName is empty, the default settings
+func applyConfiguration(flags *commandFlags, cfg *config.Config, profileName string) error {
+	appliedConfig := cfg.ApplyDefaults()
+	if profileName != "" {
+		var err error
+		appliedConfig, err = cfg.ApplyProfile(profileName)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Merge configuration with CLI flags (CLI flags take precedence)
+	cliFlags := config.CLIFlags{
+		OutputTemplate:     flags.OutputTemplate,
+		Naming:             flags.Naming,
+		OutputDir:          flags.OutputDir,
+		OutputLayout:       flags.OutputLayout,
+		FieldSeparator:     flags.FieldSeparator,
+		SafeNames:          flags.SafeNames,
+		LineEndings:        flags.LineEndings,
+		BOM:                flags.BOM,
+		PreHook:            flags.PreHook,
+		NameCommand:        flags.NameCommand,
+		PostProcessors:     splitList(flags.PostProcessors),
+		PostHook:           flags.PostHook,
+		LanguageNameLocale: flags.LangNameLocale,
+	}
+
+	// Parse languages from Select flag if provided
+	if flags.Select != "" {
+		selection := cli.ParseTrackSelection(flags.Select)
+		cliFlags.Languages = selection.LanguageCodes
+	}
+
+	// Parse exclusions from Exclude flag if provided
+	if flags.Exclude != "" {
+		exclusion := cli.ParseTrackExclusion(flags.Exclude)
+		var exclusionParts []string
+		exclusionParts = append(exclusionParts, exclusion.LanguageCodes...)
+		for _, trackNum := range exclusion.TrackNumbers {
+			exclusionParts = append(exclusionParts, strconv.Itoa(trackNum))
+		}
+		exclusionParts = append(exclusionParts, exclusion.FormatFilters...)
+		exclusionParts = append(exclusionParts, exclusion.StatusFilters...)
+		cliFlags.Exclusions = exclusionParts
+	}
+
+	appliedConfig = appliedConfig.MergeWithCLI(cliFlags)
+
+	// Apply config values back to flags if they weren't set via CLI
+	if flags.OutputTemplate == "" && appliedConfig.OutputTemplate != "" {
+		flags.OutputTemplate = appliedConfig.OutputTemplate
+	}
+	if flags.Naming == "" && appliedConfig.Naming != "" {
+		flags.Naming = appliedConfig.Naming
+	}
+	if flags.OutputDir == "" && appliedConfig.OutputDir != "" {
+		flags.OutputDir = appliedConfig.OutputDir
+	}
+	if flags.OutputLayout == "" && appliedConfig.OutputLayout != "" {
+		flags.OutputLayout = appliedConfig.OutputLayout
+	}
+	if flags.FieldSeparator == "" && appliedConfig.FieldSeparator != "" {
+		flags.FieldSeparator = appliedConfig.FieldSeparator
+	}
+	if flags.SafeNames == "" && appliedConfig.SafeNames != "" {
+		flags.SafeNames = appliedConfig.SafeNames
+	}
+	if flags.LineEndings == "" && appliedConfig.LineEndings != "" {
+		flags.LineEndings = appliedConfig.LineEndings
+	}
+	if !flags.BOM && appliedConfig.BOM {
+		flags.BOM = true
+	}
+	if flags.PreHook == "" && appliedConfig.PreHook != "" {
+		flags.PreHook = appliedConfig.PreHook
+	}
+	if flags.PostProcessors == "" && len(appliedConfig.PostProcessors) > 0 {
+		flags.PostProcessors = strings.Join(appliedConfig.PostProcessors, ",")
+	}
+	if flags.NameCommand == "" && appliedConfig.NameCommand != "" {
+		flags.NameCommand = appliedConfig.NameCommand
+	}
+	if flags.PostHook == "" && appliedConfig.PostHook != "" {
+		flags.PostHook = appliedConfig.PostHook
+	}
+	if flags.LangNameLocale == "" && appliedConfig.LanguageNameLocale != "" {
+		flags.LangNameLocale = appliedConfig.LanguageNameLocale
+	}
+	if flags.Select == "" && len(appliedConfig.Languages) > 0 {
+		flags.Select = strings.Join(appliedConfig.Languages, ",")
+	}
+	if flags.Exclude == "" && len(appliedConfig.Exclusions) > 0 {
+		flags.Exclude = strings.Join(appliedConfig.Exclusions, ",")
+	}
+
+	return nil
+}
+
+// matchProfile returns the profile selected by the match patterns for a file, announcing the choice
+func matchProfile(cfg *config.Config, inputFileName string) string {
+	profileName, pattern := cfg.MatchProfile(inputFileName)
+	if profileName != "" {
+		format.PrintInfo(fmt.Sprintf("Using profile '%s' (matches %s)", profileName, pattern))
+	}
+	return profileName
+}
+
+// loadConfiguration loads the config file in use, warning once when it has problems that would
+// otherwise be ignored silently, such as misspelled keys
+func loadConfiguration() (*config.Config, error) {
+	configPath := config.FindConfigFile()
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil || configPath == "" || configWarningShown {
+		return cfg, err
+	}
+
+	configWarningShown = true
+	if problems, err := config.ValidateFile(configPath); err == nil && len(problems) > 0 {
+		format.PrintWarning(fmt.Sprintf("%s has %d problem(s); run 'subscalpelmkv config validate' for details", configPath, len(problems)))
+	}
+	return cfg, nil
+}
+
+// configWarningShown keeps loadConfiguration from repeating its warning
+var configWarningShown bool
+
 func main() {
+	args := os.Args[1:]
+
+	// The UI language must be set before anything is printed
+	i18n.SetLanguage(uiLanguageFromArgs(args))
+
+	// Generated documentation goes to stdout without the title box
+	if len(args) > 0 && args[0] == "docs" {
+		if err := cli.HandleDocsCommand(args[1:], commandOptions(), Version); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(ErrCodeFailure)
+		}
+		os.Exit(ErrCodeSuccess)
+	}
+
+	// The audit command prints the title box itself, except for CSV and JSON reports
+	if len(args) > 0 && args[0] == "audit" {
+		if err := cli.HandleAuditCommand(args[1:], Version); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(ErrCodeFailure)
+		}
+		os.Exit(ErrCodeSuccess)
+	}
+
+	// The stats command likewise prints the title box itself, except for JSON reports
+	if len(args) > 0 && args[0] == "stats" {
+		if err := cli.HandleStatsCommand(args[1:], Version); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(ErrCodeFailure)
+		}
+		os.Exit(ErrCodeSuccess)
+	}
+
+	// With --stdout the extracted subtitle, with --progress-json the progress events and with
+	// --porcelain the result records are the only things written to stdout
+	var subtitleOut, progressOut, porcelainOut *os.File
+	if slices.Contains(args, "--stdout") || slices.Contains(args, "--progress-json") || slices.Contains(args, "--porcelain") {
+		stdout := format.RedirectToStderr()
+		if slices.Contains(args, "--stdout") {
+			subtitleOut = stdout
+		}
+		if slices.Contains(args, "--progress-json") {
+			progressOut = stdout
+		}
+		if slices.Contains(args, "--porcelain") {
+			porcelainOut = stdout
+		}
+	}
+
 	format.PrintTitleWithVersion(Version)
+	mkv.SetProgressFunc(cli.PrintMKVEvent)
 
-	args := os.Args[1:]
+	// Long-running operations take a context so embedding code can cancel them; the CLI runs them to completion
+	ctx := context.Background()
 
 	// Check for help and version flags first
 	for _, arg := range args {
 		if arg == "-h" || arg == "--help" {
-			cli.ShowHelp()
+			cli.ShowHelp(commandOptions())
 			os.Exit(ErrCodeSuccess)
 		}
 		if arg == "-v" || arg == "--version" {
+			cli.ShowVersion(Version)
 			os.Exit(ErrCodeSuccess)
 		}
 	}
 
+	// Subcommands are dispatched before flag parsing and drag-and-drop detection
+	if len(args) > 0 && args[0] == "history" {
+		if err := cli.HandleHistoryCommand(args[1:]); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(ErrCodeFailure)
+		}
+		os.Exit(ErrCodeSuccess)
+	}
+	if len(args) > 0 && args[0] == "cleanup" {
+		if err := cli.HandleCleanupCommand(args[1:]); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(ErrCodeFailure)
+		}
+		os.Exit(ErrCodeSuccess)
+	}
+	if len(args) > 0 && args[0] == "config" {
+		if err := cli.HandleConfigCommand(args[1:]); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(ErrCodeFailure)
+		}
+		os.Exit(ErrCodeSuccess)
+	}
+	if len(args) > 0 && args[0] == "install-quick-action" {
+		if err := cli.HandleInstallQuickActionCommand(args[1:]); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(ErrCodeFailure)
+		}
+		os.Exit(ErrCodeSuccess)
+	}
+	if len(args) > 0 && args[0] == "install-desktop" {
+		if err := cli.HandleInstallDesktopCommand(args[1:]); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(ErrCodeFailure)
+		}
+		os.Exit(ErrCodeSuccess)
+	}
+
 	// Check if -o flag is used without arguments and handle it specially
 	hasOutputFlagWithoutValue := false
 	modifiedArgs := make([]string, len(args))
@@ -396,11 +1406,18 @@ func main() {
 
 	// Detect execution mode: drag-and-drop vs CLI
 	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		// The configuration file can set the default answers of the prompts
+		if cfg, err := loadConfiguration(); err != nil {
+			format.PrintWarning(fmt.Sprintf("Error loading configuration: %v", err))
+		} else {
+			cli.SetPromptDefaults(cfg.Prompts)
+		}
+
 		// Use the new discovery function
 		validMKVFiles, err := util.DiscoverMKVFiles(args)
 		if err != nil {
 			format.PrintError(fmt.Sprintf("Error discovering MKV files: %v", err))
-			fmt.Println("Press enter to exit...")
+			fmt.Println(i18n.T("Press enter to exit..."))
 			fmt.Scanln()
 			os.Exit(ErrCodeFailure)
 		}
@@ -408,7 +1425,7 @@ func main() {
 		// If we found multiple valid MKV files (from files or directories), handle as batch
 		if len(validMKVFiles) > 1 {
 			defaultOutputConfig := util.BuildOutputConfig("", "", false, false)
-			err = handleBatchDragAndDrop(validMKVFiles, defaultOutputConfig)
+			err = handleBatchDragAndDrop(ctx, validMKVFiles, defaultOutputConfig)
 			if err != nil {
 				os.Exit(ErrCodeFailure)
 			}
@@ -418,85 +1435,21 @@ func main() {
 		// If we found exactly one valid file, process it
 		if len(validMKVFiles) == 1 {
 			defaultOutputConfig := util.BuildOutputConfig("", "", false, false)
-			err = cli.HandleDragAndDropModeWithConfig(validMKVFiles[0], processFile, defaultOutputConfig)
+			err = cli.HandleDragAndDropModeWithConfig(ctx, validMKVFiles[0], processFile, defaultOutputConfig)
 			if err != nil {
 				os.Exit(ErrCodeFailure)
 			}
 			os.Exit(ErrCodeSuccess)
 		}
 
-		// If no valid files found, try the traditional approach (joining with spaces for filenames with spaces)
-		inputFileName := strings.Join(args, " ")
-
-		if _, statErr := os.Stat(inputFileName); os.IsNotExist(statErr) {
-			format.PrintError(fmt.Sprintf("File does not exist: %s", inputFileName))
-			fmt.Println("Press enter to exit...")
-			fmt.Scanln()
-			os.Exit(ErrCodeFailure)
-		}
-
-		// Check if it's a directory
-		if info, _ := os.Stat(inputFileName); info.IsDir() {
-			format.PrintInfo(fmt.Sprintf("Scanning directory: %s", inputFileName))
-			files, err := util.FindMKVFilesInDirectory(inputFileName)
-			if err != nil {
-				format.PrintError(fmt.Sprintf("Error scanning directory: %v", err))
-				fmt.Println("Press enter to exit...")
-				fmt.Scanln()
-				os.Exit(ErrCodeFailure)
-			}
-
-			if len(files) == 0 {
-				format.PrintError("No MKV files found in the directory")
-				fmt.Println("Press enter to exit...")
-				fmt.Scanln()
-				os.Exit(ErrCodeFailure)
-			}
-
-			defaultOutputConfig := util.BuildOutputConfig("", "", false, false)
-
-			if len(files) == 1 {
-				err = cli.HandleDragAndDropModeWithConfig(files[0], processFile, defaultOutputConfig)
-				if err != nil {
-					os.Exit(ErrCodeFailure)
-				}
-			} else {
-				err = handleBatchDragAndDrop(files, defaultOutputConfig)
-				if err != nil {
-					os.Exit(ErrCodeFailure)
-				}
-			}
-			os.Exit(ErrCodeSuccess)
-		}
-
-		if !util.IsMKVFile(inputFileName) {
-			format.PrintError(fmt.Sprintf("File is not an MKV file: %s", inputFileName))
-			fmt.Println("Press enter to exit...")
-			fmt.Scanln()
-			os.Exit(ErrCodeFailure)
-		}
-
-		defaultOutputConfig := util.BuildOutputConfig("", "", false, false)
-		err = cli.HandleDragAndDropModeWithConfig(inputFileName, processFile, defaultOutputConfig)
-		if err != nil {
-			os.Exit(ErrCodeFailure)
-		}
-		os.Exit(ErrCodeSuccess)
+		// DiscoverMKVFiles has already reported the missing and unsupported paths
+		format.PrintError("No MKV files found in the dropped files")
+		fmt.Println(i18n.T("Press enter to exit..."))
+		fmt.Scanln()
+		os.Exit(ErrCodeFailure)
 	}
 
-	flags := struct {
-		Extract        string `short:"x" long:"extract" description:"Extract subtitles from MKV file"`
-		Batch          string `short:"b" long:"batch" description:"Extract subtitles from multiple MKV files using glob pattern (e.g., '*.mkv', 'Season 1/*.mkv')"`
-		Info           string `short:"i" long:"info" description:"Display subtitle track information for MKV file"`
-		Select         string `short:"s" long:"select" description:"Mixed selection of language codes and track IDs (e.g., 'eng,14,spa,16')"`
-		Exclude        string `short:"e" long:"exclude" description:"Mixed exclusion of language codes, track IDs, and formats (e.g., 'chi,15,sup')"`
-		OutputDir      string `short:"o" long:"output-dir" description:"Output directory for extracted subtitle files. If not specified, uses the same directory as the input file"`
-		OutputTemplate string `short:"f" long:"format" description:"Custom filename template with placeholders: {basename}, {language}, {trackno}, {trackname}, {forced}, {default}, {extension}"`
-		DryRun         bool   `short:"d" long:"dry-run" description:"Show what would be extracted without performing extraction"`
-		UseConfig      bool   `short:"c" long:"config" description:"Use default configuration profile"`
-		Profile        string `short:"p" long:"profile" description:"Use named configuration profile"`
-		Version        bool   `short:"v" long:"version" description:"Show version information"`
-	}{}
+	flags := commandFlags{}
 
 	_, cmdErr := gocmd.New(gocmd.Options{
 		Name:        "subscalpelmkv",
@@ -512,62 +1465,246 @@ func main() {
 	}
 
 	// Load configuration if requested
-	var appliedConfig *config.AppliedConfig
+	var profileConfig *config.Config // Set when batch files pick their profile by path
+	var cliFlags commandFlags        // The flags as given, before configuration values are applied
 	if flags.UseConfig || flags.Profile != "" {
-		cfg, err := config.LoadConfigWithFallback()
+		cfg, err := loadConfiguration()
 		if err != nil {
 			format.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
 			os.Exit(ErrCodeFailure)
 		}
 
-		if flags.Profile != "" {
-			appliedConfig, err = cfg.ApplyProfile(flags.Profile)
-			if err != nil {
-				format.PrintError(fmt.Sprintf("Error applying profile '%s': %v", flags.Profile, err))
+		// Without --profile, a profile whose match patterns fit the input is applied automatically
+		profileName := flags.Profile
+		if profileName == "" && flags.Extract != "" && !util.IsRemoteURL(flags.Extract) {
+			profileName = matchProfile(cfg, flags.Extract)
+		}
+		if profileName == "" && flags.Batch != "" && cfg.HasProfileMatches() {
+			if err := validateMatchedProfiles(cfg, flags); err != nil {
+				format.PrintError(err.Error())
 				os.Exit(ErrCodeFailure)
 			}
-		} else {
-			appliedConfig = cfg.ApplyDefaults()
+			profileConfig = cfg
 		}
 
-		// Merge configuration with CLI flags (CLI flags take precedence)
-		cliFlags := config.CLIFlags{
-			OutputTemplate: flags.OutputTemplate,
-			OutputDir:      flags.OutputDir,
+		cliFlags = flags
+		if err := applyConfiguration(&flags, cfg, profileName); err != nil {
+			format.PrintError(fmt.Sprintf("Error applying profile '%s': %v", profileName, err))
+			os.Exit(ErrCodeFailure)
+		}
+	}
+
+	// Load translation backend settings from the configuration file
+	var translationConfig model.TranslationConfig
+	if flags.Translate != "" {
+		if len(cli.ParseLanguageCodes(flags.Translate)) != 1 {
+			format.PrintError(fmt.Sprintf("Invalid translation target language: %s", flags.Translate))
+			os.Exit(ErrCodeFailure)
+		}
+
+		cfg, err := loadConfiguration()
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			os.Exit(ErrCodeFailure)
+		}
+		translationConfig = cfg.Translation
+	}
+
+	// Load the cue cleanup rules from the configuration file
+	var cleanupRules []string
+	if flags.Cleanup {
+		cfg, err := loadConfiguration()
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			os.Exit(ErrCodeFailure)
+		}
+		cleanupRules = cfg.CleanupRules
+		if len(cleanupRules) == 0 {
+			cleanupRules = postprocess.DefaultCleanupRules
 		}
+		if _, err := postprocess.CompileCleanupRules(cleanupRules); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(ErrCodeFailure)
+		}
+	}
 
-		// Parse languages from Select flag if provided
-		if flags.Select != "" {
-			selection := cli.ParseTrackSelection(flags.Select)
-			cliFlags.Languages = selection.LanguageCodes
+	var recipe *model.Recipe
+	if flags.Recipe != "" {
+		var err error
+		if recipe, err = util.LoadRecipe(flags.Recipe); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(ErrCodeFailure)
 		}
+	}
+	if flags.SaveRecipe != "" && flags.Extract == "" {
+		format.PrintError("--save-recipe saves the selection of a single file and needs -x")
+		os.Exit(ErrCodeFailure)
+	}
 
-		// Parse exclusions from Exclude flag if provided
-		if flags.Exclude != "" {
-			exclusion := cli.ParseTrackExclusion(flags.Exclude)
-			var exclusionParts []string
-			exclusionParts = append(exclusionParts, exclusion.LanguageCodes...)
-			for _, trackNum := range exclusion.TrackNumbers {
-				exclusionParts = append(exclusionParts, strconv.Itoa(trackNum))
+	// Load the notification sinks from the configuration file
+	var notifications []model.NotificationConfig
+	if flags.Notify {
+		cfg, err := loadConfiguration()
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			os.Exit(ErrCodeFailure)
+		}
+		if len(cfg.Notifications) == 0 {
+			format.PrintError("--notify needs at least one entry under 'notifications' in the configuration file")
+			os.Exit(ErrCodeFailure)
+		}
+		for _, sink := range cfg.Notifications {
+			if err := notify.Validate(sink); err != nil {
+				format.PrintError(fmt.Sprintf("Invalid notification in the configuration file: %v", err))
+				os.Exit(ErrCodeFailure)
 			}
-			exclusionParts = append(exclusionParts, exclusion.FormatFilters...)
-			cliFlags.Exclusions = exclusionParts
 		}
+		notifications = cfg.Notifications
+	}
 
-		appliedConfig = appliedConfig.MergeWithCLI(cliFlags)
+	if flags.MergeLanguages != "" {
+		primary, secondary, err := postprocess.ParseMergeLanguages(flags.MergeLanguages)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Invalid --merge-languages value: %v", err))
+			os.Exit(ErrCodeFailure)
+		}
+		if len(cli.ParseLanguageCodes(primary+","+secondary)) != 2 {
+			format.PrintError(fmt.Sprintf("Invalid --merge-languages value: %s", flags.MergeLanguages))
+			os.Exit(ErrCodeFailure)
+		}
+	}
+	if err := validateOutputFlags(flags); err != nil {
+		format.PrintError(err.Error())
+		os.Exit(ErrCodeFailure)
+	}
+	if err := model.ValidateLanguageStyle(flags.LangStyle); err != nil {
+		format.PrintError(fmt.Sprintf("Invalid --lang-style value '%s': must be %s, %s, %s or %s", flags.LangStyle, model.LanguageStyleISO6391, model.LanguageStyleISO6392B, model.LanguageStyleISO6392T, model.LanguageStyleName))
+		os.Exit(ErrCodeFailure)
+	}
+	if err := util.ValidateSortOrder(flags.Sort); err != nil {
+		format.PrintError(fmt.Sprintf("Invalid --sort value: %v", err))
+		os.Exit(ErrCodeFailure)
+	}
+	if err := model.ValidatePerLanguage(flags.PerLanguage); err != nil {
+		format.PrintError(fmt.Sprintf("Invalid --per-language value: %v", err))
+		os.Exit(ErrCodeFailure)
+	}
+	if flags.StableFor != "" {
+		if window, err := time.ParseDuration(flags.StableFor); err != nil || window < 0 {
+			format.PrintError(fmt.Sprintf("Invalid --stable-for value '%s': must be a duration such as 30s or 2m", flags.StableFor))
+			os.Exit(ErrCodeFailure)
+		}
+	}
+	if err := model.ValidateUndPolicy(flags.UndPolicy); err != nil {
+		format.PrintError(fmt.Sprintf("Invalid --und-policy value: %v", err))
+		os.Exit(ErrCodeFailure)
+	}
+	if flags.MinCoverage < 0 || flags.MinCoverage > 100 {
+		format.PrintError(fmt.Sprintf("Invalid --min-coverage value %d: must be a percentage from 0 to 100", flags.MinCoverage))
+		os.Exit(ErrCodeFailure)
+	}
+	if flags.MaxPerLanguage < 0 {
+		format.PrintError(fmt.Sprintf("Invalid --max-per-language value %d: must not be negative", flags.MaxPerLanguage))
+		os.Exit(ErrCodeFailure)
+	}
+	if flags.MinEntries < 0 {
+		format.PrintError(fmt.Sprintf("Invalid --min-entries value %d: must not be negative", flags.MinEntries))
+		os.Exit(ErrCodeFailure)
+	}
+	if flags.Filter != "" {
+		if _, err := model.ParseTrackFilter(flags.Filter); err != nil {
+			format.PrintError(fmt.Sprintf("Invalid --filter value: %v", err))
+			os.Exit(ErrCodeFailure)
+		}
+	}
+	if flags.SplitByChapters && flags.MergeLanguages != "" {
+		format.PrintError("--split-by-chapters cannot be combined with --merge-languages")
+		os.Exit(ErrCodeFailure)
+	}
+	if flags.StripASS != "" && flags.StripASS != postprocess.StripASSKeepASS && flags.StripASS != postprocess.StripASSToSRT {
+		format.PrintError(fmt.Sprintf("Invalid --strip-ass value '%s': must be ass or srt", flags.StripASS))
+		os.Exit(ErrCodeFailure)
+	}
+	if flags.StripHI != "" && flags.StripHI != postprocess.StripHICopy && flags.StripHI != postprocess.StripHIReplace {
+		format.PrintError(fmt.Sprintf("Invalid --strip-hi value '%s': must be copy or replace", flags.StripHI))
+		os.Exit(ErrCodeFailure)
+	}
+	if flags.FixLanguageTags {
+		if _, err := exec.LookPath("mkvpropedit"); err != nil {
+			format.PrintError("--fix-language-tags requires mkvpropedit, which was not found in PATH")
+			os.Exit(ErrCodeFailure)
+		}
+	}
+	if flags.SyncToAudio {
+		if _, err := exec.LookPath("ffmpeg"); err != nil {
+			format.PrintError("--sync-to-audio requires ffmpeg, which was not found in PATH")
+			os.Exit(ErrCodeFailure)
+		}
+	}
+	if flags.Convert != "" && flags.Convert != postprocess.ConvertASS {
+		format.PrintError(fmt.Sprintf("Invalid --convert value '%s': must be ass", flags.Convert))
+		os.Exit(ErrCodeFailure)
+	}
+	if flags.Convert != "" && flags.StripASS == postprocess.StripASSToSRT {
+		format.PrintError("--convert ass cannot be combined with --strip-ass srt")
+		os.Exit(ErrCodeFailure)
+	}
+	if flags.Style != "" {
+		if flags.Convert == "" {
+			format.PrintError("--style requires --convert ass")
+			os.Exit(ErrCodeFailure)
+		}
+		if _, _, err := subtitle.StyleTemplate(flags.Style); err != nil {
+			format.PrintError(fmt.Sprintf("Invalid --style template: %v", err))
+			os.Exit(ErrCodeFailure)
+		}
+	}
+	if flags.MergeFormat != "" && !strings.EqualFold(flags.MergeFormat, "srt") && !strings.EqualFold(flags.MergeFormat, "ass") {
+		format.PrintError(fmt.Sprintf("Invalid --merge-format value '%s': must be srt or ass", flags.MergeFormat))
+		os.Exit(ErrCodeFailure)
+	}
 
-		// Apply config values back to flags if they weren't set via CLI
-		if flags.OutputTemplate == "" && appliedConfig.OutputTemplate != "" {
-			flags.OutputTemplate = appliedConfig.OutputTemplate
+	if flags.Stdout {
+		if flags.Extract == "" {
+			format.PrintError("--stdout requires --extract with a single file")
+			os.Exit(ErrCodeFailure)
 		}
-		if flags.OutputDir == "" && appliedConfig.OutputDir != "" {
-			flags.OutputDir = appliedConfig.OutputDir
+		if flags.DryRun || flags.AllTracks {
+			format.PrintError("--stdout cannot be combined with --dry-run or --all-tracks")
+			os.Exit(ErrCodeFailure)
 		}
-		if flags.Select == "" && len(appliedConfig.Languages) > 0 {
-			flags.Select = strings.Join(appliedConfig.Languages, ",")
+	}
+
+	if flags.Porcelain && (flags.Stdout || flags.ProgressJSON) {
+		format.PrintError("--porcelain cannot be combined with --stdout or --progress-json, which also write to stdout")
+		os.Exit(ErrCodeFailure)
+	}
+
+	if flags.ProgressJSON || flags.ProgressFile != "" {
+		var progressWriter io.Writer = progressOut
+		if flags.ProgressFile != "" {
+			// Opening a FIFO waits for the program reading it
+			file, err := os.OpenFile(flags.ProgressFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+			if err != nil {
+				format.PrintError(fmt.Sprintf("Could not open --progress-json-file: %v", err))
+				os.Exit(ErrCodeFailure)
+			}
+			progressWriter = file
+		} else if flags.Stdout {
+			format.PrintError("--progress-json and --stdout both write to stdout; use --progress-json-file <path> instead")
+			os.Exit(ErrCodeFailure)
 		}
-		if flags.Exclude == "" && len(appliedConfig.Exclusions) > 0 {
-			flags.Exclude = strings.Join(appliedConfig.Exclusions, ",")
+		mkv.SetProgressFunc(cli.JSONProgressFunc(progressWriter, cli.PrintMKVEvent))
+	}
+
+	if (flags.Backup || flags.BackupDir != "") && !flags.Force {
+		format.PrintError("--backup and --backup-dir only apply to files overwritten with --force")
+		os.Exit(ErrCodeFailure)
+	}
+
+	if flags.LowPriority {
+		if err := mkv.SetLowPriority(true); err != nil {
+			format.PrintWarning(fmt.Sprintf("--low-priority is not available (%v), running at normal priority", err))
 		}
 	}
 
@@ -582,35 +1719,147 @@ func main() {
 		inputFileName := flags.Extract
 		selectionFilter := cli.BuildSelectionFilter(flags.Select)
 
-		outputConfig := util.BuildOutputConfig(flags.OutputDir, flags.OutputTemplate, hasOutputFlagWithoutValue, false)
+		outputConfig := buildOutputConfig(flags, hasOutputFlagWithoutValue, false, translationConfig)
+		outputConfig.CleanupRules = cleanupRules
+		outputConfig.Notifications = notifications
+		outputConfig.Recipe = recipe
+
+		// --stdout extracts into a temporary directory and copies the file out
+		// os.Exit skips deferred calls, so every exit below removes stdoutDir itself
+		var stdoutDir string
+		if flags.Stdout {
+			tempDir, err := os.MkdirTemp("", "subscalpelmkv-stdout-")
+			if err != nil {
+				format.PrintError(fmt.Sprintf("Could not create temporary directory: %v", err))
+				os.Exit(ErrCodeFailure)
+			}
+			stdoutDir = tempDir
+			outputConfig.OutputDir = tempDir
+		}
+
+		// Download remote input first; its subtitles go to the current directory unless -o is given
+		if util.IsRemoteURL(inputFileName) {
+			localFileName, cleanup, err := util.DownloadToTemp(ctx, inputFileName)
+			if err != nil {
+				format.PrintError(err.Error())
+				if stdoutDir != "" {
+					os.RemoveAll(stdoutDir)
+				}
+				os.Exit(ErrCodeFailure)
+			}
+			outputConfig.SourceURL = inputFileName
+			inputFileName = localFileName
+
+			if outputConfig.OutputDir == "" {
+				outputConfig.OutputDir = "."
+			} else if outputConfig.OutputDir == "__BASENAME_SUBTITLES__" {
+				outputConfig.OutputDir = naming.ResolveOutputDirectory(outputConfig.OutputDir, filepath.Base(inputFileName))
+			}
+
+			start := time.Now()
+			result, err := processFile(ctx, inputFileName, selectionFilter, flags.Exclude, true, outputConfig, flags.DryRun)
+			cleanup()
+			if flags.Porcelain {
+				cli.PrintPorcelainResult(porcelainOut, outputConfig.SourceURL, result, err)
+			}
+			if !flags.DryRun {
+				summary := notify.Summary{Mode: "extract", Duration: time.Since(start)}
+				summary.AddFile(outputConfig.SourceURL, result, err)
+				sendNotifications(ctx, outputConfig.Notifications, summary)
+			}
+			if flags.Stdout {
+				if err == nil {
+					err = writeToStdout(subtitleOut, result, stdoutDir)
+				} else {
+					os.RemoveAll(stdoutDir)
+				}
+			}
+			if err != nil {
+				os.Exit(ErrCodeFailure)
+			}
+			os.Exit(ErrCodeSuccess)
+		}
 
 		// Resolve special output directory for single file
 		if outputConfig.OutputDir == "__BASENAME_SUBTITLES__" {
-			outputConfig.OutputDir = util.ResolveOutputDirectory(outputConfig.OutputDir, inputFileName)
+			outputConfig.OutputDir = naming.ResolveOutputDirectory(outputConfig.OutputDir, inputFileName)
 		}
 
-		err := processFile(inputFileName, selectionFilter, flags.Exclude, true, outputConfig, flags.DryRun)
+		start := time.Now()
+		result, err := processFile(ctx, inputFileName, selectionFilter, flags.Exclude, true, outputConfig, flags.DryRun)
+		if flags.Porcelain {
+			cli.PrintPorcelainResult(porcelainOut, inputFileName, result, err)
+		}
+		if !flags.DryRun {
+			summary := notify.Summary{Mode: "extract", Duration: time.Since(start)}
+			summary.AddFile(filepath.Base(inputFileName), result, err)
+			sendNotifications(ctx, outputConfig.Notifications, summary)
+		}
+		if flags.Stdout {
+			if err == nil {
+				err = writeToStdout(subtitleOut, result, stdoutDir)
+			} else {
+				os.RemoveAll(stdoutDir)
+			}
+		}
 		if err != nil {
 			os.Exit(ErrCodeFailure)
 		}
+		if !flags.DryRun && !flags.Stdout {
+			batch.PrintDiskUsage(result.Outputs)
+		}
 	} else if flags.Batch != "" {
 		pattern := flags.Batch
 		selectionFilter := cli.BuildSelectionFilter(flags.Select)
 
-		outputConfig := util.BuildOutputConfig(flags.OutputDir, flags.OutputTemplate, hasOutputFlagWithoutValue, true)
+		outputConfig := buildOutputConfig(flags, hasOutputFlagWithoutValue, true, translationConfig)
+		outputConfig.CleanupRules = cleanupRules
+		outputConfig.Notifications = notifications
+		outputConfig.Recipe = recipe
 
-		err := processBatch(pattern, selectionFilter, flags.Exclude, true, outputConfig, flags.DryRun)
+		processFunc := batch.ProcessFileFunc(processFile)
+		if profileConfig != nil {
+			processFunc = profileProcessFunc(profileConfig, cliFlags, hasOutputFlagWithoutValue, translationConfig)
+		}
+		if flags.Porcelain {
+			processFunc = porcelainProcessFunc(porcelainOut, processFunc)
+		}
+
+		err := processBatch(ctx, pattern, selectionFilter, flags.Exclude, true, outputConfig, flags.DryRun, processFunc)
 		if err != nil {
 			os.Exit(ErrCodeFailure)
 		}
 	} else if flags.Info != "" {
 		inputFileName := flags.Info
-		err := cli.ShowFileInfo(inputFileName)
+		if util.IsRemoteURL(inputFileName) {
+			localFileName, cleanup, err := util.DownloadToTemp(ctx, inputFileName)
+			if err != nil {
+				format.PrintError(err.Error())
+				os.Exit(ErrCodeFailure)
+			}
+			if flags.Porcelain {
+				err = cli.PrintPorcelainInfo(ctx, porcelainOut, localFileName, inputFileName)
+			} else {
+				err = cli.ShowFileInfo(ctx, localFileName)
+			}
+			cleanup()
+			if err != nil {
+				os.Exit(ErrCodeFailure)
+			}
+			os.Exit(ErrCodeSuccess)
+		}
+
+		var err error
+		if flags.Porcelain {
+			err = cli.PrintPorcelainInfo(ctx, porcelainOut, inputFileName, inputFileName)
+		} else {
+			err = cli.ShowFileInfo(ctx, inputFileName)
+		}
 		if err != nil {
 			os.Exit(ErrCodeFailure)
 		}
 	} else {
-		cli.ShowHelp()
+		cli.ShowHelp(commandOptions())
 		os.Exit(ErrCodeFailure)
 	}
 
@@ -0,0 +1,160 @@
+package audiosync
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"subscalpelmkv/internal/subtitle"
+)
+
+// FrameDuration is the resolution of the speech and subtitle activity signals
+const FrameDuration = 10 * time.Millisecond
+
+// MaxOffset is the largest shift searched for in either direction
+const MaxOffset = 60 * time.Second
+
+// sampleRate is the rate ffmpeg resamples the audio to; speech needs no more
+const sampleRate = 16000
+
+// samplesPerFrame is the number of samples in one FrameDuration
+const samplesPerFrame = sampleRate / int(time.Second/FrameDuration)
+
+// frameRateScales are the speed ratios tried, covering subtitles timed for another frame rate
+// (PAL speed-up and 23.976/24 fps mix-ups) as well as correctly timed ones
+var frameRateScales = []float64{1, 25 / 23.976, 23.976 / 25, 25.0 / 24, 24 / 25.0, 24 / 23.976, 23.976 / 24}
+
+// Result is the correction that best lines subtitles up with speech: t' = t*Scale + Offset
+type Result struct {
+	Offset    time.Duration
+	Scale     float64
+	Agreement float64 // Share of frames where subtitle and speech activity agree after the correction
+}
+
+// SpeechActivity decodes an audio track with ffmpeg and returns one value per FrameDuration:
+// 1 where the frame is loud enough to be speech and -1 elsewhere
+func SpeechActivity(ctx context.Context, inputFileName string, trackId int) ([]float64, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-nostdin", "-v", "error", "-i", inputFileName,
+		"-map", fmt.Sprintf("0:%d", trackId), "-vn", "-ac", "1", "-ar", fmt.Sprint(sampleRate), "-f", "s16le", "-")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ffmpeg: %v", err)
+	}
+
+	// Frame energies in decibels
+	var energies []float64
+	reader := bufio.NewReaderSize(stdout, 64*1024)
+	frame := make([]byte, samplesPerFrame*2)
+	for {
+		n, readErr := io.ReadFull(reader, frame)
+		if n >= 2 {
+			sum := 0.0
+			for i := 0; i+1 < n; i += 2 {
+				sample := float64(int16(binary.LittleEndian.Uint16(frame[i:])))
+				sum += sample * sample
+			}
+			energies = append(energies, 10*math.Log10(sum/float64(n/2)+1))
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if len(energies) == 0 {
+		return nil, fmt.Errorf("ffmpeg decoded no audio")
+	}
+
+	// Frames well above the noise floor, relative to the loud parts of this track, count as speech
+	sorted := append([]float64(nil), energies...)
+	sort.Float64s(sorted)
+	noise := sorted[len(sorted)/10]
+	loud := sorted[len(sorted)*9/10]
+	threshold := noise + 0.4*(loud-noise)
+
+	activity := make([]float64, len(energies))
+	for i, energy := range energies {
+		activity[i] = -1
+		if energy > threshold {
+			activity[i] = 1
+		}
+	}
+	return activity, nil
+}
+
+// Align finds the offset and frame-rate scale that best match the cues with the speech activity
+func Align(speech []float64, cues []subtitle.Cue) Result {
+	maxLag := int(MaxOffset / FrameDuration)
+	best := Result{Scale: 1}
+	bestScore := math.Inf(-1)
+	for _, scale := range frameRateScales {
+		activity := subtitleActivity(cues, scale)
+		correlation := crossCorrelate(speech, activity)
+		zero := len(activity) - 1
+		for lag := -maxLag; lag <= maxLag; lag++ {
+			index := zero + lag
+			if index < 0 || index >= len(correlation) {
+				continue
+			}
+			if score := correlation[index]; score > bestScore {
+				bestScore = score
+				best = Result{Offset: time.Duration(lag) * FrameDuration, Scale: scale}
+			}
+		}
+	}
+
+	// Correlating ±1 signals counts agreeing frames minus disagreeing ones
+	frames := float64(len(speech))
+	best.Agreement = math.Max(0, math.Min(1, (bestScore/frames+1)/2))
+	return best
+}
+
+// Apply returns the cues retimed by the correction, dropping cues that end up before the start
+func Apply(cues []subtitle.Cue, result Result) []subtitle.Cue {
+	var retimed []subtitle.Cue
+	for _, cue := range cues {
+		cue.Start = time.Duration(float64(cue.Start)*result.Scale) + result.Offset
+		cue.End = time.Duration(float64(cue.End)*result.Scale) + result.Offset
+		if cue.End <= 0 {
+			continue
+		}
+		cue.Start = max(cue.Start, 0)
+		cue.Index = len(retimed) + 1
+		retimed = append(retimed, cue)
+	}
+	return retimed
+}
+
+// subtitleActivity returns one value per FrameDuration: 1 while a scaled cue is shown and -1 elsewhere
+func subtitleActivity(cues []subtitle.Cue, scale float64) []float64 {
+	var last time.Duration
+	for _, cue := range cues {
+		last = max(last, cue.End)
+	}
+
+	activity := make([]float64, int(float64(last)*scale/float64(FrameDuration))+1)
+	for i := range activity {
+		activity[i] = -1
+	}
+	for _, cue := range cues {
+		start := int(float64(cue.Start) * scale / float64(FrameDuration))
+		end := min(int(float64(cue.End)*scale/float64(FrameDuration)), len(activity))
+		for i := max(start, 0); i < end; i++ {
+			activity[i] = 1
+		}
+	}
+	return activity
+}
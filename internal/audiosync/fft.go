@@ -0,0 +1,74 @@
+package audiosync
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// fft computes the discrete Fourier transform of x in place; len(x) must be a power of two.
+// With inverse set it computes the unscaled inverse transform.
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+
+	// Bit-reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even, odd := x[start+k], w*x[start+k+size/2]
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+}
+
+// crossCorrelate returns c where c[lag+len(b)-1] = sum over i of a[i+lag] * b[i], for every lag
+// from -(len(b)-1) to len(a)-1
+func crossCorrelate(a, b []float64) []float64 {
+	resultLen := len(a) + len(b) - 1
+	n := 1
+	for n < resultLen {
+		n <<= 1
+	}
+
+	fa := make([]complex128, n)
+	fb := make([]complex128, n)
+	for i, v := range a {
+		fa[i] = complex(v, 0)
+	}
+	// Reversing b turns the convolution into a correlation
+	for i, v := range b {
+		fb[len(b)-1-i] = complex(v, 0)
+	}
+
+	fft(fa, false)
+	fft(fb, false)
+	for i := range fa {
+		fa[i] *= fb[i]
+	}
+	fft(fa, true)
+
+	result := make([]float64, resultLen)
+	for i := range result {
+		result[i] = real(fa[i]) / float64(n)
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"subscalpelmkv/internal/mkv"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/util"
+)
+
+// Output formats of the audit report
+const (
+	FormatTable = "table"
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+)
+
+// FileReport describes how one file covers the required languages
+type FileReport struct {
+	File      string            `json:"file"`
+	Languages []string          `json:"languages"`          // Languages of the file's subtitle tracks
+	Sidecars  map[string]string `json:"sidecars,omitempty"` // Required language -> external subtitle file covering it
+	Missing   []string          `json:"missing"`            // Required languages found neither inside nor beside the file
+	Error     string            `json:"error,omitempty"`    // Why the file could not be analyzed
+}
+
+// Complete reports whether the file has every required language
+func (r FileReport) Complete() bool {
+	return r.Error == "" && len(r.Missing) == 0
+}
+
+// AuditFile checks an MKV file's subtitle tracks and sidecar files for the required languages
+func AuditFile(ctx context.Context, inputFileName string, required []string) FileReport {
+	report := FileReport{File: inputFileName, Languages: []string{}, Missing: []string{}}
+
+	mkvInfo, err := mkv.GetTrackInfo(ctx, inputFileName)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	for _, track := range mkvInfo.Tracks {
+		if track.Type == "subtitles" && !slices.Contains(report.Languages, track.Properties.Language) {
+			report.Languages = append(report.Languages, track.Properties.Language)
+		}
+	}
+
+	for _, language := range required {
+		if matchesAny(report.Languages, language) {
+			continue
+		}
+		if sidecar := util.FindLanguageSidecar(inputFileName, language); sidecar != "" {
+			if report.Sidecars == nil {
+				report.Sidecars = make(map[string]string)
+			}
+			report.Sidecars[language] = sidecar
+			continue
+		}
+		report.Missing = append(report.Missing, language)
+	}
+	return report
+}
+
+// WriteCSV writes one row per report: file, subtitle languages, sidecar files, missing languages, error
+func WriteCSV(w io.Writer, reports []FileReport) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"file", "languages", "sidecars", "missing", "error"})
+	for _, report := range reports {
+		var sidecars []string
+		for language, path := range report.Sidecars {
+			sidecars = append(sidecars, language+"="+path)
+		}
+		sort.Strings(sidecars)
+		writer.Write([]string{
+			report.File,
+			strings.Join(report.Languages, " "),
+			strings.Join(sidecars, " "),
+			strings.Join(report.Missing, " "),
+			report.Error,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the reports as an indented JSON array
+func WriteJSON(w io.Writer, reports []FileReport) error {
+	if reports == nil {
+		reports = []FileReport{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(reports)
+}
+
+// matchesAny reports whether one of the track languages satisfies the required language
+func matchesAny(trackLanguages []string, required string) bool {
+	for _, language := range trackLanguages {
+		if model.MatchesLanguageFilter(language, required) {
+			return true
+		}
+	}
+	return false
+}
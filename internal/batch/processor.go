@@ -1,17 +1,23 @@
 package batch
 
 import (
+	"cmp"
+	"context"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/i18n"
 	"subscalpelmkv/internal/mkv"
 	"subscalpelmkv/internal/model"
 	"subscalpelmkv/internal/util"
 )
 
 // ProcessFileFunc is the function signature for processing a single file
-type ProcessFileFunc func(inputFileName, languageFilter, exclusionFilter string, showFilterMessage bool, outputConfig model.OutputConfig, dryRun bool) error
+type ProcessFileFunc func(ctx context.Context, inputFileName, languageFilter, exclusionFilter string, showFilterMessage bool, outputConfig model.OutputConfig, dryRun bool) (model.FileResult, error)
 
 // Processor handles batch processing of MKV files
 type Processor struct {
@@ -25,6 +31,15 @@ type ProcessingResult struct {
 	SuccessCount int
 	ErrorCount   int
 	TotalFiles   int
+	Files        []FileSummary
+}
+
+// FileSummary records the outcome of one file for the summary table
+type FileSummary struct {
+	FileName string
+	Result   model.FileResult
+	Duration time.Duration
+	Err      error
 }
 
 // NewProcessor creates a new batch processor
@@ -36,16 +51,41 @@ func NewProcessor(files []string, outputConfig model.OutputConfig, dryRun bool)
 	}
 }
 
-// Process executes the batch processing with the given processing function
-func (p *Processor) Process(processFunc ProcessFileFunc, languageFilter, exclusionFilter string) (*ProcessingResult, error) {
+// Process executes the batch processing with the given processing function. It stops before the
+// next file once ctx is done, returning the results so far with the context's error.
+func (p *Processor) Process(ctx context.Context, processFunc ProcessFileFunc, languageFilter, exclusionFilter string) (*ProcessingResult, error) {
 	result := &ProcessingResult{
 		TotalFiles: len(p.Files),
 	}
 
+	// A dry run only collects each file's plan; PrintSummary shows them together as a tree
+	outputConfig := p.OutputConfig
+	outputConfig.PlanOnly = p.DryRun
+
 	for i, file := range p.Files {
-		format.PrintSubSection(fmt.Sprintf("Processing file %d/%d: %s", i+1, len(p.Files), filepath.Base(file)))
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if !p.DryRun {
+			format.PrintSubSection(fmt.Sprintf("Processing file %d/%d: %s", i+1, len(p.Files), filepath.Base(file)))
+		}
 		
-		err := processFunc(file, languageFilter, exclusionFilter, false, p.OutputConfig, p.DryRun)
+		start := time.Now()
+		fileResult, err := processFunc(ctx, file, languageFilter, exclusionFilter, false, outputConfig, p.DryRun)
+		result.Files = append(result.Files, FileSummary{
+			FileName: filepath.Base(file),
+			Result:   fileResult,
+			Duration: time.Since(start),
+			Err:      err,
+		})
+		if p.DryRun {
+			if err != nil {
+				result.ErrorCount++
+			} else {
+				result.SuccessCount++
+			}
+			continue
+		}
 		if err != nil {
 			format.PrintError(fmt.Sprintf("Failed to process %s: %v", file, err))
 			result.ErrorCount++
@@ -65,26 +105,221 @@ func (p *Processor) Process(processFunc ProcessFileFunc, languageFilter, exclusi
 
 // PrintSummary displays the batch processing summary
 func (p *Processor) PrintSummary(result *ProcessingResult) {
+	if p.DryRun {
+		format.PrintSubSection("Dry Run")
+		printPlanTree(result.Files)
+	}
 	format.PrintSubSection("Batch Processing Summary")
+	if len(result.Files) > 0 {
+		fmt.Println()
+		p.printSummaryTable(result.Files)
+		fmt.Println()
+	}
 	format.PrintInfo(fmt.Sprintf("Total files: %d", result.TotalFiles))
+	if p.DryRun {
+		var estimatedBytes int64
+		for _, file := range result.Files {
+			estimatedBytes += file.Result.OutputBytes
+		}
+		if estimatedBytes > 0 {
+			format.PrintInfo(fmt.Sprintf("Estimated output size: %s", util.FormatSize(estimatedBytes)))
+		}
+	}
 	format.PrintSuccess(fmt.Sprintf("Successfully processed: %d", result.SuccessCount))
 	if result.ErrorCount > 0 {
 		format.PrintError(fmt.Sprintf("Failed to process: %d", result.ErrorCount))
 	}
+	if !p.DryRun {
+		var outputs []model.OutputFile
+		for _, file := range result.Files {
+			outputs = append(outputs, file.Result.Outputs...)
+		}
+		PrintDiskUsage(outputs)
+	}
+}
+
+// PrintDiskUsage reports the bytes written by a run, broken down by format, and its largest files
+func PrintDiskUsage(outputs []model.OutputFile) {
+	if len(outputs) == 0 {
+		return
+	}
+
+	var total int64
+	type formatUsage struct {
+		name  string
+		bytes int64
+		files int
+	}
+	var formats []formatUsage
+	for _, output := range outputs {
+		total += output.Bytes
+		name := strings.ToLower(strings.TrimPrefix(filepath.Ext(output.Path), "."))
+		if i := slices.IndexFunc(formats, func(usage formatUsage) bool { return usage.name == name }); i >= 0 {
+			formats[i].bytes += output.Bytes
+			formats[i].files++
+		} else {
+			formats = append(formats, formatUsage{name: name, bytes: output.Bytes, files: 1})
+		}
+	}
+	slices.SortStableFunc(formats, func(a, b formatUsage) int { return cmp.Compare(b.bytes, a.bytes) })
+
+	format.PrintSubSection("Disk Usage")
+	format.PrintInfo(fmt.Sprintf("Written: %s in %d file(s)", util.FormatSize(total), len(outputs)))
+	if len(formats) > 1 {
+		var parts []string
+		for _, usage := range formats {
+			parts = append(parts, fmt.Sprintf("%s %s (%d)", usage.name, util.FormatSize(usage.bytes), usage.files))
+		}
+		format.PrintInfo("By format: " + strings.Join(parts, " • "))
+	}
+
+	const largestCount = 3
+	if len(outputs) > largestCount {
+		largest := slices.Clone(outputs)
+		slices.SortStableFunc(largest, func(a, b model.OutputFile) int { return cmp.Compare(b.Bytes, a.Bytes) })
+		format.PrintInfo("Largest:")
+		for _, output := range largest[:min(largestCount, len(largest))] {
+			format.PrintExample(fmt.Sprintf("  %10s  %s", util.FormatSize(output.Bytes), filepath.Base(output.Path)))
+		}
+	}
+}
+
+// printSummaryTable prints one aligned row per file with its tracks, output size and duration
+func (p *Processor) printSummaryTable(files []FileSummary) {
+	tracksHeader, sizeHeader := "Tracks", "Size"
+	if p.DryRun {
+		tracksHeader, sizeHeader = "Planned", "Est. size"
+	}
+	const numberWidth, sizeWidth, timeWidth = 9, 10, 8
+	nameWidth := format.BoxWidth - 4 - 3*numberWidth - sizeWidth - timeWidth // "  ✓ " and the other columns
+	if nameWidth < 12 {
+		nameWidth = 12
+	}
+
+	format.BaseDim.Printf("    %s%*s%*s%*s%*s\n", padRight("File", nameWidth), numberWidth, tracksHeader, numberWidth, "Skipped", sizeWidth, sizeHeader, timeWidth, "Time")
+	for _, file := range files {
+		fmt.Print("  ")
+		if file.Err != nil {
+			format.ErrorColor.Print("✗ ")
+		} else {
+			format.SuccessColor.Print("✓ ")
+		}
+		format.BaseFg.Print(padRight(format.Truncate(file.FileName, nameWidth-1), nameWidth))
+
+		size := "-"
+		if file.Result.OutputBytes > 0 {
+			size = util.FormatSize(file.Result.OutputBytes)
+		}
+		fmt.Printf("%*d%*d%*s", numberWidth, file.Result.Extracted, numberWidth, file.Result.Skipped, sizeWidth, size)
+		format.BaseDim.Printf("%*s\n", timeWidth, formatDuration(file.Duration))
+	}
+}
+
+// printPlanTree prints the tracks of each file under its name, marking the ones that would be
+// extracted and giving the reason for the others
+func printPlanTree(files []FileSummary) {
+	for _, file := range files {
+		fmt.Println()
+		fmt.Print("  ")
+		if file.Err != nil {
+			format.ErrorColor.Print("✗ ")
+			format.BaseHighlight.Println(file.FileName)
+			format.ErrorColor.Printf("    └─ %v\n", file.Err)
+			continue
+		}
+		format.BaseHighlight.Println(file.FileName)
+
+		plan := slices.Clone(file.Result.Plan)
+		slices.SortStableFunc(plan, func(a, b model.TrackPlan) int {
+			return a.Track.Properties.Number - b.Track.Properties.Number
+		})
+		if len(plan) == 0 {
+			format.BaseDim.Println("    └─ no subtitle tracks")
+		}
+		for i, entry := range plan {
+			branch, indent := "├─", "│ "
+			if i == len(plan)-1 {
+				branch, indent = "└─", "  "
+			}
+			format.BorderColor.Print("    " + branch + " ")
+			if entry.Reason == "" {
+				format.SuccessColor.Print("✓ ")
+				format.BaseFg.Println(trackLabel(entry.Track))
+				format.PrintExample(fmt.Sprintf("  %s    → %s%s", indent, entry.OutFileName, estimatedSizeSuffix(entry.Track))) // PrintExample indents by two more
+			} else {
+				format.BaseDim.Printf("· %s: %s\n", trackLabel(entry.Track), entry.Reason)
+			}
+		}
+	}
+}
+
+// estimatedSizeSuffix returns the estimated output size of a track as " (≈ 1.2 MiB)", or "" if unknown
+func estimatedSizeSuffix(track model.MKVTrack) string {
+	if size, ok := track.EstimatedOutputSize(); ok {
+		return fmt.Sprintf(" (≈ %s)", util.FormatSize(size))
+	}
+	return ""
+}
+
+// trackLabel describes a track as in the single-file dry run, e.g. "Track 3 (eng) - Full [SRT, forced]"
+func trackLabel(track model.MKVTrack) string {
+	codecType := "Unknown"
+	if ext := model.TrackExtension(track.Properties.CodecId); ext != "" {
+		codecType = strings.ToUpper(ext)
+	}
+	label := fmt.Sprintf("Track %d (%s)", track.Properties.Number, track.Properties.Language)
+	if track.Properties.TrackName != "" {
+		label += fmt.Sprintf(" - %s", track.Properties.TrackName)
+	}
+	attributes := []string{codecType}
+	if track.Properties.Forced {
+		attributes = append(attributes, "forced")
+	}
+	if track.Properties.Default {
+		attributes = append(attributes, "default")
+	}
+	return fmt.Sprintf("%s [%s]", label, strings.Join(attributes, ", "))
+}
+
+// padRight pads text with spaces to width terminal columns
+func padRight(text string, width int) string {
+	if padding := width - format.DisplayWidth(text); padding > 0 {
+		return text + strings.Repeat(" ", padding)
+	}
+	return text
+}
+
+// formatDuration formats a duration as "850ms", "12.3s" or "4m05s"
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	default:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
 }
 
 // AnalyzeFiles analyzes a list of files and returns their information
-func AnalyzeFiles(files []string) []model.BatchFileInfo {
+func AnalyzeFiles(ctx context.Context, files []string) []model.BatchFileInfo {
 	var batchFileInfos []model.BatchFileInfo
 	
-	for _, file := range files {
+	// One spinner for the whole run; GetTrackInfo's own spinner stays hidden while it is active
+	spinner := util.StartSpinner("")
+	defer spinner.Stop()
+
+	for i, file := range files {
+		label := i18n.T("Analyzing %d/%d:", i+1, len(files))
+		spinner.SetLabel(label + " " + format.Truncate(filepath.Base(file), format.BoxWidth-format.DisplayWidth(label)-14)) // Room for the spinner and elapsed time
+
 		fileInfo := model.BatchFileInfo{
 			FileName: filepath.Base(file),
 			FilePath: file,
 		}
 		
 		// Try to get track information for this file
-		mkvInfo, err := mkv.GetTrackInfo(file)
+		mkvInfo, err := mkv.GetTrackInfo(ctx, file)
 		if err != nil {
 			fileInfo.HasError = true
 			fileInfo.ErrorMessage = fmt.Sprintf("Failed to analyze: %v", err)
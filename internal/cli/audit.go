@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"subscalpelmkv/internal/audit"
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/i18n"
+	"subscalpelmkv/internal/mkv"
+	"subscalpelmkv/internal/util"
+)
+
+// HandleAuditCommand runs the `audit <dir>... --require <langs> [--format table|csv|json] [--all]`
+// subcommand, which reports the files lacking subtitles in the required languages. CSV and JSON go
+// to stdout without the title box so they can be redirected.
+func HandleAuditCommand(args []string, version string) error {
+	var paths, required []string
+	outputFormat := audit.FormatTable
+	showAll := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--require" || arg == "--format":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			if arg == "--require" {
+				required = splitLanguages(args[i+1])
+			} else {
+				outputFormat = strings.ToLower(args[i+1])
+			}
+			i++
+		case strings.HasPrefix(arg, "--require="):
+			required = splitLanguages(strings.TrimPrefix(arg, "--require="))
+		case strings.HasPrefix(arg, "--format="):
+			outputFormat = strings.ToLower(strings.TrimPrefix(arg, "--format="))
+		case arg == "--all":
+			showAll = true
+		case arg == "--lang":
+			// Already applied by main before the command runs
+			i++
+		case strings.HasPrefix(arg, "--lang="):
+		case strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unknown audit option '%s'", arg)
+		default:
+			paths = append(paths, arg)
+		}
+	}
+
+	if outputFormat == audit.FormatTable {
+		format.PrintTitleWithVersion(version)
+		mkv.SetProgressFunc(PrintMKVEvent)
+	}
+	if len(paths) == 0 || len(required) == 0 {
+		return fmt.Errorf("usage: subscalpelmkv audit <dir|file>... --require <languages> [--format table|csv|json] [--all]")
+	}
+	if outputFormat != audit.FormatTable && outputFormat != audit.FormatCSV && outputFormat != audit.FormatJSON {
+		return fmt.Errorf("invalid audit format '%s': must be table, csv or json", outputFormat)
+	}
+
+	files, err := collectMKVFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	var reports []audit.FileReport
+	incomplete := 0
+	for _, file := range files {
+		report := audit.AuditFile(context.Background(), file, required)
+		if !report.Complete() {
+			incomplete++
+		}
+		if showAll || !report.Complete() {
+			reports = append(reports, report)
+		}
+	}
+
+	switch outputFormat {
+	case audit.FormatCSV:
+		return audit.WriteCSV(os.Stdout, reports)
+	case audit.FormatJSON:
+		return audit.WriteJSON(os.Stdout, reports)
+	}
+
+	format.PrintSubSection(i18n.T("Audit: %s", strings.Join(required, ", ")))
+	fmt.Println()
+	for _, report := range reports {
+		switch {
+		case report.Error != "":
+			format.PrintError(fmt.Sprintf("%s: %s", report.File, report.Error))
+		case len(report.Missing) > 0:
+			format.PrintWarning(i18n.T("%s: missing %s", report.File, strings.Join(report.Missing, ", ")))
+		default:
+			format.PrintSuccess(report.File)
+		}
+		for language, sidecar := range report.Sidecars {
+			format.PrintExample(fmt.Sprintf("    %s: %s", language, sidecar))
+		}
+	}
+
+	fmt.Println()
+	if incomplete == 0 {
+		format.PrintSuccess(i18n.T("All %d file(s) have subtitles in every required language", len(files)))
+	} else {
+		format.PrintInfo(i18n.T("%d of %d file(s) lack a required language", incomplete, len(files)))
+	}
+	return nil
+}
+
+// collectMKVFiles lists the given files and the MKV files found in the given directories, sorted
+func collectMKVFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot access %s: %v", path, err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		found, err := util.FindMKVFilesInDirectory(path)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning directory %s: %v", path, err)
+		}
+		files = append(files, found...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// splitLanguages splits a comma-separated language list, dropping empty entries
+func splitLanguages(value string) []string {
+	var languages []string
+	for _, language := range strings.Split(value, ",") {
+		if language = strings.TrimSpace(language); language != "" {
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"subscalpelmkv/internal/config"
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/i18n"
+	"subscalpelmkv/internal/postprocess"
+)
+
+// HandleCleanupCommand runs the `cleanup [--dry-run] <file>...` subcommand, which removes advertising
+// and credit cues from existing SRT and ASS/SSA files
+func HandleCleanupCommand(args []string) error {
+	var paths []string
+	dryRun := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--dry-run" || arg == "-n":
+			dryRun = true
+		case arg == "--lang":
+			// Already applied by main before the command runs
+			i++
+		case strings.HasPrefix(arg, "--lang="):
+		case strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unknown cleanup option '%s'", arg)
+		default:
+			paths = append(paths, arg)
+		}
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("usage: subscalpelmkv cleanup [--dry-run] <file>...")
+	}
+
+	cfg, err := config.LoadConfig(config.FindConfigFile())
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %v", err)
+	}
+	rules := cfg.CleanupRules
+	if len(rules) == 0 {
+		rules = postprocess.DefaultCleanupRules
+	}
+
+	removed, err := postprocess.CleanupFiles(paths, rules, dryRun)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if dryRun {
+		format.PrintInfo(i18n.T("Dry run: %d cue(s) would be removed, no files were changed", removed))
+	} else {
+		format.PrintSuccess(i18n.T("Removed %d cue(s)", removed))
+	}
+	return nil
+}
@@ -2,33 +2,55 @@ package cli
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	"subscalpelmkv/internal/docs"
 	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/history"
+	"subscalpelmkv/internal/i18n"
 	"subscalpelmkv/internal/mkv"
 	"subscalpelmkv/internal/model"
 	"subscalpelmkv/internal/util"
 )
 
+// promptDefaults holds the configured answers of the interactive prompts
+var promptDefaults model.PromptDefaults
+
+// SetPromptDefaults sets the answers the interactive prompts take when enter is pressed
+func SetPromptDefaults(defaults model.PromptDefaults) {
+	promptDefaults = defaults
+}
+
 // AskUserConfirmation asks the user if they want to extract all tracks
 func AskUserConfirmation() bool {
 	reader := bufio.NewReader(os.Stdin)
 
+	// Default to yes unless the configuration says otherwise
+	defaultAnswer := promptDefaults.ExtractAll == nil || *promptDefaults.ExtractAll
+	prompt, placeholder := i18n.T("Extract all tracks? Y/n:"), i18n.T(" (press enter for yes)")
+	if !defaultAnswer {
+		prompt, placeholder = i18n.T("Extract all tracks? y/N:"), i18n.T(" (press enter for no)")
+	}
+
 	for {
-		format.PrintPromptWithPlaceholder("Extract all tracks? Y/n:", " (press enter for yes)")
+		format.PrintPromptWithPlaceholder(prompt, placeholder)
 		input, err := reader.ReadString('\n')
 		if err != nil {
-			format.PrintError(fmt.Sprintf("Error reading input: %v", err))
+			format.PrintError(i18n.T("Error reading input: %v", err))
 			continue
 		}
 
 		input = strings.TrimSpace(strings.ToLower(input))
 
-		// Default to yes if empty input
-		if input == "" || input == "y" || input == "yes" {
+		if input == "" {
+			return defaultAnswer
+		}
+		if input == "y" || input == "yes" {
 			return true
 		}
 
@@ -36,40 +58,84 @@ func AskUserConfirmation() bool {
 			return false
 		}
 
-		format.PrintWarning("Please enter 'Y' for yes or 'N' for no.")
+		format.PrintWarning(i18n.T("Please enter 'Y' for yes or 'N' for no."))
 	}
 }
 
-// AskTrackSelection asks the user to enter language codes, track numbers, and/or format filters for selective extraction
-func AskTrackSelection() string {
+// AskIncludeUntagged asks whether a track without a language tag should be extracted along with the
+// selected languages, for --und-policy prompt. It defaults to no, also when input cannot be read.
+func AskIncludeUntagged(track model.MKVTrack) bool {
 	reader := bufio.NewReader(os.Stdin)
 
-	format.PrintSubSection("Track Selection")
-	format.PrintInfo("Enter selection (comma-separated):")
-	format.PrintExample("Language: eng,spa,fre  •  Track ID: 14,16,18  •  Format: srt,ass,sup  •  Mixed: eng,14,srt")
-	format.PrintPromptWithPlaceholder("Selection:", " (press enter to accept all)")
+	label := i18n.T("Track %d has no language tag", track.Properties.Number)
+	if track.Properties.TrackName != "" {
+		label += " (" + track.Properties.TrackName + ")"
+	}
+	format.PrintInfo(label)
+	for {
+		format.PrintPromptWithPlaceholder(i18n.T("Extract it with the selected languages? y/N:"), i18n.T(" (press enter for no)"))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return false
+		}
+
+		input = strings.TrimSpace(strings.ToLower(input))
+		if input == "" || input == "n" || input == "no" {
+			return false
+		}
+		if input == "y" || input == "yes" {
+			return true
+		}
+
+		format.PrintWarning(i18n.T("Please enter 'Y' for yes or 'N' for no."))
+	}
+}
+
+// AskTrackSelection asks the user to enter language codes, track numbers, and/or format filters for selective extraction.
+// With withMenu, it also mentions the numbered track menu.
+func AskTrackSelection(withMenu bool) string {
+	reader := bufio.NewReader(os.Stdin)
+
+	format.PrintSubSection(i18n.T("Track Selection"))
+	format.PrintInfo(i18n.T("Enter selection (comma-separated):"))
+	format.PrintExample(i18n.T("Language: eng,spa,fre  •  Track ID: 14,16,18  •  Format: srt,ass,sup  •  Mixed: eng,14,srt"))
+	if withMenu {
+		format.PrintExample(i18n.T("Menu: enter %s to pick tracks from a numbered list", MenuKeyword))
+	}
+	if promptDefaults.Selection != "" {
+		format.PrintPromptWithPlaceholder(i18n.T("Selection:"), i18n.T(" (press enter for %s)", promptDefaults.Selection))
+	} else {
+		format.PrintPromptWithPlaceholder(i18n.T("Selection:"), i18n.T(" (press enter to accept all)"))
+	}
 
 	input, err := reader.ReadString('\n')
 	if err != nil {
-		format.PrintError(fmt.Sprintf("Error reading input: %v", err))
+		format.PrintError(i18n.T("Error reading input: %v", err))
 		return ""
 	}
 
-	return strings.TrimSpace(input)
+	if input = strings.TrimSpace(input); input == "" {
+		return promptDefaults.Selection
+	}
+	return input
 }
 
-// AskTrackExclusion asks the user to enter exclusion criteria for tracks to exclude
+// AskTrackExclusion asks the user to enter exclusion criteria for tracks to exclude. It asks nothing
+// when the configuration skips the exclusion prompt.
 func AskTrackExclusion() string {
+	if promptDefaults.SkipExclusions {
+		return ""
+	}
 	reader := bufio.NewReader(os.Stdin)
 
-	format.PrintSubSection("Track Exclusions (Optional)")
-	format.PrintInfo("Enter exclusions (comma-separated):")
-	format.PrintExample("Language: chi,kor  •  Track ID: 15,17  •  Format: sup,sub  •  Mixed: chi,15,sup")
-	format.PrintPromptWithPlaceholder("Exclusions:", " (press enter to skip)")
+	format.PrintSubSection(i18n.T("Track Exclusions (Optional)"))
+	format.PrintInfo(i18n.T("Enter exclusions (comma-separated):"))
+	format.PrintExample(i18n.T("Language: chi,kor  •  Track ID: 15,17  •  Format: sup,sub  •  Status: disabled  •  Mixed: chi,15,sup"))
+	format.PrintPromptWithPlaceholder(i18n.T("Exclusions:"), i18n.T(" (press enter to skip)"))
 
 	input, err := reader.ReadString('\n')
 	if err != nil {
-		format.PrintError(fmt.Sprintf("Error reading input: %v", err))
+		format.PrintError(i18n.T("Error reading input: %v", err))
 		return ""
 	}
 
@@ -91,195 +157,63 @@ func ParseLanguageCodes(input string) []string {
 			continue
 		}
 
-		isValid := false
-		if len(code) == 2 {
-			_, isValid = model.LanguageCodeMapping[strings.ToLower(code)]
-		} else if len(code) == 3 {
-			for _, threeLetter := range model.LanguageCodeMapping {
-				if strings.EqualFold(code, threeLetter) {
-					isValid = true
-					break
-				}
-			}
-		}
-
-		if isValid {
+		if model.IsLanguageCode(code) {
 			validCodes = append(validCodes, code)
 		} else {
-			format.PrintWarning(fmt.Sprintf("Unknown language code '%s' - skipping", code))
+			format.PrintWarning(i18n.T("Unknown language code '%s' - skipping", code))
 		}
 	}
 
 	return validCodes
 }
 
-// ParseTrackSelection parses comma-separated language codes, track numbers, and format filters
+// ParseTrackSelection parses comma-separated language codes, track numbers or ranges, and format filters.
+// Items with a leading ! are parsed into the selection's exclusions. Unknown items are skipped with a warning.
 func ParseTrackSelection(input string) model.TrackSelection {
-	selection := model.TrackSelection{
-		LanguageCodes: []string{},
-		TrackNumbers:  []int{},
-		FormatFilters: []string{},
-		Exclusions:    model.TrackExclusion{},
-	}
-
-	if input == "" {
-		return selection
-	}
-
-	items := strings.Split(input, ",")
-
-	for _, item := range items {
-		item = strings.TrimSpace(item)
-		if item == "" {
-			continue
-		}
-
-		// Try to parse as track number first
-		if trackNum, err := strconv.Atoi(item); err == nil {
-			selection.TrackNumbers = append(selection.TrackNumbers, trackNum)
-			continue
-		}
-
-		// Try to parse as language code
-		isValidLanguage := false
-		if len(item) == 2 {
-			_, isValidLanguage = model.LanguageCodeMapping[strings.ToLower(item)]
-		} else if len(item) == 3 {
-			for _, threeLetter := range model.LanguageCodeMapping {
-				if strings.EqualFold(item, threeLetter) {
-					isValidLanguage = true
-					break
-				}
-			}
-		}
-
-		if isValidLanguage {
-			selection.LanguageCodes = append(selection.LanguageCodes, item)
-			continue
-		}
-
-		// Try to parse as subtitle format filter
-		isValidFormat := false
-		lowerItem := strings.ToLower(item)
-		for _, ext := range model.SubtitleExtensionByCodec {
-			if lowerItem == ext {
-				isValidFormat = true
-				break
-			}
-		}
-
-		if isValidFormat {
-			selection.FormatFilters = append(selection.FormatFilters, lowerItem)
+	selection, unknownItems := model.ParseTrackSelection(input)
+	for _, item := range unknownItems {
+		if negated, found := strings.CutPrefix(item, "!"); found {
+			format.PrintWarning(i18n.T("Unknown exclusion language code, format, or invalid track ID '%s' - skipping", negated))
 		} else {
-			format.PrintWarning(fmt.Sprintf("Unknown language code, format, or invalid track ID '%s' - skipping", item))
+			format.PrintWarning(i18n.T("Unknown language code, format, or invalid track ID '%s' - skipping", item))
 		}
 	}
-
 	return selection
 }
 
-// ParseTrackExclusion parses comma-separated exclusion criteria (languages, track numbers, formats)
+// ParseTrackExclusion parses comma-separated exclusion criteria (languages, track numbers or ranges, formats).
+// Unknown items are skipped with a warning.
 func ParseTrackExclusion(input string) model.TrackExclusion {
-	exclusion := model.TrackExclusion{
-		LanguageCodes: []string{},
-		TrackNumbers:  []int{},
-		FormatFilters: []string{},
-	}
-
-	if input == "" {
-		return exclusion
+	exclusion, unknownItems := model.ParseTrackExclusion(input)
+	for _, item := range unknownItems {
+		format.PrintWarning(i18n.T("Unknown exclusion language code, format, or invalid track ID '%s' - skipping", item))
 	}
+	return exclusion
+}
 
-	items := strings.Split(input, ",")
-
-	for _, item := range items {
-		item = strings.TrimSpace(item)
-		if item == "" {
-			continue
-		}
-
-		// Try to parse as track number first
-		if trackNum, err := strconv.Atoi(item); err == nil {
-			exclusion.TrackNumbers = append(exclusion.TrackNumbers, trackNum)
-			continue
-		}
-
-		// Try to parse as language code
-		isValidLanguage := false
-		if len(item) == 2 {
-			_, isValidLanguage = model.LanguageCodeMapping[strings.ToLower(item)]
-		} else if len(item) == 3 {
-			for _, threeLetter := range model.LanguageCodeMapping {
-				if strings.EqualFold(item, threeLetter) {
-					isValidLanguage = true
-					break
-				}
-			}
-		}
+// helpOptionColumn is the width of the option column in --help, including the leading indent
+const helpOptionColumn = 29
 
-		if isValidLanguage {
-			exclusion.LanguageCodes = append(exclusion.LanguageCodes, item)
-			continue
-		}
+// ShowHelp displays the help message. Usage, options, commands and selectors come from the
+// same definitions as the generated man page, so the two cannot drift apart.
+func ShowHelp(options []docs.Option) {
+	var usage strings.Builder
+	for _, synopsis := range docs.Synopsis {
+		usage.WriteString("\n  subscalpelmkv " + synopsis)
+	}
+	format.PrintUsageSection(i18n.T("Usage"), strings.TrimPrefix(usage.String(), "\n"))
 
-		// Try to parse as subtitle format filter
-		isValidFormat := false
-		lowerItem := strings.ToLower(item)
-		for _, ext := range model.SubtitleExtensionByCodec {
-			if lowerItem == ext {
-				isValidFormat = true
-				break
-			}
-		}
+	format.PrintUsageSection(i18n.T("Selection Options"), helpOptionLines(options, docs.OptionGroupSelection))
+	format.PrintUsageSection(i18n.T("Selectors"), helpColumns(docs.Selectors()))
+	format.PrintUsageSection(i18n.T("Output Options"), helpOptionLines(options, docs.OptionGroupOutput))
 
-		if isValidFormat {
-			exclusion.FormatFilters = append(exclusion.FormatFilters, lowerItem)
-		} else {
-			format.PrintWarning(fmt.Sprintf("Unknown exclusion language code, format, or invalid track ID '%s' - skipping", item))
-		}
+	commands := make([][2]string, len(docs.Commands))
+	for i, command := range docs.Commands {
+		commands[i] = [2]string{command.Usage, command.Description}
 	}
+	format.PrintUsageSection(i18n.T("Commands"), helpColumns(commands))
 
-	return exclusion
-}
-
-// ShowHelp displays the help message
-func ShowHelp() {
-	format.PrintUsageSection("Usage", `  subscalpelmkv [OPTIONS] <file>
-  subscalpelmkv -x <file> [selection options] [output options]
-  subscalpelmkv -b <pattern> [selection options] [output options]
-  subscalpelmkv -i <file>`)
-
-	format.PrintUsageSection("Selection Options", `  -x, --extract <file>       Extract subtitles from MKV file
-	 -b, --batch <pattern>      Extract subtitles from multiple MKV files using glob pattern
-	                            (e.g., '*.mkv', 'Season 1/*.mkv', '/path/to/*.mkv')
-	 -i, --info <file>          Display subtitle track information
-	 -s, --select <selection>   Select subtitle tracks by language codes, track IDs,
-	                            and/or subtitle formats. Use comma-separated values.
-	                            Language codes: 2-letter (en,es) or 3-letter (eng,spa)
-	                            Track IDs: specific track IDs (14,16,18)
-	                            Subtitle formats: srt, ass, ssa, sup, sub, vtt, usf, etc.
-	                            Mixed: combine all types (e.g., 'eng,14,srt,sup')
-	                            If not specified, all subtitle tracks will be extracted
-	 -e, --exclude <exclusion>  Exclude subtitle tracks by language codes, track IDs,
-	                            and/or subtitle formats. Use comma-separated values.
-	                            Same format as --select. Exclusions are applied after
-	                            selections, allowing you to exclude specific tracks from
-	                            your selection (e.g., 'chi,15,sup')`)
-
-	format.PrintUsageSection("Output Options", `  -o, --output-dir [dir]     Output directory for extracted subtitle files
-                             (default: same directory as input file)
-                             If -o is used without a directory, creates {basename}-subtitles
-                             Output directory will be created if it doesn't exist
-  -f, --format <template>    Custom filename template with placeholders:
-                             {basename}, {language}, {trackno}, {trackname},
-                             {forced}, {default}, {extension}
-  -d, --dry-run              Show what would be extracted without performing extraction
-  -c, --config               Use default configuration profile
-  -p, --profile <name>       Use named configuration profile
-  -h, --help                 Show this help message
-  -v, --version              Show version information`)
-
-	format.PrintUsageSection("Examples", "")
+	format.PrintUsageSection(i18n.T("Examples"), "")
 	format.PrintExample("subscalpelmkv -i video.mkv")
 	format.PrintExample("subscalpelmkv -x video.mkv")
 	format.PrintExample("subscalpelmkv -x video.mkv -s eng")
@@ -291,6 +225,7 @@ func ShowHelp() {
 	format.PrintExample("subscalpelmkv -x video.mkv -e chi,kor")
 	format.PrintExample("subscalpelmkv -x video.mkv -s eng,spa -e sup")
 	format.PrintExample("subscalpelmkv -x video.mkv -e 15,17,sup")
+	format.PrintExample("subscalpelmkv -x video.mkv -e disabled")
 	format.PrintExample("subscalpelmkv -b \"*.mkv\" -s eng")
 	format.PrintExample("subscalpelmkv -b \"Season 1/*.mkv\" -s eng,spa")
 	format.PrintExample("subscalpelmkv -b \"/path/to/movies/*.mkv\" -o ./subtitles")
@@ -298,15 +233,16 @@ func ShowHelp() {
 	format.PrintExample("subscalpelmkv -x video.mkv -o")
 	format.PrintExample("subscalpelmkv -x video.mkv -f \"{basename}-{language}.{extension}\"")
 	format.PrintExample("subscalpelmkv -x video.mkv -s eng --dry-run")
+	format.PrintExample("subscalpelmkv -x video.mkv -s eng --post-hook \"ffsubsync {source} -i {output} --overwrite-input\"")
 	format.PrintExample("subscalpelmkv -x video.mkv --config")
 	format.PrintExample("subscalpelmkv -x video.mkv --profile anime")
 	format.PrintExample("subscalpelmkv video.mkv    (drag-and-drop mode)")
 
-	format.PrintUsageSection("Default filename template", `  {basename}.{language}.{trackno}.{trackname}.{forced}.{default}.{extension}`)
+	format.PrintUsageSection(i18n.T("Default filename template"), `  {basename}.{language}.{trackno}.{trackname}.{forced}.{default}.{extension}`)
 
-	format.PrintUsageSection("Language codes", `  Supports both 2-letter (en, es, fr) and 3-letter (eng, spa, fre) codes`)
+	format.PrintUsageSection(i18n.T("Language codes"), `  Supports both 2-letter (en, es, fr) and 3-letter (eng, spa, fre) codes`)
 
-	format.PrintUsageSection("Configuration", `  Config files are searched in this order:
+	format.PrintUsageSection(i18n.T("Configuration"), `  Config files are searched in this order:
   1. ./subscalpelmkv.yaml (current directory)
   2. ~/.config/subscalpelmkv/config.yaml (Linux/macOS)
      %APPDATA%\subscalpelmkv\config.yaml (Windows)
@@ -315,30 +251,75 @@ func ShowHelp() {
   CLI flags override config values. Use --config for default profile
   or --profile <name> for named profiles.`)
 
-	format.PrintUsageSection("Drag-and-drop mode", `  Simply drag an MKV file onto the executable for interactive mode
+	format.PrintUsageSection(i18n.T("Drag-and-drop mode"), `  Simply drag an MKV file onto the executable for interactive mode
   with track selection options.
 `)
 }
 
+// helpOptionLines renders the options of one group as the option and description columns of --help
+func helpOptionLines(options []docs.Option, group string) string {
+	var rows [][2]string
+	for _, option := range options {
+		if option.Group != group {
+			continue
+		}
+		name := "    --" + option.Long
+		if option.Short != "" {
+			name = "-" + option.Short + ", --" + option.Long
+		}
+		if option.Value != "" {
+			name += " " + option.Value
+		}
+		rows = append(rows, [2]string{name, option.Description})
+	}
+	return helpColumns(rows)
+}
+
+// helpColumns renders name/description rows, wrapping descriptions into the second column.
+// Names too long for the first column get their description on the following lines.
+func helpColumns(rows [][2]string) string {
+	indent := strings.Repeat(" ", helpOptionColumn)
+	var b strings.Builder
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		line := "  " + row[0]
+		if len(line) >= helpOptionColumn-1 {
+			b.WriteString(line + "\n" + indent)
+		} else {
+			b.WriteString(line + strings.Repeat(" ", helpOptionColumn-len(line)))
+		}
+		b.WriteString(strings.Join(docs.WrapText(row[1], 58), "\n"+indent))
+	}
+	return b.String()
+}
+
 // DisplaySubtitleTracks shows available subtitle tracks to the user
 func DisplaySubtitleTracks(mkvInfo *model.MKVInfo) {
-	format.PrintSection("Available Subtitle Tracks")
+	format.PrintSection(i18n.T("Available Subtitle Tracks"))
 
 	subtitleCount := 0
 	for i, track := range mkvInfo.Tracks {
 		if track.Type == "subtitles" {
 			subtitleCount++
 
-			codecType := "Unknown"
+			codecType := i18n.T("Unknown")
 			if ext, exists := model.SubtitleExtensionByCodec[track.Properties.CodecId]; exists {
 				codecType = strings.ToUpper(ext)
 			}
+			if role := roleLabel(track); role != "" {
+				codecType += " · " + role
+			}
 
 			// Get the full language name
 			languageName := model.GetLanguageName(track.Properties.Language)
+			if track.LanguageSubtags() != "" {
+				languageName = model.GetLocalizedLanguageName(track.LanguageTag(), "")
+			}
 
 			// For simple SUP tracks without attributes, we need to print codec on second line
-			if !track.Properties.Forced && !track.Properties.Default && codecType != "" {
+			if !track.Properties.Forced && !track.Properties.Default && track.Properties.Enabled && codecType != "" {
 				// Print track info without codec (it will be on second line)
 				format.PrintTrackInfoWithLanguageName(
 					track.Properties.Number,
@@ -348,12 +329,13 @@ func DisplaySubtitleTracks(mkvInfo *model.MKVInfo) {
 					"", // Empty codec - we'll print it separately
 					track.Properties.Forced,
 					track.Properties.Default,
+					!track.Properties.Enabled,
 				)
 				// Print codec on second line
 				format.BorderColor.Print("│   ")
 				format.CodecColor.Print(codecType)
-				// The visible length is 3 (for "   ") + len(codecType)
-				visibleLen := 3 + len(codecType)
+				// The visible length is 3 (for "   ") + the width of codecType
+				visibleLen := 3 + format.DisplayWidth(codecType)
 				padding := format.BoxWidth - visibleLen - 1 // -1 for space before closing border
 				if padding > 0 {
 					fmt.Print(strings.Repeat(" ", padding))
@@ -369,6 +351,7 @@ func DisplaySubtitleTracks(mkvInfo *model.MKVInfo) {
 					codecType,
 					track.Properties.Forced,
 					track.Properties.Default,
+					!track.Properties.Enabled,
 				)
 			}
 
@@ -390,9 +373,9 @@ func DisplaySubtitleTracks(mkvInfo *model.MKVInfo) {
 	}
 
 	if subtitleCount == 0 {
-		noTracksMsg := "No subtitle tracks found in this file."
-		visibleLen := 2 + len(noTracksMsg)          // "│ " + message
-		padding := format.BoxWidth - visibleLen - 1 // -1 for space before closing border
+		noTracksMsg := i18n.T("No subtitle tracks found in this file.")
+		visibleLen := 2 + format.DisplayWidth(noTracksMsg) // "│ " + message
+		padding := format.BoxWidth - visibleLen - 1        // -1 for space before closing border
 		format.BorderColor.Print("│ ")
 		format.WarningColor.Print(noTracksMsg)
 		if padding > 0 {
@@ -424,25 +407,25 @@ func DisplaySubtitleTracks(mkvInfo *model.MKVInfo) {
 		}
 
 		// Display summary
-		trackWord := "tracks"
+		trackWord := i18n.T("tracks")
 		if subtitleCount == 1 {
-			trackWord = "track"
+			trackWord = i18n.T("track")
 		}
 
-		languageWord := "languages"
+		languageWord := i18n.T("languages")
 		if len(languageSet) == 1 {
-			languageWord = "language"
+			languageWord = i18n.T("language")
 		}
 
-		formatWord := "formats"
+		formatWord := i18n.T("formats")
 		if len(formatSet) == 1 {
-			formatWord = "format"
+			formatWord = i18n.T("format")
 		}
 
-		summaryMsg := fmt.Sprintf("%d total %s, %d %s, %d %s",
+		summaryMsg := i18n.T("%d total %s, %d %s, %d %s",
 			subtitleCount, trackWord, len(languageSet), languageWord, len(formatSet), formatWord)
-		visibleLen := 2 + len(summaryMsg)       // "│ " + message
-		padding := format.BoxWidth - visibleLen // No -1 needed for proper alignment
+		visibleLen := 2 + format.DisplayWidth(summaryMsg) // "│ " + message
+		padding := format.BoxWidth - visibleLen           // No -1 needed for proper alignment
 		format.BorderColor.Print("│ ")
 		format.InfoColor.Print(summaryMsg)
 		if padding > 0 {
@@ -457,8 +440,8 @@ func DisplaySubtitleTracks(mkvInfo *model.MKVInfo) {
 // HandleDragAndDropMode handles the interactive drag-and-drop mode (backward compatibility)
 func HandleDragAndDropMode(inputFileName string, processFileFunc func(string, string, bool) error) error {
 	// Create a wrapper function that adds default output config
-	wrapperFunc := func(inputFileName, languageFilter, exclusionFilter string, showFilterMessage bool, outputConfig model.OutputConfig, dryRun bool) error {
-		return processFileFunc(inputFileName, languageFilter, showFilterMessage)
+	wrapperFunc := func(ctx context.Context, inputFileName, languageFilter, exclusionFilter string, showFilterMessage bool, outputConfig model.OutputConfig, dryRun bool) (model.FileResult, error) {
+		return model.FileResult{}, processFileFunc(inputFileName, languageFilter, showFilterMessage)
 	}
 
 	defaultOutputConfig := model.OutputConfig{
@@ -467,20 +450,38 @@ func HandleDragAndDropMode(inputFileName string, processFileFunc func(string, st
 		CreateDir: false,
 	}
 
-	return HandleDragAndDropModeWithConfig(inputFileName, wrapperFunc, defaultOutputConfig)
+	return HandleDragAndDropModeWithConfig(context.Background(), inputFileName, wrapperFunc, defaultOutputConfig)
+}
+
+// HandleDragAndDropModeWithConfig handles the interactive drag-and-drop mode with output configuration.
+// After a successful extraction it offers another selection from the same file or another file in
+// its directory, so a wrong selection can be corrected without starting over.
+func HandleDragAndDropModeWithConfig(ctx context.Context, inputFileName string, processFileFunc func(context.Context, string, string, string, bool, model.OutputConfig, bool) (model.FileResult, error), outputConfig model.OutputConfig) error {
+	for {
+		extracted, err := extractInteractively(ctx, inputFileName, processFileFunc, outputConfig)
+		if err != nil || !extracted {
+			fmt.Println(i18n.T("Press enter to exit..."))
+			fmt.Scanln()
+			return err
+		}
+
+		if inputFileName = AskExtractMore(inputFileName); inputFileName == "" {
+			return nil
+		}
+		fmt.Println()
+	}
 }
 
-// HandleDragAndDropModeWithConfig handles the interactive drag-and-drop mode with output configuration
-func HandleDragAndDropModeWithConfig(inputFileName string, processFileFunc func(string, string, string, bool, model.OutputConfig, bool) error, outputConfig model.OutputConfig) error {
-	format.PrintInfo(fmt.Sprintf("Processing file: %s", inputFileName))
+// extractInteractively shows the subtitle tracks of a file, asks for a selection and extracts it.
+// It reports whether an extraction ran.
+func extractInteractively(ctx context.Context, inputFileName string, processFileFunc func(context.Context, string, string, string, bool, model.OutputConfig, bool) (model.FileResult, error), outputConfig model.OutputConfig) (bool, error) {
+	format.PrintInfo(i18n.T("Processing file: %s", inputFileName))
 
 	// Get track information to show available subtitle tracks
-	mkvInfo, err := mkv.GetTrackInfo(inputFileName)
+	mkvInfo, err := mkv.GetTrackInfo(ctx, inputFileName)
 	if err != nil {
-		format.PrintError(fmt.Sprintf("Error: %v", err))
-		fmt.Println("Press enter to exit...")
-		fmt.Scanln()
-		return err
+		format.PrintError(i18n.T("Error: %v", err))
+		return false, err
 	}
 
 	DisplaySubtitleTracks(mkvInfo)
@@ -494,28 +495,32 @@ func HandleDragAndDropModeWithConfig(inputFileName string, processFileFunc func(
 	}
 
 	if !hasSubtitles {
-		format.PrintWarning("No subtitle tracks found in this file.")
-		fmt.Println("Press enter to exit...")
-		fmt.Scanln()
-		return nil
+		format.PrintWarning(i18n.T("No subtitle tracks found in this file."))
+		return false, nil
 	}
 
-	extractAll := AskUserConfirmation()
+	// Offer the selection last used in this directory before asking from scratch
+	var selectionResult *SelectionResult
+	if last, found := history.LastSelection(inputFileName); found && AskUseLastSelection(last) {
+		selectionResult = ProcessSelectionForBatch(ParseTrackSelection(last.Selection), ParseTrackExclusion(last.Exclusion))
+	} else {
+		extractAll := AskUserConfirmation()
 
-	// Extract available subtitle track numbers for validation
-	var availableTracks []int
-	for _, track := range mkvInfo.Tracks {
-		if track.Type == "subtitles" {
-			availableTracks = append(availableTracks, track.Properties.Number)
+		// Extract available subtitle tracks for validation and the numbered menu
+		var availableTracks []int
+		var subtitleTracks []model.MKVTrack
+		for _, track := range mkvInfo.Tracks {
+			if track.Type == "subtitles" {
+				availableTracks = append(availableTracks, track.Properties.Number)
+				subtitleTracks = append(subtitleTracks, track)
+			}
 		}
-	}
 
-	// Use the shared function for processing selection and exclusion
-	selectionResult, err := ProcessSelectionAndExclusion(extractAll, availableTracks)
-	if err != nil {
-		fmt.Println("Press enter to exit...")
-		fmt.Scanln()
-		return nil
+		// Use the shared function for processing selection and exclusion
+		selectionResult, err = ProcessSelectionAndExclusion(extractAll, availableTracks, subtitleTracks)
+		if err != nil {
+			return false, nil
+		}
 	}
 
 	if selectionResult.Message != "" {
@@ -523,17 +528,115 @@ func HandleDragAndDropModeWithConfig(inputFileName string, processFileFunc func(
 		format.PrintInfo(selectionResult.Message)
 	}
 
-	err = processFileFunc(inputFileName, selectionResult.LanguageFilter, selectionResult.ExclusionFilter, false, outputConfig, false)
+	_, err = processFileFunc(ctx, inputFileName, selectionResult.LanguageFilter, selectionResult.ExclusionFilter, false, outputConfig, false)
 	if err != nil {
-		format.PrintError(fmt.Sprintf("Error: %v", err))
-		fmt.Println("Press enter to exit...")
-		fmt.Scanln()
-		return err
+		format.PrintError(i18n.T("Error: %v", err))
+		return false, err
 	}
 
-	fmt.Println("Press enter to exit...")
-	fmt.Scanln()
-	return nil
+	if err := history.RememberSelection(inputFileName, selectionResult.LanguageFilter, selectionResult.ExclusionFilter); err != nil {
+		format.PrintWarning(i18n.T("Could not remember the selection: %v", err))
+	}
+	return true, nil
+}
+
+// AskUseLastSelection shows the selection last used in the directory and asks whether to use it again
+func AskUseLastSelection(last history.Selection) bool {
+	reader := bufio.NewReader(os.Stdin)
+
+	description := ProcessSelectionForBatch(ParseTrackSelection(last.Selection), ParseTrackExclusion(last.Exclusion)).Message
+	if description == "" {
+		description = i18n.T("Extracting all subtitle tracks")
+	}
+	format.PrintSubSection(i18n.T("Last Selection"))
+	format.PrintInfo(description)
+
+	for {
+		format.PrintPromptWithPlaceholder(i18n.T("Use the last selection for this folder? Y/n:"), i18n.T(" (press enter for yes)"))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return false
+		}
+
+		input = strings.TrimSpace(strings.ToLower(input))
+		if input == "" || input == "y" || input == "yes" {
+			return true
+		}
+		if input == "n" || input == "no" {
+			return false
+		}
+
+		format.PrintWarning(i18n.T("Please enter 'Y' for yes or 'N' for no."))
+	}
+}
+
+// AskExtractMore asks whether to extract more after an interactive extraction. It returns the file
+// to continue with: the same file, another MKV file from its directory, or "" to exit.
+func AskExtractMore(inputFileName string) string {
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		fmt.Println()
+		format.PrintPromptWithPlaceholder(i18n.T("Extract more? [s]ame file, [o]ther file:"), i18n.T(" (press enter to exit)"))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return ""
+		}
+
+		switch strings.TrimSpace(strings.ToLower(input)) {
+		case "":
+			return ""
+		case "s", "same":
+			return inputFileName
+		case "o", "other":
+			if next := askSiblingFile(reader, inputFileName); next != "" {
+				return next
+			}
+		default:
+			format.PrintWarning(i18n.T("Please enter 'S' for the same file, 'O' for another file, or press enter to exit."))
+		}
+	}
+}
+
+// askSiblingFile lets the user pick an MKV file from the directory of inputFileName by number. It
+// returns "" when the directory has no MKV files or the user goes back.
+func askSiblingFile(reader *bufio.Reader, inputFileName string) string {
+	dir := filepath.Dir(inputFileName)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		format.PrintError(i18n.T("Error: %v", err))
+		return ""
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && util.IsMKVFile(entry.Name()) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	if len(files) == 0 {
+		format.PrintWarning(i18n.T("No MKV files found in %s", dir))
+		return ""
+	}
+
+	format.PrintSubSection(i18n.T("Files in %s", dir))
+	for i, file := range files {
+		format.PrintExample(fmt.Sprintf("%2d  %s", i+1, filepath.Base(file)))
+	}
+	for {
+		format.PrintPromptWithPlaceholder(i18n.T("File number:"), i18n.T(" (press enter to go back)"))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return ""
+		}
+		input = strings.TrimSpace(input)
+		if input == "" {
+			return ""
+		}
+		if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(files) {
+			return files[n-1]
+		}
+		format.PrintWarning(i18n.T("Please enter a number from 1 to %d.", len(files)))
+	}
 }
 
 // BuildSelectionFilter builds a selection filter from command line arguments
@@ -542,42 +645,55 @@ func BuildSelectionFilter(input string) string {
 }
 
 // ShowFileInfo displays subtitle track information for a file without extracting
-func ShowFileInfo(inputFileName string) error {
+func ShowFileInfo(ctx context.Context, inputFileName string) error {
+	mkvInfo, err := analyzeFile(ctx, inputFileName)
+	if err != nil {
+		return err
+	}
+
+	DisplaySubtitleTracks(mkvInfo)
+
+	return nil
+}
+
+// analyzeFile reads the track information of an MKV file, reporting why a file cannot be read
+func analyzeFile(ctx context.Context, inputFileName string) (*model.MKVInfo, error) {
 	if ifs, statErr := os.Stat(inputFileName); os.IsNotExist(statErr) || ifs.IsDir() {
-		format.PrintError(fmt.Sprintf("File does not exist or is a directory: %s", inputFileName))
-		return statErr
+		format.PrintError(i18n.T("File does not exist or is a directory: %s", inputFileName))
+		if statErr == nil {
+			statErr = fmt.Errorf("%s is a directory", inputFileName)
+		}
+		return nil, statErr
 	}
 
 	if !util.IsMKVFile(inputFileName) {
-		format.PrintError(fmt.Sprintf("File is not an MKV file: %s", inputFileName))
-		return fmt.Errorf("file is not an MKV file")
+		format.PrintError(i18n.T("File is not an MKV or WebM file: %s", inputFileName))
+		return nil, fmt.Errorf("file is not an MKV or WebM file")
 	}
 
-	mkvInfo, err := mkv.GetTrackInfo(inputFileName)
+	mkvInfo, err := mkv.GetTrackInfo(ctx, inputFileName)
 	if err != nil {
-		format.PrintError(fmt.Sprintf("Error analyzing file: %v", err))
-		return err
+		format.PrintError(i18n.T("Error analyzing file: %v", err))
+		return nil, err
 	}
-
-	DisplaySubtitleTracks(mkvInfo)
-
-	return nil
+	return mkvInfo, nil
 }
 
 // DisplayBatchFiles shows batch file information to the user in the same visual style as subtitle tracks
 func DisplayBatchFiles(batchFiles []model.BatchFileInfo) {
-	format.PrintSection("Files to Process")
+	format.PrintSection(i18n.T("Files to Process"))
 
 	// Use expanded view as default for batch mode
 	for i, fileInfo := range batchFiles {
 		if fileInfo.HasError {
 			// Display error files differently
+			fileName := format.Truncate(fileInfo.FileName, format.BoxWidth-4)
 			format.BorderColor.Print("│ ")
 			format.ErrorColor.Print("✗")
 			fmt.Print(" ")
-			format.BaseFg.Print(fileInfo.FileName)
+			format.BaseFg.Print(fileName)
 
-			contentLen := 2 + 2 + len(fileInfo.FileName) // "│ " + "✗ " + filename
+			contentLen := 2 + 2 + format.DisplayWidth(fileName) // "│ " + "✗ " + filename
 			padding := format.BoxWidth - contentLen
 			if padding > 0 {
 				fmt.Print(strings.Repeat(" ", padding))
@@ -585,9 +701,10 @@ func DisplayBatchFiles(batchFiles []model.BatchFileInfo) {
 			format.BorderColor.Println(" │")
 
 			// Error message on second line
+			errorMessage := format.Truncate(fileInfo.ErrorMessage, format.BoxWidth-4)
 			format.BorderColor.Print("│   ")
-			format.ErrorColor.Print(fileInfo.ErrorMessage)
-			errorLen := 3 + len(fileInfo.ErrorMessage) // "│   " + error
+			format.ErrorColor.Print(errorMessage)
+			errorLen := 3 + format.DisplayWidth(errorMessage) // "│   " + error
 			errorPadding := format.BoxWidth - errorLen - 1
 			if errorPadding > 0 {
 				fmt.Print(strings.Repeat(" ", errorPadding))
@@ -595,12 +712,13 @@ func DisplayBatchFiles(batchFiles []model.BatchFileInfo) {
 			format.BorderColor.Println(" │")
 		} else {
 			// Display normal files
+			fileName := format.Truncate(fileInfo.FileName, format.BoxWidth-4)
 			format.BorderColor.Print("│ ")
 			format.BaseHighlight.Print("▪")
 			fmt.Print(" ")
-			format.BaseFg.Print(fileInfo.FileName)
+			format.BaseFg.Print(fileName)
 
-			contentLen := 2 + 2 + len(fileInfo.FileName) // "│ " + "▪ " + filename
+			contentLen := 2 + 2 + format.DisplayWidth(fileName) // "│ " + "▪ " + filename
 			padding := format.BoxWidth - contentLen
 			if padding > 0 {
 				fmt.Print(strings.Repeat(" ", padding))
@@ -645,36 +763,36 @@ func DisplayBatchFiles(batchFiles []model.BatchFileInfo) {
 	}
 
 	// Display summary
-	fileWord := "files"
+	fileWord := i18n.T("files")
 	if validFiles == 1 {
-		fileWord = "file"
+		fileWord = i18n.T("file")
 	}
 
-	trackWord := "tracks"
+	trackWord := i18n.T("tracks")
 	if totalTracks == 1 {
-		trackWord = "track"
+		trackWord = i18n.T("track")
 	}
 
-	languageWord := "languages"
+	languageWord := i18n.T("languages")
 	if len(languageSet) == 1 {
-		languageWord = "language"
+		languageWord = i18n.T("language")
 	}
 
-	formatWord := "formats"
+	formatWord := i18n.T("formats")
 	if len(formatSet) == 1 {
-		formatWord = "format"
+		formatWord = i18n.T("format")
 	}
 
 	var summaryMsg string
 	if errorFiles > 0 {
-		summaryMsg = fmt.Sprintf("%d valid %s, %d total %s, %d %s, %d %s • %d errors",
+		summaryMsg = i18n.T("%d valid %s, %d total %s, %d %s, %d %s • %d errors",
 			validFiles, fileWord, totalTracks, trackWord, len(languageSet), languageWord, len(formatSet), formatWord, errorFiles)
 	} else {
-		summaryMsg = fmt.Sprintf("%d %s, %d total %s, %d %s, %d %s",
+		summaryMsg = i18n.T("%d %s, %d total %s, %d %s, %d %s",
 			validFiles, fileWord, totalTracks, trackWord, len(languageSet), languageWord, len(formatSet), formatWord)
 	}
 
-	visibleLen := 2 + len(summaryMsg) // "│ " + message
+	visibleLen := 2 + format.DisplayWidth(summaryMsg) // "│ " + message
 	padding := format.BoxWidth - visibleLen
 	format.BorderColor.Print("│ ")
 	format.InfoColor.Print(summaryMsg)
@@ -690,9 +808,9 @@ func DisplayBatchFiles(batchFiles []model.BatchFileInfo) {
 func displayExpandedFileDetails(fileInfo model.BatchFileInfo) {
 	// Track count line
 	format.BorderColor.Print("│   ")
-	trackText := fmt.Sprintf("Tracks: %d", fileInfo.SubtitleCount)
+	trackText := i18n.T("Tracks: %d", fileInfo.SubtitleCount)
 	format.InfoColor.Print(trackText)
-	trackLen := 3 + len(trackText)
+	trackLen := 3 + format.DisplayWidth(trackText)
 	trackPadding := format.BoxWidth - trackLen - 1
 	if trackPadding > 0 {
 		fmt.Print(strings.Repeat(" ", trackPadding))
@@ -706,8 +824,8 @@ func displayExpandedFileDetails(fileInfo model.BatchFileInfo) {
 		suffixLen := 2 // " │"
 		availableWidth := format.BoxWidth - prefixLen - suffixLen
 
-		langLabel := "Languages: "
-		langLabelLen := len(langLabel)
+		langLabel := i18n.T("Languages: ")
+		langLabelLen := format.DisplayWidth(langLabel)
 
 		// Join all languages
 		allLangs := strings.Join(fileInfo.LanguageCodes, ", ")
@@ -813,8 +931,8 @@ func displayExpandedFileDetails(fileInfo model.BatchFileInfo) {
 		suffixLen := 2 // " │"
 		availableWidth := format.BoxWidth - prefixLen - suffixLen
 
-		formatLabel := "Formats: "
-		formatLabelLen := len(formatLabel)
+		formatLabel := i18n.T("Formats: ")
+		formatLabelLen := format.DisplayWidth(formatLabel)
 
 		// Join all formats
 		allFormats := strings.Join(fileInfo.SubtitleFormats, ", ")
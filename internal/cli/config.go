@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"subscalpelmkv/internal/config"
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/i18n"
+	"subscalpelmkv/internal/mkv"
+)
+
+// HandleConfigCommand runs the `config validate [file]` subcommand
+func HandleConfigCommand(args []string) error {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--lang":
+			// Already applied by main before the command runs
+			i++
+		case strings.HasPrefix(arg, "--lang="):
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if len(rest) == 0 || rest[0] != "validate" || len(rest) > 2 {
+		return fmt.Errorf("usage: subscalpelmkv config validate [file]")
+	}
+
+	configPath := ""
+	if len(rest) == 2 {
+		configPath = rest[1]
+	}
+	return ValidateConfiguration(configPath)
+}
+
+// ValidateConfiguration checks a config file (the one in use when configPath is empty) and the
+// MKVToolNix installation, printing each problem with its line number
+func ValidateConfiguration(configPath string) error {
+	format.PrintSubSection(i18n.T("Configuration Check"))
+	fmt.Println()
+
+	failures := 0
+	if configPath == "" {
+		configPath = config.FindConfigFile()
+	}
+	if configPath == "" {
+		format.PrintInfo(i18n.T("No configuration file found, built-in defaults are used. Searched:"))
+		for _, location := range config.GetConfigLocations() {
+			format.PrintExample("    " + location)
+		}
+	} else {
+		problems, err := config.ValidateFile(configPath)
+		if err != nil {
+			return err
+		}
+		for _, problem := range problems {
+			if problem.Line > 0 {
+				format.PrintError(fmt.Sprintf("%s:%d: %s", configPath, problem.Line, problem.Message))
+			} else {
+				format.PrintError(fmt.Sprintf("%s: %s", configPath, problem.Message))
+			}
+		}
+		if len(problems) == 0 {
+			format.PrintSuccess(i18n.T("%s is valid", configPath))
+		}
+		failures += len(problems)
+	}
+
+	for _, tool := range mkv.RequiredToolNames {
+		toolVersion, err := mkv.ToolVersion(context.Background(), tool)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("%s: %v", tool, err))
+			failures++
+			continue
+		}
+		format.PrintSuccess(toolVersion)
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("found %d problem(s)", failures)
+	}
+	return nil
+}
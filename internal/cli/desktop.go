@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"subscalpelmkv/internal/desktop"
+	"subscalpelmkv/internal/format"
+)
+
+// HandleInstallQuickActionCommand runs the `install-quick-action [--uninstall]` subcommand, which adds
+// a Finder Quick Action opening the selected MKV files in Terminal on macOS
+func HandleInstallQuickActionCommand(args []string) error {
+	uninstall, err := parseInstallArgs(args, "install-quick-action")
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS != "darwin" {
+		return fmt.Errorf("Finder Quick Actions are only available on macOS")
+	}
+
+	servicesDir, err := desktop.ServicesDir()
+	if err != nil {
+		return err
+	}
+	if uninstall {
+		removed, err := desktop.UninstallQuickAction(servicesDir)
+		if err != nil {
+			return err
+		}
+		if removed {
+			format.PrintSuccess("Removed the Finder Quick Action")
+		} else {
+			format.PrintInfo("The Finder Quick Action is not installed")
+		}
+		return nil
+	}
+
+	executable, err := executablePath()
+	if err != nil {
+		return err
+	}
+	workflowPath, err := desktop.InstallQuickAction(servicesDir, executable)
+	if err != nil {
+		return err
+	}
+	format.PrintSuccess(fmt.Sprintf("Installed %s", workflowPath))
+	format.PrintInfo(fmt.Sprintf("Right-click MKV files or folders in Finder and choose Quick Actions > %s", desktop.QuickActionName))
+	format.PrintInfo(fmt.Sprintf("The Quick Action runs %s; install it again after moving the program", executable))
+	return nil
+}
+
+// HandleInstallDesktopCommand runs the `install-desktop [--uninstall]` subcommand, which adds a desktop
+// entry and Nautilus and Dolphin actions opening MKV files in a terminal on Linux
+func HandleInstallDesktopCommand(args []string) error {
+	uninstall, err := parseInstallArgs(args, "install-desktop")
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return fmt.Errorf("desktop integration is only available on Linux and other freedesktop.org systems (use install-quick-action on macOS)")
+	}
+
+	dataDir, err := desktop.DataDir()
+	if err != nil {
+		return err
+	}
+	if uninstall {
+		removed, err := desktop.UninstallDesktopIntegration(dataDir)
+		for _, path := range removed {
+			format.PrintSuccess(fmt.Sprintf("Removed %s", path))
+		}
+		if err != nil {
+			return err
+		}
+		if len(removed) == 0 {
+			format.PrintInfo("The desktop integration is not installed")
+		}
+		return nil
+	}
+
+	executable, err := executablePath()
+	if err != nil {
+		return err
+	}
+	written, err := desktop.InstallDesktopIntegration(dataDir, executable)
+	for _, path := range written {
+		format.PrintSuccess(fmt.Sprintf("Installed %s", path))
+	}
+	if err != nil {
+		return err
+	}
+	format.PrintInfo("Open MKV files with SubScalpelMKV from the file manager's Open With menu, Nautilus' Scripts menu or Dolphin's context menu")
+	format.PrintInfo(fmt.Sprintf("The entries run %s; install them again after moving the program", executable))
+	return nil
+}
+
+// parseInstallArgs parses the arguments of the install commands, reporting whether --uninstall is set
+func parseInstallArgs(args []string, command string) (bool, error) {
+	uninstall := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--uninstall":
+			uninstall = true
+		case arg == "--lang":
+			// Already applied by main before the command runs
+			i++
+		case strings.HasPrefix(arg, "--lang="):
+		default:
+			return false, fmt.Errorf("usage: subscalpelmkv %s [--uninstall]", command)
+		}
+	}
+	return uninstall, nil
+}
+
+// executablePath returns the absolute path of the running program with symlinks resolved, for
+// launchers that start it again
+func executablePath() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the program: %v", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	return executable, nil
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"fmt"
+
+	"subscalpelmkv/internal/docs"
+)
+
+// HandleDocsCommand runs the `docs --man|--markdown` subcommand, printing documentation generated
+// from the option definitions to stdout
+func HandleDocsCommand(args []string, options []docs.Option, version string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: subscalpelmkv docs --man|--markdown")
+	}
+
+	switch args[0] {
+	case "--man":
+		fmt.Print(docs.Man(options, version))
+	case "--markdown":
+		fmt.Print(docs.Markdown(options, version))
+	default:
+		return fmt.Errorf("unknown docs option '%s' (use --man or --markdown)", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/history"
+	"subscalpelmkv/internal/i18n"
+)
+
+// HandleHistoryCommand runs the `history [--since <value>] [--prune <value>]` subcommand
+func HandleHistoryCommand(args []string) error {
+	var since, prune string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--since":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--since requires a value")
+			}
+			since = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--since="):
+			since = strings.TrimPrefix(arg, "--since=")
+		case arg == "--prune":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--prune requires a value")
+			}
+			prune = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--prune="):
+			prune = strings.TrimPrefix(arg, "--prune=")
+		case arg == "--lang":
+			// Already applied by main before the command runs
+			i++
+		case strings.HasPrefix(arg, "--lang="):
+		default:
+			return fmt.Errorf("unknown history option '%s'", arg)
+		}
+	}
+
+	if prune != "" {
+		if since != "" {
+			return fmt.Errorf("--since and --prune cannot be used together")
+		}
+		return PruneHistory(prune)
+	}
+	return ShowHistory(since)
+}
+
+// PruneHistory removes the entries processed before a date or duration from the history
+func PruneHistory(before string) error {
+	beforeTime, err := history.ParseSince(before, time.Now())
+	if err != nil {
+		return err
+	}
+	removed, err := history.Prune(beforeTime)
+	if err != nil {
+		return err
+	}
+	format.PrintSuccess(i18n.T("Removed %d history entries processed before %s", removed, beforeTime.Format("2006-01-02 15:04")))
+	return nil
+}
+
+// ShowHistory lists the processed files recorded in the history, optionally limited to a time window
+func ShowHistory(since string) error {
+	entries, err := history.Load()
+	if err != nil {
+		return err
+	}
+
+	title := i18n.T("Processing History")
+	if since != "" {
+		sinceTime, err := history.ParseSince(since, time.Now())
+		if err != nil {
+			return err
+		}
+		entries = history.Since(entries, sinceTime)
+		title = i18n.T("Processing History since %s", sinceTime.Format("2006-01-02 15:04"))
+	}
+
+	format.PrintSubSection(title)
+	fmt.Println()
+	if len(entries) == 0 {
+		format.PrintInfo(i18n.T("No processed files recorded"))
+		return nil
+	}
+
+	for _, entry := range entries {
+		trackStrs := make([]string, len(entry.Tracks))
+		for i, t := range entry.Tracks {
+			trackStrs[i] = strconv.Itoa(t)
+		}
+
+		fmt.Println()
+		format.BaseDim.Print("  " + entry.ProcessedAt.Local().Format("2006-01-02 15:04") + "  ")
+		format.BaseFg.Println(entry.Path)
+		format.BaseDim.Println("    " + i18n.T("tracks: %s", strings.Join(trackStrs, ", ")))
+		for _, output := range entry.Outputs {
+			format.PrintExample(fmt.Sprintf("  → %s", output))
+		}
+	}
+
+	fmt.Println()
+	format.PrintInfo(i18n.T("%d file(s) • history file: %s", len(entries), history.DefaultPath()))
+	return nil
+}
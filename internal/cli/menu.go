@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/i18n"
+	"subscalpelmkv/internal/model"
+)
+
+// MenuKeyword opens the numbered track menu from the selection prompt
+const MenuKeyword = "m"
+
+// AskTrackMenu shows the subtitle tracks as a numbered list in which entering menu numbers such as
+// "1 3 5" or "1-4" toggles tracks, "all" and "none" select all or none, and enter confirms. It
+// returns the track numbers of the chosen tracks, or nil if none were chosen.
+func AskTrackMenu(tracks []model.MKVTrack) []int {
+	reader := bufio.NewReader(os.Stdin)
+	selected := make([]bool, len(tracks))
+
+	format.PrintSubSection(i18n.T("Track Menu"))
+	format.PrintExample(i18n.T("Toggle: 1 3 5  •  Range: 1-4  •  all  •  none  •  Press enter to confirm"))
+	for {
+		fmt.Println()
+		for i, track := range tracks {
+			mark := "[ ]"
+			if selected[i] {
+				mark = "[x]"
+			}
+			format.PrintExample(fmt.Sprintf("%2d  %s %s", i+1, mark, menuTrackLabel(track)))
+		}
+
+		format.PrintPromptWithPlaceholder(i18n.T("Toggle:"), i18n.T(" (press enter to confirm)"))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return nil
+		}
+		input = strings.TrimSpace(strings.ToLower(input))
+
+		switch input {
+		case "":
+			var trackNums []int
+			for i, track := range tracks {
+				if selected[i] {
+					trackNums = append(trackNums, track.Properties.Number)
+				}
+			}
+			return trackNums
+		case "all", "none":
+			for i := range selected {
+				selected[i] = input == "all"
+			}
+			continue
+		}
+
+		for _, item := range strings.FieldsFunc(input, func(r rune) bool { return r == ' ' || r == ',' }) {
+			positions, ok := model.ParseTrackRange(item)
+			if !ok {
+				position, err := strconv.Atoi(item)
+				if err != nil {
+					format.PrintWarning(i18n.T("Not a menu number or range: '%s'", item))
+					continue
+				}
+				positions = []int{position}
+			}
+			if slices.ContainsFunc(positions, func(position int) bool { return position < 1 || position > len(tracks) }) {
+				format.PrintWarning(i18n.T("Please enter numbers from 1 to %d.", len(tracks)))
+				continue
+			}
+			for _, position := range positions {
+				selected[position-1] = !selected[position-1]
+			}
+		}
+	}
+}
+
+// roleLabel names the role ClassifyRoles found for a track, or returns an empty string
+func roleLabel(track model.MKVTrack) string {
+	switch track.Properties.Role {
+	case model.TrackRoleDialogue:
+		return i18n.T("Full Dialogue")
+	case model.TrackRoleSigns:
+		return i18n.T("Signs & Songs")
+	}
+	return ""
+}
+
+// menuTrackLabel describes a track in the menu, e.g. "Track 3 (eng) - Full [SRT, forced]"
+func menuTrackLabel(track model.MKVTrack) string {
+	codecType := i18n.T("Unknown")
+	if ext, exists := model.SubtitleExtensionByCodec[track.Properties.CodecId]; exists {
+		codecType = strings.ToUpper(ext)
+	}
+	label := fmt.Sprintf("%s %d (%s)", i18n.T("Track"), track.Properties.Number, track.Properties.Language)
+	if track.Properties.TrackName != "" {
+		label += " - " + track.Properties.TrackName
+	}
+	attributes := []string{codecType}
+	if track.Properties.Forced {
+		attributes = append(attributes, "forced")
+	}
+	if track.Properties.Default {
+		attributes = append(attributes, "default")
+	}
+	if role := roleLabel(track); role != "" {
+		attributes = append(attributes, role)
+	}
+	return fmt.Sprintf("%s [%s]", label, strings.Join(attributes, ", "))
+}
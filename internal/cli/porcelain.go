@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"subscalpelmkv/internal/model"
+)
+
+// Porcelain output is one tab-separated record per line, starting with the record type and the
+// source file. Records and their fields only ever get added at the end, so scripts keep working
+// across versions:
+//
+//	track    <source> <number> <language> <format> <attributes> <name>
+//	output   <source> <number> <language> <format> <bytes> <path>
+//	planned  <source> <number> <language> <format> <path>
+//	file     <source> <status> <extracted> <skipped> <bytes> <error>
+var porcelainEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// writePorcelainRecord writes one record, escaping tabs, line breaks and backslashes in its fields
+func writePorcelainRecord(w io.Writer, fields ...string) {
+	for i, field := range fields {
+		fields[i] = porcelainEscaper.Replace(field)
+	}
+	fmt.Fprintln(w, strings.Join(fields, "\t"))
+}
+
+// porcelainAttributes lists a track's flags as "default,forced,disabled", or "-" for none
+func porcelainAttributes(track model.MKVTrack) string {
+	var attributes []string
+	if track.Properties.Default {
+		attributes = append(attributes, "default")
+	}
+	if track.Properties.Forced {
+		attributes = append(attributes, "forced")
+	}
+	if !track.Properties.Enabled {
+		attributes = append(attributes, "disabled")
+	}
+	if len(attributes) == 0 {
+		return "-"
+	}
+	return strings.Join(attributes, ",")
+}
+
+// PrintPorcelainInfo writes a track record for every subtitle track of a file. source names the file
+// in the records, such as the URL of a downloaded file.
+func PrintPorcelainInfo(ctx context.Context, w io.Writer, inputFileName, source string) error {
+	mkvInfo, err := analyzeFile(ctx, inputFileName)
+	if err != nil {
+		return err
+	}
+	for _, track := range mkvInfo.Tracks {
+		if track.Type != "subtitles" {
+			continue
+		}
+		writePorcelainRecord(w, "track", source, strconv.Itoa(track.Properties.Number), track.Properties.Language,
+			model.TrackExtension(track.Properties.CodecId), porcelainAttributes(track), track.Properties.TrackName)
+	}
+	return nil
+}
+
+// PrintPorcelainResult writes the output records of a processed file, or its planned outputs in a dry
+// run, followed by its file record. The status of the file record is "ok" or "failed".
+func PrintPorcelainResult(w io.Writer, source string, result model.FileResult, err error) {
+	for _, output := range result.Outputs {
+		track := output.Track
+		writePorcelainRecord(w, "output", source, strconv.Itoa(track.Properties.Number), track.Properties.Language,
+			model.TrackExtension(track.Properties.CodecId), strconv.FormatInt(output.Bytes, 10), output.Path)
+	}
+	if len(result.Outputs) == 0 {
+		for _, plan := range result.Plan {
+			if plan.OutFileName == "" {
+				continue
+			}
+			writePorcelainRecord(w, "planned", source, strconv.Itoa(plan.Track.Properties.Number), plan.Track.Properties.Language,
+				model.TrackExtension(plan.Track.Properties.CodecId), plan.OutFileName)
+		}
+	}
+
+	status, message := "ok", ""
+	if err != nil {
+		status, message = "failed", err.Error()
+	}
+	writePorcelainRecord(w, "file", source, status, strconv.Itoa(result.Extracted), strconv.Itoa(result.Skipped),
+		strconv.FormatInt(result.OutputBytes, 10), message)
+}
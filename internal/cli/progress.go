@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/i18n"
+	"subscalpelmkv/internal/mkv"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/progress"
+	"subscalpelmkv/internal/util"
+)
+
+// terminalProgress holds the spinner and progress bar currently drawn by PrintMKVEvent
+var terminalProgress struct {
+	spinner *progress.Spinner
+	ticker  *time.Ticker
+	done    chan struct{}
+}
+
+// PrintMKVEvent draws extraction events on the terminal: spinners, progress bars and track lines
+func PrintMKVEvent(event mkv.Event) {
+	switch event.Kind {
+	case mkv.EventStart:
+		switch event.Stage {
+		case mkv.StageAnalyze:
+			terminalProgress.spinner = util.StartSpinner(i18n.T("Analyzing tracks..."))
+		case mkv.StagePrepare:
+			format.PrintStep(1, "Preparing selected tracks for extraction...")
+			util.ResetProgressBar()
+		case mkv.StageExtract, mkv.StageDemux:
+			util.ResetProgressBar()
+		}
+		// The stage reads its input file once, so its size and the percentage give the bytes processed
+		if event.Stage != mkv.StageAnalyze {
+			if info, err := os.Stat(event.File); err == nil {
+				util.SetProgressTotalBytes(info.Size())
+			}
+		}
+	case mkv.EventProgress:
+		if terminalProgress.ticker == nil {
+			startProgressBar()
+		}
+		util.ShowProgressBar(event.Percent)
+	case mkv.EventEnd:
+		if event.Stage == mkv.StageAnalyze {
+			terminalProgress.spinner.Stop()
+			terminalProgress.spinner = nil
+			return
+		}
+		if terminalProgress.ticker != nil {
+			stopProgressBar(event.Err != nil)
+			if (event.Stage == mkv.StageExtract || event.Stage == mkv.StageDemux) && event.Err == nil {
+				fmt.Println()
+				fmt.Println()
+			}
+		}
+	case mkv.EventTrack:
+		printExtractedTrack(event.TrackNumber, event.Track, event.OutFileName)
+	case mkv.EventInfo:
+		format.PrintInfo(event.Message)
+	case mkv.EventWarning:
+		format.PrintWarning(event.Message)
+	case mkv.EventSuccess:
+		format.PrintSuccess(event.Message)
+	case mkv.EventError:
+		format.PrintError(event.Message)
+	}
+}
+
+// startProgressBar hides the cursor and keeps the elapsed time of the bar ticking between updates
+func startProgressBar() {
+	fmt.Print("\033[?25l")
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				util.UpdateElapsedTime()
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	terminalProgress.ticker = ticker
+	terminalProgress.done = done
+}
+
+// stopProgressBar stops the elapsed time and shows the cursor again, clearing the bar on failure
+// so the error is printed on a clean line
+func stopProgressBar(failed bool) {
+	terminalProgress.done <- struct{}{}
+	terminalProgress.ticker = nil
+
+	fmt.Print("\033[?25h")
+	if failed {
+		fmt.Print("\r\033[K")
+	}
+}
+
+// printExtractedTrack prints an extracted track in a two-line format matching dry-run style
+func printExtractedTrack(trackNumber int, track model.MKVTrack, outFileName string) {
+	// Get codec type for display
+	codecType := "Unknown"
+	if ext := model.TrackExtension(track.Properties.CodecId); ext != "" {
+		codecType = strings.ToUpper(ext)
+	}
+
+	// Build track details string
+	trackDetails := fmt.Sprintf("Track %d (%s)", trackNumber, track.Properties.Language)
+	if track.Properties.TrackName != "" {
+		trackDetails += fmt.Sprintf(" - %s", track.Properties.TrackName)
+	}
+
+	// Add format and attributes
+	attributes := []string{codecType}
+	if track.Properties.Forced {
+		attributes = append(attributes, "forced")
+	}
+	if track.Properties.Default {
+		attributes = append(attributes, "default")
+	}
+
+	// First line: Track details with checkmark
+	format.SuccessColor.Print("  ✓ ")
+	format.BaseFg.Println(fmt.Sprintf("%s [%s]", trackDetails, strings.Join(attributes, ", ")))
+
+	// Second line: Output path with arrow
+	format.PrintExample(fmt.Sprintf("    → %s", outFileName))
+	fmt.Println()
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"subscalpelmkv/internal/mkv"
+	"subscalpelmkv/internal/model"
+)
+
+// jsonEventNames are the "event" values of the JSON progress stream
+var jsonEventNames = map[mkv.EventKind]string{
+	mkv.EventStart:    "start",
+	mkv.EventProgress: "progress",
+	mkv.EventEnd:      "end",
+	mkv.EventTrack:    "track",
+	mkv.EventInfo:     "info",
+	mkv.EventWarning:  "warning",
+	mkv.EventSuccess:  "success",
+	mkv.EventError:    "error",
+}
+
+// jsonEvent is one line of the --progress-json stream. Its field names are kept stable for the
+// programs reading it.
+type jsonEvent struct {
+	Time     string `json:"time"`
+	Event    string `json:"event"`
+	Stage    string `json:"stage,omitempty"`
+	File     string `json:"file,omitempty"`
+	Percent  *int   `json:"percent,omitempty"`
+	Track    int    `json:"track,omitempty"`
+	Language string `json:"language,omitempty"`
+	Format   string `json:"format,omitempty"`
+	Output   string `json:"output,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// JSONProgressFunc returns a progress function writing every extraction event to w as a line of
+// JSON before passing it on to next, which keeps drawing the terminal output. Writing stops at the
+// first error, as when the program reading a FIFO exits.
+func JSONProgressFunc(w io.Writer, next mkv.ProgressFunc) mkv.ProgressFunc {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	failed := false
+
+	return func(event mkv.Event) {
+		if !failed {
+			if err := encoder.Encode(newJSONEvent(event)); err != nil {
+				failed = true
+			}
+		}
+		if next != nil {
+			next(event)
+		}
+	}
+}
+
+// newJSONEvent converts an extraction event into its JSON form
+func newJSONEvent(event mkv.Event) jsonEvent {
+	line := jsonEvent{
+		Time:  time.Now().Format(time.RFC3339Nano),
+		Event: jsonEventNames[event.Kind],
+		File:  event.File,
+	}
+
+	switch event.Kind {
+	case mkv.EventStart, mkv.EventProgress, mkv.EventEnd, mkv.EventTrack:
+		line.Stage = event.Stage.String()
+	default:
+		line.Message = event.Message
+	}
+	if event.Kind == mkv.EventProgress {
+		percent := event.Percent
+		line.Percent = &percent
+	}
+	if event.Kind == mkv.EventEnd && event.Err != nil {
+		line.Error = event.Err.Error()
+	}
+	if event.Kind == mkv.EventTrack {
+		line.Track = event.TrackNumber
+		line.Language = event.Track.Properties.Language
+		line.Format = model.TrackExtension(event.Track.Properties.CodecId)
+		line.Output = event.OutFileName
+	}
+	return line
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/i18n"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/util"
+)
+
+// SkipKeyword leaves a file out of a batch at the per-file selection prompt
+const SkipKeyword = "skip"
+
+// FileRefinement replaces the batch selection for one file
+type FileRefinement struct {
+	Skip            bool // Leave the file out of the batch
+	LanguageFilter  string
+	ExclusionFilter string
+}
+
+// RefineBatchSelection lists the files whose tracks the batch selection matches differently from
+// most files, such as an episode with an extra or a missing track, and lets the user give them a
+// selection of their own or skip them. It returns the refinements by file path.
+func RefineBatchSelection(files []string, mkvInfos map[string]*model.MKVInfo, selection model.TrackSelection) map[string]FileRefinement {
+	// Files are compared by the language and format of their matching tracks
+	matches := make(map[string]string)
+	counts := make(map[string]int)
+	for _, file := range files {
+		if mkvInfo := mkvInfos[file]; mkvInfo != nil {
+			matches[file] = describeMatches(mkvInfo.Tracks, selection)
+			counts[matches[file]]++
+		}
+	}
+	typical := ""
+	for _, file := range files {
+		if match, found := matches[file]; found && counts[match] > counts[typical] {
+			typical = match
+		}
+	}
+
+	var differing []string
+	for _, file := range files {
+		if match, found := matches[file]; found && match != typical {
+			differing = append(differing, file)
+		}
+	}
+	if len(differing) == 0 {
+		return nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	format.PrintSubSection(i18n.T("Files Matching Differently"))
+	format.PrintInfo(i18n.T("Most files: %s", describeMatchList(typical)))
+	for i, file := range differing {
+		format.PrintExample(fmt.Sprintf("%2d  %s: %s", i+1, filepath.Base(file), describeMatchList(matches[file])))
+	}
+
+	var picked []int
+	for {
+		format.PrintPromptWithPlaceholder(i18n.T("Refine files (e.g., 1 3 or 1-2):"), i18n.T(" (press enter to use the selection for all)"))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return nil
+		}
+		var ok bool
+		if picked, ok = parseFileNumbers(strings.TrimSpace(strings.ToLower(input)), len(differing)); ok {
+			break
+		}
+		format.PrintWarning(i18n.T("Please enter numbers from 1 to %d.", len(differing)))
+	}
+
+	refinements := make(map[string]FileRefinement)
+	for _, number := range picked {
+		file := differing[number-1]
+		fmt.Println()
+		DisplaySubtitleTracks(mkvInfos[file])
+		if refinement, changed := askFileRefinement(reader, file, mkvInfos[file]); changed {
+			refinements[file] = refinement
+		}
+	}
+	return refinements
+}
+
+// askFileRefinement asks for the selection of one file, reporting false when the batch selection is kept
+func askFileRefinement(reader *bufio.Reader, file string, mkvInfo *model.MKVInfo) (FileRefinement, bool) {
+	var availableTracks []int
+	for _, track := range mkvInfo.Tracks {
+		if track.Type == "subtitles" {
+			availableTracks = append(availableTracks, track.Properties.Number)
+		}
+	}
+
+	for {
+		format.PrintExample(i18n.T("Enter a selection for this file, or %s to leave it out", SkipKeyword))
+		format.PrintPromptWithPlaceholder(i18n.T("Selection for %s:", filepath.Base(file)), i18n.T(" (press enter to keep the batch selection)"))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return FileRefinement{}, false
+		}
+		input = strings.TrimSpace(input)
+
+		switch {
+		case input == "":
+			return FileRefinement{}, false
+		case strings.EqualFold(input, SkipKeyword):
+			format.PrintInfo(i18n.T("Skipping %s", filepath.Base(file)))
+			return FileRefinement{Skip: true}, true
+		}
+
+		selection, invalidItems := ParseTrackSelectionWithValidation(input, availableTracks)
+		if len(invalidItems) > 0 {
+			for _, item := range invalidItems {
+				format.PrintWarning(i18n.T("Unknown language code, format, or invalid track ID '%s'", item))
+			}
+			continue
+		}
+		return FileRefinement{
+			LanguageFilter:  convertSelectionToString(selection),
+			ExclusionFilter: convertExclusionToString(selection.Exclusions),
+		}, true
+	}
+}
+
+// describeMatches lists the language and format of the subtitle tracks a selection matches, e.g.
+// "eng SRT, jpn ASS"
+func describeMatches(tracks []model.MKVTrack, selection model.TrackSelection) string {
+	var descriptions []string
+	for _, track := range tracks {
+		if track.Type == "subtitles" && util.MatchesTrackSelection(track, selection) {
+			descriptions = append(descriptions, track.Properties.Language+" "+strings.ToUpper(model.TrackExtension(track.Properties.CodecId)))
+		}
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// describeMatchList names a describeMatches list for display, which is empty when nothing matched
+func describeMatchList(matches string) string {
+	if matches == "" {
+		return i18n.T("no matching tracks")
+	}
+	return matches
+}
+
+// parseFileNumbers parses numbers and ranges from 1 to count, as in "1 3" or "1-2". Empty input
+// yields none.
+func parseFileNumbers(input string, count int) ([]int, bool) {
+	var numbers []int
+	for _, item := range strings.FieldsFunc(input, func(r rune) bool { return r == ' ' || r == ',' }) {
+		positions, ok := model.ParseTrackRange(item)
+		if !ok {
+			position, err := strconv.Atoi(item)
+			if err != nil {
+				return nil, false
+			}
+			positions = []int{position}
+		}
+		for _, position := range positions {
+			if position < 1 || position > count {
+				return nil, false
+			}
+			if !slices.Contains(numbers, position) {
+				numbers = append(numbers, position)
+			}
+		}
+	}
+	return numbers, true
+}
@@ -2,10 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
 	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/i18n"
 	"subscalpelmkv/internal/model"
 )
 
@@ -18,8 +20,9 @@ type SelectionResult struct {
 	Title           string
 }
 
-// ProcessSelectionAndExclusion handles the common logic for processing track selections and exclusions
-func ProcessSelectionAndExclusion(extractAll bool, availableTracks []int) (*SelectionResult, error) {
+// ProcessSelectionAndExclusion handles the common logic for processing track selections and exclusions.
+// When menuTracks is given, the selection prompt also offers them as a numbered menu.
+func ProcessSelectionAndExclusion(extractAll bool, availableTracks []int, menuTracks []model.MKVTrack) (*SelectionResult, error) {
 	result := &SelectionResult{}
 
 	if !extractAll {
@@ -27,14 +30,26 @@ func ProcessSelectionAndExclusion(extractAll bool, availableTracks []int) (*Sele
 		var selectionInput string
 		var validSelection bool
 		for !validSelection {
-			selectionInput = AskTrackSelection()
+			selectionInput = AskTrackSelection(len(menuTracks) > 0)
+			if len(menuTracks) > 0 && strings.EqualFold(selectionInput, MenuKeyword) {
+				trackNums := AskTrackMenu(menuTracks)
+				if len(trackNums) == 0 {
+					format.PrintWarning(i18n.T("No tracks selected"))
+					fmt.Println() // Add spacing
+					continue
+				}
+				result.Selection = model.TrackSelection{TrackNumbers: trackNums}
+				result.LanguageFilter = convertSelectionToString(result.Selection)
+				result.Title, result.Message = buildSelectionTitleAndMessage(result.Selection, result.Selection.Exclusions)
+				return result, nil
+			}
 			var invalidItems []string
 			result.Selection, invalidItems = ParseTrackSelectionWithValidation(selectionInput, availableTracks)
 			
 			if len(invalidItems) > 0 {
 				// Show warning and ask to retry
 				for _, item := range invalidItems {
-					format.PrintWarning(fmt.Sprintf("Unknown language code, format, or invalid track ID '%s'", item))
+					format.PrintWarning(i18n.T("Unknown language code, format, or invalid track ID '%s'", item))
 				}
 				fmt.Println() // Add spacing
 				continue
@@ -42,7 +57,7 @@ func ProcessSelectionAndExclusion(extractAll bool, availableTracks []int) (*Sele
 			validSelection = true
 		}
 
-		if len(result.Selection.LanguageCodes) == 0 && len(result.Selection.TrackNumbers) == 0 && len(result.Selection.FormatFilters) == 0 {
+		if len(result.Selection.LanguageCodes) == 0 && len(result.Selection.TrackNumbers) == 0 && len(result.Selection.FormatFilters) == 0 && len(result.Selection.StatusFilters) == 0 {
 			// Empty input means accept all tracks - same as extractAll = true
 			// Ask for exclusions when extracting all tracks
 			var exclusionInput string
@@ -57,19 +72,21 @@ func ProcessSelectionAndExclusion(extractAll bool, availableTracks []int) (*Sele
 					if len(invalidItems) > 0 {
 						// Show warning and ask to retry
 						for _, item := range invalidItems {
-							format.PrintWarning(fmt.Sprintf("Unknown exclusion language code, format, or invalid track ID '%s'", item))
+							format.PrintWarning(i18n.T("Unknown exclusion language code, format, or invalid track ID '%s'", item))
 						}
 						fmt.Println() // Add spacing
 						continue
 					}
 					
-					result.Selection.Exclusions = exclusion
+					result.Selection.Exclusions = model.MergeTrackExclusions(result.Selection.Exclusions, exclusion)
+				}
+				if exclusion := result.Selection.Exclusions; len(exclusion.LanguageCodes) > 0 || len(exclusion.TrackNumbers) > 0 || len(exclusion.FormatFilters) > 0 || len(exclusion.StatusFilters) > 0 {
 					result.ExclusionFilter = convertExclusionToString(exclusion)
-					result.Title = "Track Processing"
+					result.Title = i18n.T("Track Processing")
 					result.Message = buildExclusionOnlyMessage(exclusion)
 				} else {
-					result.Title = "Track Processing"
-					result.Message = "Extracting all subtitle tracks"
+					result.Title = i18n.T("Track Processing")
+					result.Message = i18n.T("Extracting all subtitle tracks")
 				}
 				validExclusion = true
 			}
@@ -87,17 +104,17 @@ func ProcessSelectionAndExclusion(extractAll bool, availableTracks []int) (*Sele
 					if len(invalidItems) > 0 {
 						// Show warning and ask to retry
 						for _, item := range invalidItems {
-							format.PrintWarning(fmt.Sprintf("Unknown exclusion language code, format, or invalid track ID '%s'", item))
+							format.PrintWarning(i18n.T("Unknown exclusion language code, format, or invalid track ID '%s'", item))
 						}
 						fmt.Println() // Add spacing
 						continue
 					}
 					
-					result.Selection.Exclusions = exclusion
-					result.ExclusionFilter = convertExclusionToString(exclusion)
+					result.Selection.Exclusions = model.MergeTrackExclusions(result.Selection.Exclusions, exclusion)
 				}
 				validExclusion = true
 			}
+			result.ExclusionFilter = convertExclusionToString(result.Selection.Exclusions)
 
 			// Convert to comma-separated string for processFile function
 			result.LanguageFilter = convertSelectionToString(result.Selection)
@@ -105,8 +122,8 @@ func ProcessSelectionAndExclusion(extractAll bool, availableTracks []int) (*Sele
 		}
 	} else {
 		// When extracting all tracks, don't ask for exclusions - just extract everything
-		result.Title = "Track Processing"
-		result.Message = "Extracting all subtitle tracks"
+		result.Title = i18n.T("Track Processing")
+		result.Message = i18n.T("Extracting all subtitle tracks")
 	}
 
 	return result, nil
@@ -119,18 +136,18 @@ func ProcessSelectionForBatch(selection model.TrackSelection, exclusion model.Tr
 	}
 	result.Selection.Exclusions = exclusion
 
-	if len(selection.LanguageCodes) > 0 || len(selection.TrackNumbers) > 0 || len(selection.FormatFilters) > 0 {
+	if len(selection.LanguageCodes) > 0 || len(selection.TrackNumbers) > 0 || len(selection.FormatFilters) > 0 || len(selection.StatusFilters) > 0 {
 		result.LanguageFilter = convertSelectionToString(selection)
 	}
 
-	if len(exclusion.LanguageCodes) > 0 || len(exclusion.TrackNumbers) > 0 || len(exclusion.FormatFilters) > 0 {
+	if len(exclusion.LanguageCodes) > 0 || len(exclusion.TrackNumbers) > 0 || len(exclusion.FormatFilters) > 0 || len(exclusion.StatusFilters) > 0 {
 		result.ExclusionFilter = convertExclusionToString(exclusion)
 	}
 
 	if result.LanguageFilter != "" {
 		result.Title, result.Message = buildSelectionTitleAndMessage(selection, exclusion)
 	} else if result.ExclusionFilter != "" {
-		result.Title = "Track Processing"
+		result.Title = i18n.T("Track Processing")
 		result.Message = buildExclusionOnlyMessage(exclusion)
 	}
 
@@ -145,6 +162,7 @@ func convertSelectionToString(selection model.TrackSelection) string {
 		filterParts = append(filterParts, strconv.Itoa(trackNum))
 	}
 	filterParts = append(filterParts, selection.FormatFilters...)
+	filterParts = append(filterParts, selection.StatusFilters...)
 	return strings.Join(filterParts, ",")
 }
 
@@ -156,6 +174,7 @@ func convertExclusionToString(exclusion model.TrackExclusion) string {
 		exclusionParts = append(exclusionParts, strconv.Itoa(trackNum))
 	}
 	exclusionParts = append(exclusionParts, exclusion.FormatFilters...)
+	exclusionParts = append(exclusionParts, exclusion.StatusFilters...)
 	return strings.Join(exclusionParts, ",")
 }
 
@@ -163,228 +182,122 @@ func convertExclusionToString(exclusion model.TrackExclusion) string {
 func buildSelectionTitleAndMessage(selection model.TrackSelection, exclusion model.TrackExclusion) (string, string) {
 	var messageParts []string
 	if len(selection.LanguageCodes) > 0 {
-		messageParts = append(messageParts, fmt.Sprintf("languages: %s", strings.Join(selection.LanguageCodes, ", ")))
+		messageParts = append(messageParts, i18n.T("languages: %s", strings.Join(selection.LanguageCodes, ", ")))
 	}
 	if len(selection.TrackNumbers) > 0 {
 		trackStrs := make([]string, len(selection.TrackNumbers))
 		for i, t := range selection.TrackNumbers {
 			trackStrs[i] = strconv.Itoa(t)
 		}
-		messageParts = append(messageParts, fmt.Sprintf("track IDs: %s", strings.Join(trackStrs, ", ")))
+		messageParts = append(messageParts, i18n.T("track IDs: %s", strings.Join(trackStrs, ", ")))
 	}
 	if len(selection.FormatFilters) > 0 {
-		messageParts = append(messageParts, fmt.Sprintf("formats: %s", strings.Join(selection.FormatFilters, ", ")))
+		messageParts = append(messageParts, i18n.T("formats: %s", strings.Join(selection.FormatFilters, ", ")))
+	}
+	if len(selection.StatusFilters) > 0 {
+		messageParts = append(messageParts, i18n.T("status: %s", strings.Join(selection.StatusFilters, ", ")))
 	}
 
 	if len(messageParts) == 0 {
 		return "", ""
 	}
 
-	baseMessage := fmt.Sprintf("Selecting tracks matching %s", strings.Join(messageParts, "; "))
+	baseMessage := i18n.T("Selecting tracks matching %s", strings.Join(messageParts, "; "))
 
 	// Add exclusion info if present
-	if len(exclusion.LanguageCodes) > 0 || len(exclusion.TrackNumbers) > 0 || len(exclusion.FormatFilters) > 0 {
+	if len(exclusion.LanguageCodes) > 0 || len(exclusion.TrackNumbers) > 0 || len(exclusion.FormatFilters) > 0 || len(exclusion.StatusFilters) > 0 {
 		var exclusionMsgParts []string
 		if len(exclusion.LanguageCodes) > 0 {
-			exclusionMsgParts = append(exclusionMsgParts, fmt.Sprintf("languages: %s", strings.Join(exclusion.LanguageCodes, ", ")))
+			exclusionMsgParts = append(exclusionMsgParts, i18n.T("languages: %s", strings.Join(exclusion.LanguageCodes, ", ")))
 		}
 		if len(exclusion.TrackNumbers) > 0 {
 			trackStrs := make([]string, len(exclusion.TrackNumbers))
 			for i, t := range exclusion.TrackNumbers {
 				trackStrs[i] = strconv.Itoa(t)
 			}
-			exclusionMsgParts = append(exclusionMsgParts, fmt.Sprintf("track IDs: %s", strings.Join(trackStrs, ", ")))
+			exclusionMsgParts = append(exclusionMsgParts, i18n.T("track IDs: %s", strings.Join(trackStrs, ", ")))
 		}
 		if len(exclusion.FormatFilters) > 0 {
-			exclusionMsgParts = append(exclusionMsgParts, fmt.Sprintf("formats: %s", strings.Join(exclusion.FormatFilters, ", ")))
+			exclusionMsgParts = append(exclusionMsgParts, i18n.T("formats: %s", strings.Join(exclusion.FormatFilters, ", ")))
+		}
+		if len(exclusion.StatusFilters) > 0 {
+			exclusionMsgParts = append(exclusionMsgParts, i18n.T("status: %s", strings.Join(exclusion.StatusFilters, ", ")))
 		}
 
 		if len(exclusionMsgParts) > 0 {
-			baseMessage = fmt.Sprintf("%s; excluding %s", baseMessage, strings.Join(exclusionMsgParts, "; "))
+			baseMessage = i18n.T("%s; excluding %s", baseMessage, strings.Join(exclusionMsgParts, "; "))
 		}
 	}
 
-	return "Track Processing", baseMessage
+	return i18n.T("Track Processing"), baseMessage
 }
 
 // buildExclusionOnlyMessage builds a message when only exclusions are specified
 func buildExclusionOnlyMessage(exclusion model.TrackExclusion) string {
 	var exclusionMsgParts []string
 	if len(exclusion.LanguageCodes) > 0 {
-		exclusionMsgParts = append(exclusionMsgParts, fmt.Sprintf("languages: %s", strings.Join(exclusion.LanguageCodes, ", ")))
+		exclusionMsgParts = append(exclusionMsgParts, i18n.T("languages: %s", strings.Join(exclusion.LanguageCodes, ", ")))
 	}
 	if len(exclusion.TrackNumbers) > 0 {
 		trackStrs := make([]string, len(exclusion.TrackNumbers))
 		for i, t := range exclusion.TrackNumbers {
 			trackStrs[i] = strconv.Itoa(t)
 		}
-		exclusionMsgParts = append(exclusionMsgParts, fmt.Sprintf("track IDs: %s", strings.Join(trackStrs, ", ")))
+		exclusionMsgParts = append(exclusionMsgParts, i18n.T("track IDs: %s", strings.Join(trackStrs, ", ")))
 	}
 	if len(exclusion.FormatFilters) > 0 {
-		exclusionMsgParts = append(exclusionMsgParts, fmt.Sprintf("formats: %s", strings.Join(exclusion.FormatFilters, ", ")))
+		exclusionMsgParts = append(exclusionMsgParts, i18n.T("formats: %s", strings.Join(exclusion.FormatFilters, ", ")))
+	}
+	if len(exclusion.StatusFilters) > 0 {
+		exclusionMsgParts = append(exclusionMsgParts, i18n.T("status: %s", strings.Join(exclusion.StatusFilters, ", ")))
 	}
 
 	if len(exclusionMsgParts) > 0 {
-		return fmt.Sprintf("Excluding tracks matching %s", strings.Join(exclusionMsgParts, "; "))
+		return i18n.T("Excluding tracks matching %s", strings.Join(exclusionMsgParts, "; "))
 	}
-	return "Extracting all subtitle tracks"
+	return i18n.T("Extracting all subtitle tracks")
 }
 
-// ParseTrackSelectionWithValidation parses track selection input and returns invalid items
+// ParseTrackSelectionWithValidation parses track selection input and returns invalid items: those
+// the selection syntax does not know and track numbers or ranges naming tracks the file lacks
 func ParseTrackSelectionWithValidation(input string, availableTracks []int) (model.TrackSelection, []string) {
-	selection := model.TrackSelection{
-		LanguageCodes: []string{},
-		TrackNumbers:  []int{},
-		FormatFilters: []string{},
-		Exclusions:    model.TrackExclusion{},
-	}
-	
-	var invalidItems []string
-
-	if input == "" {
-		return selection, invalidItems
-	}
-
-	items := strings.Split(input, ",")
-
-	for _, item := range items {
-		item = strings.TrimSpace(item)
-		if item == "" {
-			continue
-		}
-
-		// Try to parse as track number first
-		if trackNum, err := strconv.Atoi(item); err == nil {
-			// Check if track number is valid
-			isValidTrack := false
-			for _, validTrack := range availableTracks {
-				if trackNum == validTrack {
-					isValidTrack = true
-					break
-				}
-			}
-			if isValidTrack {
-				selection.TrackNumbers = append(selection.TrackNumbers, trackNum)
-				continue
-			} else {
-				invalidItems = append(invalidItems, item)
-				continue
-			}
-		}
-
-		// Try to parse as language code
-		isValidLanguage := false
-		if len(item) == 2 {
-			_, isValidLanguage = model.LanguageCodeMapping[strings.ToLower(item)]
-		} else if len(item) == 3 {
-			for _, threeLetter := range model.LanguageCodeMapping {
-				if strings.EqualFold(item, threeLetter) {
-					isValidLanguage = true
-					break
-				}
-			}
-		}
-
-		if isValidLanguage {
-			selection.LanguageCodes = append(selection.LanguageCodes, item)
-			continue
-		}
-
-		// Try to parse as subtitle format filter
-		isValidFormat := false
-		lowerItem := strings.ToLower(item)
-		for _, ext := range model.SubtitleExtensionByCodec {
-			if lowerItem == ext {
-				isValidFormat = true
-				break
-			}
-		}
-
-		if isValidFormat {
-			selection.FormatFilters = append(selection.FormatFilters, lowerItem)
-		} else {
-			invalidItems = append(invalidItems, item)
-		}
-	}
-
-	return selection, invalidItems
+	validInput, invalidItems := dropUnavailableTracks(input, availableTracks)
+	selection, unknownItems := model.ParseTrackSelection(validInput)
+	return selection, append(invalidItems, unknownItems...)
 }
 
 // ParseTrackExclusionWithValidation parses track exclusion input and returns invalid items
 func ParseTrackExclusionWithValidation(input string, availableTracks []int) (model.TrackExclusion, []string) {
-	exclusion := model.TrackExclusion{
-		LanguageCodes: []string{},
-		TrackNumbers:  []int{},
-		FormatFilters: []string{},
-	}
-	
-	var invalidItems []string
-
-	if input == "" {
-		return exclusion, invalidItems
-	}
-
-	items := strings.Split(input, ",")
-
-	for _, item := range items {
-		item = strings.TrimSpace(item)
-		if item == "" {
-			continue
-		}
-
-		// Try to parse as track number first
-		if trackNum, err := strconv.Atoi(item); err == nil {
-			// Check if track number is valid
-			isValidTrack := false
-			for _, validTrack := range availableTracks {
-				if trackNum == validTrack {
-					isValidTrack = true
-					break
-				}
-			}
-			if isValidTrack {
-				exclusion.TrackNumbers = append(exclusion.TrackNumbers, trackNum)
-				continue
-			} else {
-				invalidItems = append(invalidItems, item)
-				continue
-			}
-		}
+	validInput, invalidItems := dropUnavailableTracks(input, availableTracks)
+	exclusion, unknownItems := model.ParseTrackExclusion(validInput)
+	return exclusion, append(invalidItems, unknownItems...)
+}
 
-		// Try to parse as language code
-		isValidLanguage := false
-		if len(item) == 2 {
-			_, isValidLanguage = model.LanguageCodeMapping[strings.ToLower(item)]
-		} else if len(item) == 3 {
-			for _, threeLetter := range model.LanguageCodeMapping {
-				if strings.EqualFold(item, threeLetter) {
-					isValidLanguage = true
-					break
-				}
-			}
+// dropUnavailableTracks removes the track numbers and ranges, negated or not, that name a track
+// missing from availableTracks. It returns the remaining input and the removed items.
+func dropUnavailableTracks(input string, availableTracks []int) (string, []string) {
+	var kept, invalidItems []string
+	for _, item := range strings.Split(input, ",") {
+		trimmed := strings.TrimPrefix(strings.TrimSpace(item), "!")
+		trackNums, isTrackItem := model.ParseTrackRange(trimmed)
+		if trackNum, err := strconv.Atoi(trimmed); err == nil {
+			trackNums, isTrackItem = []int{trackNum}, true
 		}
-
-		if isValidLanguage {
-			exclusion.LanguageCodes = append(exclusion.LanguageCodes, item)
+		if isTrackItem && !containsAllTracks(availableTracks, trackNums) {
+			invalidItems = append(invalidItems, strings.TrimSpace(item))
 			continue
 		}
+		kept = append(kept, item)
+	}
+	return strings.Join(kept, ","), invalidItems
+}
 
-		// Try to parse as subtitle format filter
-		isValidFormat := false
-		lowerItem := strings.ToLower(item)
-		for _, ext := range model.SubtitleExtensionByCodec {
-			if lowerItem == ext {
-				isValidFormat = true
-				break
-			}
-		}
-
-		if isValidFormat {
-			exclusion.FormatFilters = append(exclusion.FormatFilters, lowerItem)
-		} else {
-			invalidItems = append(invalidItems, item)
+// containsAllTracks reports whether every track number is one of the available tracks
+func containsAllTracks(availableTracks []int, trackNums []int) bool {
+	for _, trackNum := range trackNums {
+		if !slices.Contains(availableTracks, trackNum) {
+			return false
 		}
 	}
-
-	return exclusion, invalidItems
-}
\ No newline at end of file
+	return true
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/i18n"
+	"subscalpelmkv/internal/mkv"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/stats"
+)
+
+// HandleStatsCommand runs the `stats <dir>... [--format table|json]` subcommand, which summarizes the
+// subtitle coverage of a library. JSON goes to stdout without the title box so it can be redirected.
+func HandleStatsCommand(args []string, version string) error {
+	var paths []string
+	outputFormat := stats.FormatTable
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--format":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			outputFormat = strings.ToLower(args[i+1])
+			i++
+		case strings.HasPrefix(arg, "--format="):
+			outputFormat = strings.ToLower(strings.TrimPrefix(arg, "--format="))
+		case arg == "--lang":
+			// Already applied by main before the command runs
+			i++
+		case strings.HasPrefix(arg, "--lang="):
+		case strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unknown stats option '%s'", arg)
+		default:
+			paths = append(paths, arg)
+		}
+	}
+
+	if outputFormat == stats.FormatTable {
+		format.PrintTitleWithVersion(version)
+		mkv.SetProgressFunc(PrintMKVEvent)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("usage: subscalpelmkv stats <dir|file>... [--format table|json]")
+	}
+	if outputFormat != stats.FormatTable && outputFormat != stats.FormatJSON {
+		return fmt.Errorf("invalid stats format '%s': must be table or json", outputFormat)
+	}
+
+	files, err := collectMKVFiles(paths)
+	if err != nil {
+		return err
+	}
+	report := stats.NewReport()
+	for _, file := range files {
+		report.AnalyzeFile(context.Background(), file)
+	}
+	report.Finish()
+
+	if outputFormat == stats.FormatJSON {
+		return report.WriteJSON(os.Stdout)
+	}
+	printStatsReport(report)
+	return nil
+}
+
+// printStatsReport shows a statistics report as tables
+func printStatsReport(report *stats.Report) {
+	format.PrintSubSection(i18n.T("Library Statistics"))
+	format.PrintInfo(i18n.T("%d file(s), %d subtitle track(s)", report.Files, report.Tracks))
+
+	if len(report.Languages) > 0 {
+		format.PrintSubSection(i18n.T("Languages"))
+		fmt.Println()
+		for _, count := range report.Languages {
+			format.PrintExample(fmt.Sprintf("    %-4s %-22s %s", count.Name, format.Truncate(model.GetLanguageName(count.Name), 22),
+				i18n.T("%5d track(s) in %5d file(s) (%s)", count.Tracks, count.Files, percentOf(count.Files, report.Analyzed))))
+		}
+	}
+
+	if len(report.Formats) > 0 {
+		format.PrintSubSection(i18n.T("Formats"))
+		fmt.Println()
+		for _, count := range report.Formats {
+			format.PrintExample(fmt.Sprintf("    %-27s %s", strings.ToUpper(count.Name),
+				i18n.T("%5d track(s) in %5d file(s) (%s)", count.Tracks, count.Files, percentOf(count.Files, report.Analyzed))))
+		}
+	}
+
+	printStatsFiles(i18n.T("Files without subtitles (%d)", len(report.WithoutSubtitles)), report.WithoutSubtitles)
+	printStatsFiles(i18n.T("Files with only image-based subtitles (%d)", len(report.ImageOnly)), report.ImageOnly)
+
+	if len(report.Errors) > 0 {
+		format.PrintSubSection(i18n.T("Files that could not be analyzed (%d)", len(report.Errors)))
+		fmt.Println()
+		for _, fileError := range report.Errors {
+			format.PrintError(fmt.Sprintf("%s: %s", fileError.File, fileError.Error))
+		}
+	}
+}
+
+// printStatsFiles lists the files of a report section, if any
+func printStatsFiles(title string, files []string) {
+	if len(files) == 0 {
+		return
+	}
+	format.PrintSubSection(title)
+	fmt.Println()
+	for _, file := range files {
+		format.PrintWarning(file)
+	}
+}
+
+// percentOf formats part as a percentage of total
+func percentOf(part, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%d%%", part*100/total)
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"subscalpelmkv/internal/config"
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/i18n"
+	"subscalpelmkv/internal/mkv"
+)
+
+// ShowVersion prints the MKVToolNix versions, the config file in use and the runtime, for bug reports
+func ShowVersion(version string) {
+	format.PrintSubSection(i18n.T("Version Information"))
+	fmt.Println()
+
+	printVersionLine("subscalpelmkv", fmt.Sprintf("v%s", version), true)
+	for _, tool := range mkv.ToolNames {
+		toolVersion, err := mkv.ToolVersion(context.Background(), tool)
+		if err != nil {
+			printVersionLine(tool, err.Error(), false)
+			continue
+		}
+		printVersionLine(tool, toolVersion, true)
+	}
+
+	configFile := config.FindConfigFile()
+	if configFile == "" {
+		configFile = i18n.T("none (built-in defaults)")
+	}
+	printVersionLine("config", configFile, true)
+	printVersionLine("runtime", fmt.Sprintf("%s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH), true)
+}
+
+// printVersionLine prints one aligned name/value line of the version report
+func printVersionLine(name, value string, ok bool) {
+	fmt.Print("  ")
+	if ok {
+		format.BaseHighlight.Print("▪")
+	} else {
+		format.ErrorColor.Print("✗")
+	}
+	format.BaseDim.Printf(" %-14s ", name)
+	if ok {
+		format.BaseFg.Println(value)
+	} else {
+		format.ErrorColor.Println(value)
+	}
+}
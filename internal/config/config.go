@@ -1,36 +1,77 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"gopkg.in/yaml.v3"
+
+	"subscalpelmkv/internal/model"
 )
 
 // Config represents the main configuration structure
 type Config struct {
-	DefaultLanguages   []string           `yaml:"default_languages"`
-	DefaultExclusions  []string           `yaml:"default_exclusions"`
-	OutputTemplate     string             `yaml:"output_template"`
-	OutputDir          string             `yaml:"output_dir"`
-	Profiles           map[string]Profile `yaml:"profiles"`
+	DefaultLanguages   []string                   `yaml:"default_languages"`
+	DefaultExclusions  []string                   `yaml:"default_exclusions"`
+	OutputTemplate     string                     `yaml:"output_template"`
+	Naming             string                     `yaml:"naming"`
+	OutputDir          string                     `yaml:"output_dir"`
+	OutputLayout       string                     `yaml:"output_layout"`
+	FieldSeparator     string                     `yaml:"field_separator"`
+	SafeNames          string                     `yaml:"safe_names"`
+	LineEndings        string                     `yaml:"line_endings"`
+	BOM                bool                       `yaml:"bom"`
+	PreHook            string                     `yaml:"pre_hook"`
+	NameCommand        string                     `yaml:"name_command"`
+	PostProcessors     []string                   `yaml:"post_processors"`
+	PostHook           string                     `yaml:"post_hook"`
+	LanguageNameLocale string                     `yaml:"language_name_locale"`
+	CleanupRules       []string                   `yaml:"cleanup_rules"`
+	Translation        model.TranslationConfig    `yaml:"translation"`
+	Notifications      []model.NotificationConfig `yaml:"notifications"`
+	Prompts            model.PromptDefaults       `yaml:"prompts"`
+	Profiles           map[string]Profile         `yaml:"profiles"`
 }
 
 // Profile represents a named configuration profile
 type Profile struct {
-	Languages      []string `yaml:"languages"`
-	Exclusions     []string `yaml:"exclusions"`
-	OutputTemplate string   `yaml:"output_template"`
-	OutputDir      string   `yaml:"output_dir"`
+	Languages          []string `yaml:"languages"`
+	Exclusions         []string `yaml:"exclusions"`
+	OutputTemplate     string   `yaml:"output_template"`
+	Naming             string   `yaml:"naming"`
+	OutputDir          string   `yaml:"output_dir"`
+	OutputLayout       string   `yaml:"output_layout"`
+	FieldSeparator     string   `yaml:"field_separator"`
+	SafeNames          string   `yaml:"safe_names"`
+	LineEndings        string   `yaml:"line_endings"`
+	BOM                bool     `yaml:"bom"`
+	PreHook            string   `yaml:"pre_hook"`
+	NameCommand        string   `yaml:"name_command"`
+	PostProcessors     []string `yaml:"post_processors"`
+	PostHook           string   `yaml:"post_hook"`
+	LanguageNameLocale string   `yaml:"language_name_locale"`
+	Match              []string `yaml:"match"` // Path patterns that select this profile automatically
 }
 
 // AppliedConfig represents the final configuration after merging defaults, config file, and CLI flags
 type AppliedConfig struct {
-	Languages      []string
-	Exclusions     []string
-	OutputTemplate string
-	OutputDir      string
+	Languages          []string
+	Exclusions         []string
+	OutputTemplate     string
+	Naming             string
+	OutputDir          string
+	OutputLayout       string
+	FieldSeparator     string
+	SafeNames          string
+	LineEndings        string
+	BOM                bool
+	PreHook            string
+	NameCommand        string
+	PostProcessors     []string
+	PostHook           string
+	LanguageNameLocale string
 }
 
 // GetDefaultConfig returns the default configuration values
@@ -118,10 +159,21 @@ func (c *Config) ApplyProfile(profileName string) (*AppliedConfig, error) {
 	}
 
 	applied := &AppliedConfig{
-		Languages:      c.DefaultLanguages,
-		Exclusions:     c.DefaultExclusions,
-		OutputTemplate: c.OutputTemplate,
-		OutputDir:      c.OutputDir,
+		Languages:          c.DefaultLanguages,
+		Exclusions:         c.DefaultExclusions,
+		OutputTemplate:     c.OutputTemplate,
+		Naming:             c.Naming,
+		OutputDir:          c.OutputDir,
+		OutputLayout:       c.OutputLayout,
+		FieldSeparator:     c.FieldSeparator,
+		SafeNames:          c.SafeNames,
+		LineEndings:        c.LineEndings,
+		BOM:                c.BOM,
+		PreHook:            c.PreHook,
+		NameCommand:        c.NameCommand,
+		PostProcessors:     c.PostProcessors,
+		PostHook:           c.PostHook,
+		LanguageNameLocale: c.LanguageNameLocale,
 	}
 
 	// Override with profile values if they're set
@@ -131,12 +183,48 @@ func (c *Config) ApplyProfile(profileName string) (*AppliedConfig, error) {
 	if len(profile.Exclusions) > 0 {
 		applied.Exclusions = profile.Exclusions
 	}
+	// A template and a naming preset both name the files, so the profile's choice replaces either
 	if profile.OutputTemplate != "" {
 		applied.OutputTemplate = profile.OutputTemplate
+		applied.Naming = ""
+	}
+	if profile.Naming != "" {
+		applied.Naming = profile.Naming
+		applied.OutputTemplate = ""
 	}
 	if profile.OutputDir != "" {
 		applied.OutputDir = profile.OutputDir
 	}
+	if profile.OutputLayout != "" {
+		applied.OutputLayout = profile.OutputLayout
+	}
+	if profile.FieldSeparator != "" {
+		applied.FieldSeparator = profile.FieldSeparator
+	}
+	if profile.SafeNames != "" {
+		applied.SafeNames = profile.SafeNames
+	}
+	if profile.LineEndings != "" {
+		applied.LineEndings = profile.LineEndings
+	}
+	if profile.BOM {
+		applied.BOM = true
+	}
+	if profile.PreHook != "" {
+		applied.PreHook = profile.PreHook
+	}
+	if profile.NameCommand != "" {
+		applied.NameCommand = profile.NameCommand
+	}
+	if len(profile.PostProcessors) > 0 {
+		applied.PostProcessors = profile.PostProcessors
+	}
+	if profile.PostHook != "" {
+		applied.PostHook = profile.PostHook
+	}
+	if profile.LanguageNameLocale != "" {
+		applied.LanguageNameLocale = profile.LanguageNameLocale
+	}
 
 	return applied, nil
 }
@@ -144,36 +232,29 @@ func (c *Config) ApplyProfile(profileName string) (*AppliedConfig, error) {
 // ApplyDefaults returns the default configuration as applied config
 func (c *Config) ApplyDefaults() *AppliedConfig {
 	return &AppliedConfig{
-		Languages:      c.DefaultLanguages,
-		Exclusions:     c.DefaultExclusions,
-		OutputTemplate: c.OutputTemplate,
-		OutputDir:      c.OutputDir,
+		Languages:          c.DefaultLanguages,
+		Exclusions:         c.DefaultExclusions,
+		OutputTemplate:     c.OutputTemplate,
+		Naming:             c.Naming,
+		OutputDir:          c.OutputDir,
+		OutputLayout:       c.OutputLayout,
+		FieldSeparator:     c.FieldSeparator,
+		SafeNames:          c.SafeNames,
+		LineEndings:        c.LineEndings,
+		BOM:                c.BOM,
+		PreHook:            c.PreHook,
+		NameCommand:        c.NameCommand,
+		PostProcessors:     c.PostProcessors,
+		PostHook:           c.PostHook,
+		LanguageNameLocale: c.LanguageNameLocale,
 	}
 }
 
-// ValidateConfig performs basic validation on the configuration
+// ValidateConfig performs basic validation on the configuration, returning the first problem found
 func ValidateConfig(config *Config) error {
-	// Validate profiles
-	for profileName, profile := range config.Profiles {
-		if profileName == "" {
-			return fmt.Errorf("profile name cannot be empty")
-		}
-		
-		// Validate language codes in profile
-		for _, lang := range profile.Languages {
-			if len(lang) != 2 && len(lang) != 3 {
-				return fmt.Errorf("invalid language code '%s' in profile '%s': must be 2 or 3 characters", lang, profileName)
-			}
-		}
+	if problems := config.problems(); len(problems) > 0 {
+		return errors.New(problems[0].Message)
 	}
-	
-	// Validate default language codes
-	for _, lang := range config.DefaultLanguages {
-		if len(lang) != 2 && len(lang) != 3 {
-			return fmt.Errorf("invalid default language code '%s': must be 2 or 3 characters", lang)
-		}
-	}
-
 	return nil
 }
 
@@ -196,19 +277,41 @@ func GetConfigLocations() []string {
 
 // CLIFlags represents the command line flags that can be overridden by config
 type CLIFlags struct {
-	Languages      []string
-	Exclusions     []string
-	OutputTemplate string
-	OutputDir      string
+	Languages          []string
+	Exclusions         []string
+	OutputTemplate     string
+	Naming             string
+	OutputDir          string
+	OutputLayout       string
+	FieldSeparator     string
+	SafeNames          string
+	LineEndings        string
+	BOM                bool
+	PreHook            string
+	NameCommand        string
+	PostProcessors     []string
+	PostHook           string
+	LanguageNameLocale string
 }
 
 // MergeWithCLI merges applied configuration with CLI flags, where CLI flags take precedence
 func (ac *AppliedConfig) MergeWithCLI(cli CLIFlags) *AppliedConfig {
 	merged := &AppliedConfig{
-		Languages:      ac.Languages,
-		Exclusions:     ac.Exclusions,
-		OutputTemplate: ac.OutputTemplate,
-		OutputDir:      ac.OutputDir,
+		Languages:          ac.Languages,
+		Exclusions:         ac.Exclusions,
+		OutputTemplate:     ac.OutputTemplate,
+		Naming:             ac.Naming,
+		OutputDir:          ac.OutputDir,
+		OutputLayout:       ac.OutputLayout,
+		FieldSeparator:     ac.FieldSeparator,
+		SafeNames:          ac.SafeNames,
+		LineEndings:        ac.LineEndings,
+		BOM:                ac.BOM,
+		PreHook:            ac.PreHook,
+		NameCommand:        ac.NameCommand,
+		PostProcessors:     ac.PostProcessors,
+		PostHook:           ac.PostHook,
+		LanguageNameLocale: ac.LanguageNameLocale,
 	}
 
 	// CLI flags override config values if they're set
@@ -220,10 +323,45 @@ func (ac *AppliedConfig) MergeWithCLI(cli CLIFlags) *AppliedConfig {
 	}
 	if cli.OutputTemplate != "" {
 		merged.OutputTemplate = cli.OutputTemplate
+		merged.Naming = ""
+	}
+	if cli.Naming != "" {
+		merged.Naming = cli.Naming
+		merged.OutputTemplate = ""
 	}
 	if cli.OutputDir != "" {
 		merged.OutputDir = cli.OutputDir
 	}
+	if cli.OutputLayout != "" {
+		merged.OutputLayout = cli.OutputLayout
+	}
+	if cli.FieldSeparator != "" {
+		merged.FieldSeparator = cli.FieldSeparator
+	}
+	if cli.SafeNames != "" {
+		merged.SafeNames = cli.SafeNames
+	}
+	if cli.LineEndings != "" {
+		merged.LineEndings = cli.LineEndings
+	}
+	if cli.BOM {
+		merged.BOM = true
+	}
+	if cli.PreHook != "" {
+		merged.PreHook = cli.PreHook
+	}
+	if cli.NameCommand != "" {
+		merged.NameCommand = cli.NameCommand
+	}
+	if len(cli.PostProcessors) > 0 {
+		merged.PostProcessors = cli.PostProcessors
+	}
+	if cli.PostHook != "" {
+		merged.PostHook = cli.PostHook
+	}
+	if cli.LanguageNameLocale != "" {
+		merged.LanguageNameLocale = cli.LanguageNameLocale
+	}
 
 	return merged
 }
\ No newline at end of file
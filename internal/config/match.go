@@ -0,0 +1,86 @@
+package config
+
+import (
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// MatchProfile returns the profile whose match patterns fit path, along with the matching pattern.
+// When several profiles match, the most specific pattern (the one with the most literal characters)
+// wins, and ties go to the profile name that sorts first. It returns empty strings when none match.
+func (c *Config) MatchProfile(path string) (string, string) {
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
+	path = filepath.ToSlash(path)
+
+	profileNames := make([]string, 0, len(c.Profiles))
+	for profileName := range c.Profiles {
+		profileNames = append(profileNames, profileName)
+	}
+	sort.Strings(profileNames)
+
+	bestProfile, bestPattern, bestScore := "", "", -1
+	for _, profileName := range profileNames {
+		for _, pattern := range c.Profiles[profileName].Match {
+			if score := patternSpecificity(pattern); score > bestScore && matchPathPattern(pattern, path) {
+				bestProfile, bestPattern, bestScore = profileName, pattern, score
+			}
+		}
+	}
+	return bestProfile, bestPattern
+}
+
+// HasProfileMatches reports whether any profile declares match patterns
+func (c *Config) HasProfileMatches() bool {
+	for _, profile := range c.Profiles {
+		if len(profile.Match) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathPattern matches a slash-separated absolute path against a glob pattern where ** spans
+// directories and *, ? stay within one path element. Matching ignores case on Windows.
+func matchPathPattern(pattern, path string) bool {
+	var expr strings.Builder
+	if runtime.GOOS == "windows" {
+		expr.WriteString("(?i)")
+	}
+	expr.WriteString("^")
+
+	runes := []rune(filepath.ToSlash(pattern))
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				if i+1 < len(runes) && runes[i+1] == '/' {
+					i++
+					expr.WriteString("(?:.*/)?") // "**/" also matches no directories at all
+				} else {
+					expr.WriteString(".*")
+				}
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+	expr.WriteString("$")
+
+	matched, err := regexp.MatchString(expr.String(), path)
+	return err == nil && matched
+}
+
+// patternSpecificity counts the literal characters of a pattern, so "**/Anime/Movies/**" outranks "**/Anime/**"
+func patternSpecificity(pattern string) int {
+	return len([]rune(strings.NewReplacer("*", "", "?", "").Replace(pattern)))
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"subscalpelmkv/internal/hook"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/notify"
+	"subscalpelmkv/internal/postprocess"
+	"subscalpelmkv/internal/translate"
+)
+
+// Problem is one configuration error, located by the YAML keys leading to the offending value
+type Problem struct {
+	Line    int // 1-based line in the config file, 0 when unknown
+	Message string
+	path    []string
+}
+
+var (
+	yamlLinePattern     = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownFieldPattern = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+)
+
+// ValidateFile parses a config file strictly and returns every problem found, with line numbers.
+// Unlike LoadConfig it reports unknown keys, which would otherwise be ignored.
+func ValidateFile(configPath string) ([]Problem, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []Problem{yamlProblem(err.Error())}, nil // Syntax errors stop parsing
+	}
+
+	var problems []Problem
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return []Problem{yamlProblem(err.Error())}, nil
+		}
+		for _, message := range typeErr.Errors {
+			problems = append(problems, yamlProblem(message))
+		}
+	}
+
+	for _, problem := range config.problems() {
+		problem.Line = lineOf(&root, problem.path)
+		problems = append(problems, problem)
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
+	return problems, nil
+}
+
+// problems runs the semantic checks shared by ValidateConfig and ValidateFile
+func (c *Config) problems() []Problem {
+	var problems []Problem
+	add := func(message string, path ...string) {
+		problems = append(problems, Problem{Message: message, path: path})
+	}
+
+	profileNames := make([]string, 0, len(c.Profiles))
+	for profileName := range c.Profiles {
+		profileNames = append(profileNames, profileName)
+	}
+	sort.Strings(profileNames)
+
+	for _, profileName := range profileNames {
+		profile := c.Profiles[profileName]
+		if profileName == "" {
+			add("profile name cannot be empty", "profiles", profileName)
+		}
+
+		// Validate language codes in profile
+		for i, lang := range profile.Languages {
+			if len(lang) != 2 && len(lang) != 3 {
+				add(fmt.Sprintf("invalid language code '%s' in profile '%s': must be 2 or 3 characters", lang, profileName), "profiles", profileName, "languages", strconv.Itoa(i))
+			}
+		}
+		if err := model.ValidateOutputLayout(profile.OutputLayout); err != nil {
+			add(fmt.Sprintf("invalid output_layout in profile '%s': %v", profileName, err), "profiles", profileName, "output_layout")
+		}
+		if err := model.ValidateFieldSeparator(profile.FieldSeparator); err != nil {
+			add(fmt.Sprintf("invalid field_separator in profile '%s': %v", profileName, err), "profiles", profileName, "field_separator")
+		}
+		if err := model.ValidateSafeNames(profile.SafeNames); err != nil {
+			add(fmt.Sprintf("invalid safe_names in profile '%s': %v", profileName, err), "profiles", profileName, "safe_names")
+		}
+		if err := model.ValidateLineEndings(profile.LineEndings); err != nil {
+			add(fmt.Sprintf("invalid line_endings in profile '%s': %v", profileName, err), "profiles", profileName, "line_endings")
+		}
+		for i, name := range profile.PostProcessors {
+			if err := postprocess.ValidateProcessorName(name); err != nil {
+				add(fmt.Sprintf("invalid post_processors in profile '%s': %v", profileName, err), "profiles", profileName, "post_processors", strconv.Itoa(i))
+			}
+		}
+		if err := model.ValidateOutputTemplate(profile.OutputTemplate); err != nil {
+			add(fmt.Sprintf("invalid output_template in profile '%s': %v", profileName, err), "profiles", profileName, "output_template")
+		}
+		if err := model.ValidateNaming(profile.Naming); err != nil {
+			add(fmt.Sprintf("invalid naming in profile '%s': %v", profileName, err), "profiles", profileName, "naming")
+		} else if profile.Naming != "" && profile.OutputTemplate != "" {
+			add(fmt.Sprintf("profile '%s' sets both naming and output_template; use one", profileName), "profiles", profileName, "naming")
+		}
+		if err := hook.ValidatePostHook(profile.PostHook); err != nil {
+			add(fmt.Sprintf("invalid post_hook in profile '%s': %v", profileName, err), "profiles", profileName, "post_hook")
+		}
+		if err := model.ValidateLanguageNameLocale(profile.LanguageNameLocale); err != nil {
+			add(fmt.Sprintf("invalid language_name_locale '%s' in profile '%s': use 'native' or a locale such as 'fr' or 'pt-BR'", profile.LanguageNameLocale, profileName), "profiles", profileName, "language_name_locale")
+		}
+	}
+
+	// Validate default language codes
+	for i, lang := range c.DefaultLanguages {
+		if len(lang) != 2 && len(lang) != 3 {
+			add(fmt.Sprintf("invalid default language code '%s': must be 2 or 3 characters", lang), "default_languages", strconv.Itoa(i))
+		}
+	}
+	if err := model.ValidateOutputLayout(c.OutputLayout); err != nil {
+		add(fmt.Sprintf("invalid output_layout: %v", err), "output_layout")
+	}
+	if err := model.ValidateFieldSeparator(c.FieldSeparator); err != nil {
+		add(fmt.Sprintf("invalid field_separator: %v", err), "field_separator")
+	}
+	if err := model.ValidateSafeNames(c.SafeNames); err != nil {
+		add(fmt.Sprintf("invalid safe_names: %v", err), "safe_names")
+	}
+	if err := model.ValidateLineEndings(c.LineEndings); err != nil {
+		add(fmt.Sprintf("invalid line_endings: %v", err), "line_endings")
+	}
+	for i, name := range c.PostProcessors {
+		if err := postprocess.ValidateProcessorName(name); err != nil {
+			add(fmt.Sprintf("invalid post_processors: %v", err), "post_processors", strconv.Itoa(i))
+		}
+	}
+	if err := model.ValidateOutputTemplate(c.OutputTemplate); err != nil {
+		add(fmt.Sprintf("invalid output_template: %v", err), "output_template")
+	}
+	if err := model.ValidateNaming(c.Naming); err != nil {
+		add(fmt.Sprintf("invalid naming: %v", err), "naming")
+	} else if c.Naming != "" && c.OutputTemplate != "" {
+		add("naming and output_template are both set; use one", "naming")
+	}
+	if err := hook.ValidatePostHook(c.PostHook); err != nil {
+		add(fmt.Sprintf("invalid post_hook: %v", err), "post_hook")
+	}
+	if err := model.ValidateLanguageNameLocale(c.LanguageNameLocale); err != nil {
+		add(fmt.Sprintf("invalid language_name_locale '%s': use 'native' or a locale such as 'fr' or 'pt-BR'", c.LanguageNameLocale), "language_name_locale")
+	}
+
+	for i, rule := range c.CleanupRules {
+		if _, err := regexp.Compile(rule); err != nil {
+			add(fmt.Sprintf("invalid cleanup rule '%s': %v", rule, err), "cleanup_rules", strconv.Itoa(i))
+		}
+	}
+
+	switch strings.ToLower(c.Translation.Provider) {
+	case "", translate.ProviderLibreTranslate, translate.ProviderDeepL:
+	default:
+		add(fmt.Sprintf("unknown translation provider '%s' (supported: %s, %s)", c.Translation.Provider, translate.ProviderLibreTranslate, translate.ProviderDeepL), "translation", "provider")
+	}
+	if c.Translation.BatchSize < 0 {
+		add("translation batch_size cannot be negative", "translation", "batch_size")
+	}
+	if c.Translation.RequestsPerMinute < 0 {
+		add("translation requests_per_minute cannot be negative", "translation", "requests_per_minute")
+	}
+
+	for i, sink := range c.Notifications {
+		if err := notify.Validate(sink); err != nil {
+			add(fmt.Sprintf("invalid notification: %v", err), "notifications", strconv.Itoa(i))
+		}
+	}
+
+	return problems
+}
+
+// yamlProblem turns a yaml.v3 error message ("yaml: line 3: ...") into a Problem
+func yamlProblem(message string) Problem {
+	match := yamlLinePattern.FindStringSubmatch(message)
+	if match == nil {
+		return Problem{Message: strings.TrimPrefix(message, "yaml: ")}
+	}
+
+	line, _ := strconv.Atoi(match[1])
+	message = match[2]
+	if field := unknownFieldPattern.FindStringSubmatch(message); field != nil {
+		message = fmt.Sprintf("unknown key '%s'", field[1])
+	}
+	return Problem{Line: line, Message: message}
+}
+
+// lineOf returns the line of the deepest node found along path; sequence elements are addressed by index
+func lineOf(root *yaml.Node, path []string) int {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	line := 0
+	for _, key := range path {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if index, err := strconv.Atoi(key); err == nil && index < len(node.Content) {
+				next = node.Content[index]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return line
+}
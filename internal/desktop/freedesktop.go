@@ -0,0 +1,158 @@
+package desktop
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Names of the files installed by InstallDesktopIntegration, relative to the data directory
+var (
+	desktopEntryFile = filepath.Join("applications", "subscalpelmkv.desktop")
+	launcherFile     = filepath.Join("subscalpelmkv", "open-in-terminal")
+	nautilusFile     = filepath.Join("nautilus", "scripts", QuickActionName)
+	dolphinFile      = filepath.Join("kio", "servicemenus", "subscalpelmkv.desktop")
+)
+
+// mimeTypes are the file types the desktop entry and the Dolphin action accept: Matroska, WebM and
+// directories of them
+const mimeTypes = "video/x-matroska;video/webm;inode/directory;"
+
+// desktopEntry lists the program under "Open With" for MKV files. Terminal=true has the desktop
+// environment run it in the user's terminal.
+const desktopEntry = `[Desktop Entry]
+Type=Application
+Name=SubScalpelMKV
+GenericName=Subtitle Extractor
+Comment=Extract subtitle tracks from MKV files
+Exec=%s %%F
+Terminal=true
+MimeType=` + mimeTypes + `
+Categories=AudioVideo;Video;
+`
+
+// launcherScript opens the program in a terminal window for the file manager actions, which unlike
+// desktop entries have no Terminal key. It tries $TERMINAL, the system's default terminal and the
+// terminals of the common desktops.
+const launcherScript = `#!/bin/sh
+# Opens SubScalpelMKV in a terminal window with the given files
+program=%s
+for terminal in "$TERMINAL" x-terminal-emulator gnome-terminal konsole xfce4-terminal mate-terminal kitty alacritty foot xterm; do
+	[ -n "$terminal" ] && command -v "$terminal" >/dev/null 2>&1 || continue
+	case "$terminal" in
+	gnome-terminal) exec gnome-terminal -- "$program" "$@" ;;
+	xfce4-terminal | mate-terminal) exec "$terminal" -x "$program" "$@" ;;
+	kitty | foot) exec "$terminal" "$program" "$@" ;;
+	*) exec "$terminal" -e "$program" "$@" ;;
+	esac
+done
+echo "No terminal emulator found; set TERMINAL to the one to use" >&2
+exit 1
+`
+
+// nautilusScript is the entry of Nautilus' Scripts menu, which passes the selected files as arguments
+const nautilusScript = `#!/bin/sh
+exec %s "$@"
+`
+
+// dolphinServiceMenu adds the action to Dolphin's context menu for MKV files and folders
+const dolphinServiceMenu = `[Desktop Entry]
+Type=Service
+MimeType=` + mimeTypes + `
+Actions=extractSubtitles
+X-KDE-ServiceTypes=KonqPopupMenu/Plugin
+
+[Desktop Action extractSubtitles]
+Name=` + QuickActionName + `
+Icon=video-x-matroska
+Exec=%s %%F
+`
+
+// DataDir returns the user's XDG data directory, where desktop entries and file manager actions
+// are installed
+func DataDir() (string, error) {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dataHome) {
+		return dataHome, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// InstallDesktopIntegration writes a desktop entry for executable, a Nautilus script and a Dolphin
+// service menu into dataDir, replacing earlier installations. It returns the paths it wrote.
+func InstallDesktopIntegration(dataDir, executable string) ([]string, error) {
+	launcher := filepath.Join(dataDir, launcherFile)
+	files := []struct {
+		name    string
+		content string
+		mode    os.FileMode
+	}{
+		{desktopEntryFile, fmt.Sprintf(desktopEntry, desktopExecQuote(executable)), 0644},
+		{launcherFile, fmt.Sprintf(launcherScript, shellQuote(executable)), 0755},
+		{nautilusFile, fmt.Sprintf(nautilusScript, shellQuote(launcher)), 0755},
+		// Dolphin only runs service menus that are executable
+		{dolphinFile, fmt.Sprintf(dolphinServiceMenu, desktopExecQuote(launcher)), 0755},
+	}
+
+	var written []string
+	for _, file := range files {
+		path := filepath.Join(dataDir, file.name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return written, fmt.Errorf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(file.content), file.mode); err != nil {
+			return written, fmt.Errorf("failed to write %s: %v", path, err)
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := os.Chmod(path, file.mode); err != nil {
+			return written, fmt.Errorf("failed to make %s executable: %v", path, err)
+		}
+		written = append(written, path)
+	}
+
+	// Refresh the "Open With" cache where the tool is installed; desktops rescan on their own otherwise
+	if tool, err := exec.LookPath("update-desktop-database"); err == nil {
+		_ = exec.Command(tool, filepath.Dir(filepath.Join(dataDir, desktopEntryFile))).Run()
+	}
+	return written, nil
+}
+
+// UninstallDesktopIntegration removes the files written by InstallDesktopIntegration from dataDir,
+// returning the paths it removed
+func UninstallDesktopIntegration(dataDir string) ([]string, error) {
+	var removed []string
+	for _, name := range []string{desktopEntryFile, launcherFile, nautilusFile, dolphinFile} {
+		path := filepath.Join(dataDir, name)
+		if !fileExists(path) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %v", path, err)
+		}
+		removed = append(removed, path)
+	}
+	// The launcher's own directory holds nothing else
+	_ = os.Remove(filepath.Dir(filepath.Join(dataDir, launcherFile)))
+	return removed, nil
+}
+
+// shellQuote quotes a path for a POSIX shell
+func shellQuote(path string) string {
+	return "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+}
+
+// desktopExecEscaper escapes the characters that need a backslash within a quoted Exec argument
+var desktopExecEscaper = strings.NewReplacer(`"`, `\"`, "`", "\\`", `$`, `\$`, `\`, `\\`)
+
+// desktopExecQuote quotes a path as an argument of a desktop entry's Exec key. The key's value is
+// also a string with escapes of its own, so backslashes are doubled again, and % is doubled for the
+// field codes.
+func desktopExecQuote(path string) string {
+	quoted := `"` + desktopExecEscaper.Replace(path) + `"`
+	return strings.ReplaceAll(strings.ReplaceAll(quoted, `\`, `\\`), "%", "%%")
+}
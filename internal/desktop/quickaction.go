@@ -0,0 +1,264 @@
+package desktop
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// QuickActionName is the Finder menu entry of the Quick Action
+const QuickActionName = "Extract Subtitles with SubScalpelMKV"
+
+// quickActionScript opens a Terminal window running the executable on the selected files, as
+// dropping them onto it would. AppleScript's quoted form escapes the paths for the shell.
+const quickActionScript = `osascript - "$@" <<'APPLESCRIPT'
+on run argv
+	set command to quoted form of "%s"
+	repeat with selectedFile in argv
+		set command to command & " " & quoted form of (selectedFile as text)
+	end repeat
+	tell application "Terminal"
+		activate
+		do script command
+	end tell
+end run
+APPLESCRIPT
+`
+
+// quickActionInfo registers the workflow as a Finder service for movies and folders, so MKV files
+// and directories of them can be sent to it
+const quickActionInfo = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>NSServices</key>
+	<array>
+		<dict>
+			<key>NSMenuItem</key>
+			<dict>
+				<key>default</key>
+				<string>%s</string>
+			</dict>
+			<key>NSMessage</key>
+			<string>runWorkflowAsService</string>
+			<key>NSRequiredContext</key>
+			<dict>
+				<key>NSApplicationIdentifier</key>
+				<string>com.apple.finder</string>
+			</dict>
+			<key>NSSendFileTypes</key>
+			<array>
+				<string>public.movie</string>
+				<string>public.folder</string>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>
+`
+
+// quickActionDocument is the Automator workflow: a single Run Shell Script action receiving the
+// selected files as arguments
+const quickActionDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>AMApplicationBuild</key>
+	<string>523</string>
+	<key>AMApplicationVersion</key>
+	<string>2.10</string>
+	<key>AMDocumentVersion</key>
+	<string>2</string>
+	<key>actions</key>
+	<array>
+		<dict>
+			<key>action</key>
+			<dict>
+				<key>AMAccepts</key>
+				<dict>
+					<key>Container</key>
+					<string>List</string>
+					<key>Optional</key>
+					<true/>
+					<key>Types</key>
+					<array>
+						<string>com.apple.cocoa.string</string>
+					</array>
+				</dict>
+				<key>AMActionVersion</key>
+				<string>2.0.3</string>
+				<key>AMApplication</key>
+				<array>
+					<string>Automator</string>
+				</array>
+				<key>AMParameterProperties</key>
+				<dict>
+					<key>COMMAND_STRING</key>
+					<dict/>
+					<key>CheckedForUserDefaultShell</key>
+					<dict/>
+					<key>inputMethod</key>
+					<dict/>
+					<key>shell</key>
+					<dict/>
+					<key>source</key>
+					<dict/>
+				</dict>
+				<key>AMProvides</key>
+				<dict>
+					<key>Container</key>
+					<string>List</string>
+					<key>Types</key>
+					<array>
+						<string>com.apple.cocoa.string</string>
+					</array>
+				</dict>
+				<key>ActionBundlePath</key>
+				<string>/System/Library/Automator/Run Shell Script.action</string>
+				<key>ActionName</key>
+				<string>Run Shell Script</string>
+				<key>ActionParameters</key>
+				<dict>
+					<key>COMMAND_STRING</key>
+					<string>%s</string>
+					<key>CheckedForUserDefaultShell</key>
+					<true/>
+					<key>inputMethod</key>
+					<integer>1</integer>
+					<key>shell</key>
+					<string>/bin/bash</string>
+					<key>source</key>
+					<string></string>
+				</dict>
+				<key>BundleIdentifier</key>
+				<string>com.apple.RunShellScript</string>
+				<key>CFBundleVersion</key>
+				<string>2.0.3</string>
+				<key>CanShowSelectedItemsWhenRun</key>
+				<false/>
+				<key>CanShowWhenRun</key>
+				<true/>
+				<key>Category</key>
+				<array>
+					<string>AMCategoryUtilities</string>
+				</array>
+				<key>Class Name</key>
+				<string>RunShellScriptAction</string>
+				<key>InputUUID</key>
+				<string>5E1C0A1B-3F4D-4C6E-9A57-2B8D1E0F6A31</string>
+				<key>Keywords</key>
+				<array>
+					<string>Shell</string>
+					<string>Script</string>
+				</array>
+				<key>OutputUUID</key>
+				<string>8C2F4D6E-1A3B-4E5C-8D7F-9B0A2C4E6F13</string>
+				<key>UUID</key>
+				<string>3A7E9C1D-5B2F-4A6E-8C0D-1F3B5D7A9E24</string>
+				<key>UnlocalizedApplications</key>
+				<array>
+					<string>Automator</string>
+				</array>
+				<key>arguments</key>
+				<dict/>
+				<key>isViewVisible</key>
+				<integer>1</integer>
+			</dict>
+		</dict>
+	</array>
+	<key>connectors</key>
+	<dict/>
+	<key>workflowMetaData</key>
+	<dict>
+		<key>serviceApplicationBundleID</key>
+		<string>com.apple.finder</string>
+		<key>serviceApplicationPath</key>
+		<string>/System/Library/CoreServices/Finder.app</string>
+		<key>serviceInputTypeIdentifier</key>
+		<string>com.apple.Automator.fileSystemObject</string>
+		<key>serviceOutputTypeIdentifier</key>
+		<string>com.apple.Automator.nothing</string>
+		<key>serviceProcessesInput</key>
+		<integer>0</integer>
+		<key>workflowTypeIdentifier</key>
+		<string>com.apple.Automator.servicesMenu</string>
+	</dict>
+</dict>
+</plist>
+`
+
+// ServicesDir returns the user's macOS Services folder, where Quick Actions are installed
+func ServicesDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "Services"), nil
+}
+
+// QuickActionPath returns the path of the Quick Action's workflow bundle in servicesDir
+func QuickActionPath(servicesDir string) string {
+	return filepath.Join(servicesDir, QuickActionName+".workflow")
+}
+
+// InstallQuickAction writes a Finder Quick Action into servicesDir that opens the selected files with
+// executable in Terminal, replacing an earlier installation. It returns the workflow's path.
+func InstallQuickAction(servicesDir, executable string) (string, error) {
+	workflowPath := QuickActionPath(servicesDir)
+	contentsDir := filepath.Join(workflowPath, "Contents")
+	if err := os.RemoveAll(workflowPath); err != nil {
+		return "", fmt.Errorf("failed to remove the existing Quick Action: %v", err)
+	}
+	if err := os.MkdirAll(contentsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %v", contentsDir, err)
+	}
+
+	script := fmt.Sprintf(quickActionScript, appleScriptEscaper.Replace(executable))
+	files := map[string]string{
+		"Info.plist":     fmt.Sprintf(quickActionInfo, xmlEscape(QuickActionName)),
+		"document.wflow": fmt.Sprintf(quickActionDocument, xmlEscape(script)),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(contentsDir, name), []byte(content), 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %v", name, err)
+		}
+	}
+
+	// Let the services menu pick up the new entry without logging out; it does so on its own otherwise
+	if pbs := "/System/Library/CoreServices/pbs"; fileExists(pbs) {
+		_ = exec.Command(pbs, "-update").Run()
+	}
+	return workflowPath, nil
+}
+
+// UninstallQuickAction removes the Quick Action from servicesDir, reporting whether it was installed
+func UninstallQuickAction(servicesDir string) (bool, error) {
+	workflowPath := QuickActionPath(servicesDir)
+	if !fileExists(workflowPath) {
+		return false, nil
+	}
+	if err := os.RemoveAll(workflowPath); err != nil {
+		return false, fmt.Errorf("failed to remove %s: %v", workflowPath, err)
+	}
+	return true, nil
+}
+
+// appleScriptEscaper escapes text for an AppleScript string literal
+var appleScriptEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// xmlEscape escapes text for a property list string
+func xmlEscape(text string) string {
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
+
+// fileExists checks if a path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
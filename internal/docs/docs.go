@@ -0,0 +1,298 @@
+package docs
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"subscalpelmkv/internal/model"
+)
+
+// Option groups, set with the group tag; untagged options belong to OptionGroupOutput
+const (
+	OptionGroupSelection = "selection"
+	OptionGroupOutput    = "output"
+)
+
+// Option describes one command line flag as declared in the gocmd flags struct
+type Option struct {
+	Short       string
+	Long        string
+	Value       string // Value placeholder such as "<file>" or "[dir]"; empty for switches
+	Group       string
+	Description string
+}
+
+// HelpOption documents --help, which is handled before gocmd parses the flags
+var HelpOption = Option{Short: "h", Long: "help", Group: OptionGroupOutput, Description: "Show this help message"}
+
+// Command describes a subcommand
+type Command struct {
+	Usage       string
+	Description string
+}
+
+// Synopsis lists the invocation forms, without the program name
+var Synopsis = []string{
+	"[OPTIONS] <file>",
+	"-x <file> [selection options] [output options]",
+	"-b <pattern> [selection options] [output options]",
+	"-i <file>",
+	"history [--since <date|duration>] [--prune <date|duration>]",
+	"config validate [file]",
+	"docs --man|--markdown",
+}
+
+// Commands lists the subcommands dispatched before flag parsing
+var Commands = []Command{
+	{"history [--since <value>] [--prune <value>]", "List processed files from the processing history. --since accepts a date (2024-01-31) or a duration (36h, 7d, 2w). --prune removes the entries processed before such a date or duration instead."},
+	{"config validate [file]", "Check the configuration file (the one in use unless a file is given) for syntax errors, unknown keys and invalid values, with line numbers, and check that mkvmerge and mkvextract are installed."},
+	{"cleanup [--dry-run] <file>...", "Remove advertising and credit cues from existing SRT and ASS/SSA files using cleanup_rules from the configuration, or built-in rules. --dry-run lists the cues that would be removed without changing the files."},
+	{"audit <dir>... --require <langs> [--format table|csv|json] [--all]", "Scan a library and report the files lacking subtitle tracks, or sidecar subtitle files, in the required languages. --all lists complete files too."},
+	{"stats <dir>... [--format table|json]", "Summarize the subtitle coverage of a library: tracks per language and per format, files without any subtitles and files with only image-based subtitles."},
+	{"install-quick-action [--uninstall]", "Add a Finder Quick Action on macOS that opens the selected MKV files or folders in Terminal for interactive extraction. --uninstall removes it."},
+	{"install-desktop [--uninstall]", "Add a desktop entry and Nautilus and Dolphin actions on Linux that open the selected MKV files or folders in a terminal for interactive extraction. --uninstall removes them."},
+	{"docs --man|--markdown", "Print a man page or Markdown reference generated from the option definitions."},
+}
+
+// templatePlaceholders documents the filename template placeholders in display order
+var templatePlaceholders = [][2]string{
+	{"{basename}", "Input file name without extension"},
+	{"{language}", "Language code (e.g., eng, spa)"},
+	{"{languagename}", "Full language name (English unless --lang-name-locale is set)"},
+	{"{trackno}", "Track number (e.g., 003)"},
+	{"{trackname}", "Track name, if set"},
+	{"{forced}", "\"forced\" for forced tracks"},
+	{"{default}", "\"default\" for default tracks"},
+	{"{extension}", "Subtitle file extension (e.g., srt, ass, sup)"},
+	{"{date}", "Run start date (e.g., 2024-01-31)"},
+	{"{time}", "Run start time (e.g., 14-05-09)"},
+	{"{timestamp}", "Run start date and time (e.g., 20240131-140509)"},
+	{"{hash}", "Short hash of the track UID, or of the source file when the track has none; {hash:12} sets the length (default 8)"},
+	{"{chapter}", "Chapter number with --split-by-chapters (e.g., 01)"},
+}
+
+// templateModifierNote explains the placeholder modifiers after the placeholder list
+const templateModifierNote = "Append :lower, :upper or :slug to a placeholder to lowercase, uppercase or slugify its value, e.g. {basename:slug}."
+
+// OptionsFromFlags reads the options from a gocmd flags struct (or a pointer to one) using its
+// short, long, value, group and description tags. Non-bool fields without a value tag take "<value>".
+func OptionsFromFlags(flags interface{}) []Option {
+	t := reflect.TypeOf(flags)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var options []Option
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		long := field.Tag.Get("long")
+		if long == "" {
+			continue
+		}
+		value := field.Tag.Get("value")
+		if value == "" && field.Type.Kind() != reflect.Bool {
+			value = "<value>"
+		}
+		group := field.Tag.Get("group")
+		if group == "" {
+			group = OptionGroupOutput
+		}
+		options = append(options, Option{
+			Short:       field.Tag.Get("short"),
+			Long:        long,
+			Value:       value,
+			Group:       group,
+			Description: field.Tag.Get("description"),
+		})
+	}
+	return options
+}
+
+// selectorFormats returns the distinct subtitle formats usable in selections and exclusions
+func selectorFormats() []string {
+	seen := make(map[string]bool)
+	var formats []string
+	for _, ext := range model.SubtitleExtensionByCodec {
+		if !seen[ext] {
+			seen[ext] = true
+			formats = append(formats, ext)
+		}
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Selectors describes the values accepted by --select and --exclude
+func Selectors() [][2]string {
+	return [][2]string{
+		{"Language codes", "2-letter (en, es, fr) or 3-letter (eng, spa, fre) ISO 639 codes"},
+		{"Track IDs", "Track numbers as shown by --info (e.g., 14,16) or inclusive ranges (e.g., 3-7)"},
+		{"Formats", strings.Join(selectorFormats(), ", ")},
+		{"Status", strings.Join(model.TrackStatusFilters, ", ")},
+	}
+}
+
+// files lists the files read or written, described so they hold on every platform
+var files = [][2]string{
+	{"./subscalpelmkv.yaml", "Configuration in the current directory (highest priority)"},
+	{"<user config dir>/subscalpelmkv/config.yaml", "Configuration in the user config directory (~/.config on Linux, ~/Library/Application Support on macOS, %AppData% on Windows)"},
+	{"~/.subscalpelmkv.yaml", "Configuration in the home directory"},
+	{"<user config dir>/subscalpelmkv/history.jsonl", "Processing history"},
+}
+
+// WrapText splits text into lines of at most width characters, breaking at spaces
+func WrapText(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Man renders the documentation as a roff man page in section 1
+func Man(options []Option, version string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ".TH SUBSCALPELMKV 1 \"\" \"subscalpelmkv %s\" \"User Commands\"\n", manEscape(version))
+	b.WriteString(".SH NAME\nsubscalpelmkv \\- extract subtitle tracks from MKV and WebM files\n")
+
+	b.WriteString(".SH SYNOPSIS\n")
+	for i, usage := range Synopsis {
+		if i > 0 {
+			b.WriteString(".br\n")
+		}
+		fmt.Fprintf(&b, ".B subscalpelmkv\n%s\n", manEscape(usage))
+	}
+
+	b.WriteString(".SH DESCRIPTION\n")
+	b.WriteString("Extracts subtitle tracks from Matroska files using MKVToolNix. " +
+		"Tracks can be selected or excluded by language, track ID, format and status. " +
+		"Passing files or directories without options starts the interactive drag\\-and\\-drop mode.\n")
+
+	b.WriteString(".SH OPTIONS\n")
+	for _, option := range options {
+		fmt.Fprintf(&b, ".TP\n%s\n%s\n", manOptionName(option), manEscape(option.Description))
+	}
+
+	b.WriteString(".SH COMMANDS\n")
+	for _, command := range Commands {
+		fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", manEscape(command.Usage), manEscape(command.Description))
+	}
+
+	b.WriteString(".SH SELECTORS\n")
+	b.WriteString("\\-\\-select and \\-\\-exclude take a comma\\-separated mix of:\n")
+	for _, selector := range Selectors() {
+		fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", manEscape(selector[0]), manEscape(selector[1]))
+	}
+
+	b.WriteString(".SH FILENAME TEMPLATE\n")
+	fmt.Fprintf(&b, "The default template is\n.B %s\n.PP\nPlaceholders:\n", manEscape(model.DefaultOutputTemplate))
+	for _, placeholder := range templatePlaceholders {
+		fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", manEscape(placeholder[0]), manEscape(placeholder[1]))
+	}
+	fmt.Fprintf(&b, ".PP\n%s\n", manEscape(templateModifierNote))
+
+	b.WriteString(".SH FILES\n")
+	for _, file := range files {
+		fmt.Fprintf(&b, ".TP\n.I %s\n%s\n", manEscape(file[0]), manEscape(file[1]))
+	}
+
+	b.WriteString(".SH ENVIRONMENT\n")
+	b.WriteString(".TP\n.B LC_ALL, LC_MESSAGES, LANG\nInterface language when \\-\\-lang is not given\n")
+
+	b.WriteString(".SH SEE ALSO\n.BR mkvmerge (1),\n.BR mkvextract (1)\n")
+	return b.String()
+}
+
+// Markdown renders the documentation as a Markdown reference page
+func Markdown(options []Option, version string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# subscalpelmkv %s\n\n", version)
+	b.WriteString("Extract subtitle tracks from MKV and WebM files.\n\n")
+
+	b.WriteString("## Synopsis\n\n```\n")
+	for _, usage := range Synopsis {
+		fmt.Fprintf(&b, "subscalpelmkv %s\n", usage)
+	}
+	b.WriteString("```\n\n")
+
+	b.WriteString("## Options\n\n| Option | Short | Description |\n|--------|-------|-------------|\n")
+	for _, option := range options {
+		short := ""
+		if option.Short != "" {
+			short = fmt.Sprintf("`-%s`", option.Short)
+		}
+		long := "`--" + option.Long
+		if option.Value != "" {
+			long += " " + option.Value
+		}
+		long += "`"
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", long, short, markdownEscape(option.Description))
+	}
+
+	b.WriteString("\n## Commands\n\n| Command | Description |\n|---------|-------------|\n")
+	for _, command := range Commands {
+		fmt.Fprintf(&b, "| `%s` | %s |\n", command.Usage, markdownEscape(command.Description))
+	}
+
+	b.WriteString("\n## Selectors\n\n`--select` and `--exclude` take a comma-separated mix of:\n\n")
+	for _, selector := range Selectors() {
+		fmt.Fprintf(&b, "- **%s**: %s\n", selector[0], selector[1])
+	}
+
+	fmt.Fprintf(&b, "\n## Filename Template\n\nDefault: `%s`\n\n", model.DefaultOutputTemplate)
+	b.WriteString("| Placeholder | Description |\n|-------------|-------------|\n")
+	for _, placeholder := range templatePlaceholders {
+		fmt.Fprintf(&b, "| `%s` | %s |\n", placeholder[0], markdownEscape(placeholder[1]))
+	}
+	fmt.Fprintf(&b, "\n%s\n", templateModifierNote)
+
+	b.WriteString("\n## Files\n\n")
+	for _, file := range files {
+		fmt.Fprintf(&b, "- `%s`: %s\n", file[0], file[1])
+	}
+
+	b.WriteString("\n## Environment\n\n- `LC_ALL`, `LC_MESSAGES`, `LANG`: interface language when `--lang` is not given\n")
+	return b.String()
+}
+
+// manOptionName renders an option's flags in bold with its value in italics, e.g. "\fB\-x\fR, \fB\-\-extract\fR \fI<file>\fR"
+func manOptionName(option Option) string {
+	name := fmt.Sprintf("\\fB\\-\\-%s\\fR", manEscape(option.Long))
+	if option.Short != "" {
+		name = fmt.Sprintf("\\fB\\-%s\\fR, %s", manEscape(option.Short), name)
+	}
+	if option.Value != "" {
+		name += fmt.Sprintf(" \\fI%s\\fR", manEscape(option.Value))
+	}
+	return name
+}
+
+// manEscape escapes backslashes and hyphens and protects lines that would start a roff request
+func manEscape(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\e")
+	text = strings.ReplaceAll(text, "-", "\\-")
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = "\\&" + text
+	}
+	return text
+}
+
+// markdownEscape keeps table cells intact by escaping pipes
+func markdownEscape(text string) string {
+	return strings.ReplaceAll(text, "|", "\\|")
+}
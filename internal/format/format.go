@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+
+	"subscalpelmkv/internal/i18n"
 )
 
 // Custom RGB color helper function
@@ -45,8 +47,9 @@ var (
 	CodecColor       = BaseDim                              // Dimmed for codec info
 
 	// Track attribute colors - modern style
-	ForcedAttribute  = WarningColor                         // Use warning color for forced
-	DefaultAttribute = SuccessColor                         // Use success color for default
+	ForcedAttribute   = WarningColor                        // Use warning color for forced
+	DefaultAttribute  = SuccessColor                        // Use success color for default
+	DisabledAttribute = ErrorColor                          // Use error color for disabled
 
 	// Progress colors - modern gradient effect
 	ProgressFg   = NewRGBColor(100, 180, 240)              // Bright blue
@@ -63,10 +66,20 @@ var (
 // PrintTitleWithVersion prints the main application title with version number
 func PrintTitleWithVersion(version string) {
 	titleWidth := 30 // Fixed width for title box
+
+	// Middle line, measured first so long translations can widen the box
+	subtitle := i18n.T("Extract MKV Subtitles")
+	if version != "" {
+		subtitle = i18n.T("Extract MKV Subtitles v%s", version)
+	}
+	subtitleLen := DisplayWidth(subtitle)
+	if subtitleLen+2 > titleWidth {
+		titleWidth = subtitleLen + 2 // Widen the box for long translations
+	}
 	
 	// Top border with title
 	title := "SubScalpelMKV"
-	titleLen := len(title)
+	titleLen := DisplayWidth(title)
 	dashesBeforeTitle := 1
 	dashesAfterTitle := titleWidth - titleLen - dashesBeforeTitle - 2 // -2 for spaces around title
 	
@@ -80,11 +93,6 @@ func PrintTitleWithVersion(version string) {
 	BaseAccent.Println("┐")
 	
 	// Middle line
-	subtitle := "Extract MKV Subtitles"
-	if version != "" {
-		subtitle = fmt.Sprintf("Extract MKV Subtitles v%s", version)
-	}
-	subtitleLen := len(subtitle)
 	padding := titleWidth - subtitleLen - 2 // -2 for "│ " at start
 	
 	BaseAccent.Print("│ ")
@@ -98,14 +106,11 @@ func PrintTitleWithVersion(version string) {
 	BaseAccent.Println("┘")
 }
 
-// Box width constant for consistent sizing
-const BoxWidth = 60
-
 // PrintSection prints a section header with modern box drawing
 func PrintSection(title string) {
 	fmt.Println()
 	titlePadded := fmt.Sprintf(" %s ", title)
-	titleLen := len(titlePadded)
+	titleLen := DisplayWidth(titlePadded)
 	leftPad := (BoxWidth - titleLen) / 2
 	rightPad := BoxWidth - titleLen - leftPad
 	
@@ -152,13 +157,13 @@ func PrintStep(step int, message string) {
 	fmt.Print("  ")
 	InfoColor.Print("►")
 	fmt.Print(" ")
-	BaseDim.Printf("Step %d:", step)
+	BaseDim.Print(i18n.T("Step %d:", step))
 	fmt.Print(" ")
 	BaseFg.Println(message)
 }
 
 // PrintTrackInfoWithLanguageName prints formatted track information with full language name
-func PrintTrackInfoWithLanguageName(trackNum int, language, languageName, trackName, codecType string, forced, defaultTrack bool) {
+func PrintTrackInfoWithLanguageName(trackNum int, language, languageName, trackName, codecType string, forced, defaultTrack, disabled bool) {
 	// Use white for the track indicator
 	trackColor := BaseHighlight
 
@@ -167,26 +172,29 @@ func PrintTrackInfoWithLanguageName(trackNum int, language, languageName, trackN
 	BorderColor.Print("│ ")
 	trackColor.Print("▪")
 	fmt.Print(" ")
-	BaseFg.Print("Track ")
+	trackLabel := i18n.T("Track") + " "
+	BaseFg.Print(trackLabel)
 	BaseHighlight.Print(trackNum)
 	BaseDim.Print(" • ")
 	BaseFg.Print(language)
 	
 	// Calculate visible content length for first line
-	contentLen := 2 + 2 + 6 + len(fmt.Sprint(trackNum)) + 3 + len(language) // "│ " + "▪ " + "Track " + num + " • " + lang
+	contentLen := 2 + 2 + DisplayWidth(trackLabel) + len(fmt.Sprint(trackNum)) + 3 + len(language) // "│ " + "▪ " + "Track " + num + " • " + lang
 	
 	// Add full language name if provided
 	if languageName != "" && languageName != language {
 		BaseDim.Print(" (")
 		BaseAccent.Print(languageName)
 		BaseDim.Print(")")
-		contentLen += 3 + len(languageName) // " (" + name + ")"
+		contentLen += 3 + DisplayWidth(languageName) // " (" + name + ")"
 	}
 	
 	if trackName != "" {
+		// Shorten long names so the line fits the box on narrow terminals
+		trackName = Truncate(trackName, BoxWidth-contentLen-3)
 		BaseDim.Print(" • ")
 		BaseAccent.Print(trackName)
-		contentLen += 3 + len(trackName)
+		contentLen += 3 + DisplayWidth(trackName)
 	}
 	
 	// Add padding and close the line
@@ -197,10 +205,19 @@ func PrintTrackInfoWithLanguageName(trackNum int, language, languageName, trackN
 	BorderColor.Println(" │")
 	
 	// Second line: Attributes (if any)
-	if forced || defaultTrack || codecType != "" {
+	if forced || defaultTrack || disabled || codecType != "" {
 		BorderColor.Print("│   ")
 		attrLen := 3 // "│   "
 		
+		if disabled {
+			DisabledAttribute.Print("◌ DISABLED")
+			attrLen += 10
+			if defaultTrack || forced || codecType != "" {
+				fmt.Print("  ")
+				attrLen += 2
+			}
+		}
+		
 		if defaultTrack {
 			DefaultAttribute.Print("◉ DEFAULT")
 			attrLen += 9
@@ -221,7 +238,7 @@ func PrintTrackInfoWithLanguageName(trackNum int, language, languageName, trackN
 		
 		if codecType != "" {
 			CodecColor.Print(codecType)
-			attrLen += len(codecType)
+			attrLen += DisplayWidth(codecType)
 		}
 		
 		// Add padding and close the line
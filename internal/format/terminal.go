@@ -0,0 +1,97 @@
+package format
+
+import (
+	"os"
+	"strconv"
+	"unicode"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+	"golang.org/x/text/width"
+)
+
+// Box width bounds, in columns between the borders
+const (
+	DefaultBoxWidth = 60  // Used when the terminal width cannot be detected
+	MinBoxWidth     = 50  // Narrower terminals wrap rather than squeezing the layout further
+	MaxBoxWidth     = 100 // Keeps lines readable on very wide terminals
+)
+
+// BoxWidth is the inner width of boxes, sized to the terminal when stdout is one
+var BoxWidth = detectBoxWidth()
+
+// detectBoxWidth sizes boxes from the terminal width, falling back to $COLUMNS and then DefaultBoxWidth
+func detectBoxWidth() int {
+	columns := 0
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		columns = width
+	} else if value, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil {
+		columns = value
+	}
+	if columns <= 0 {
+		return DefaultBoxWidth
+	}
+
+	width := columns - 2 // Borders
+	if width < MinBoxWidth {
+		return MinBoxWidth
+	}
+	if width > MaxBoxWidth {
+		return MaxBoxWidth
+	}
+	return width
+}
+
+// RuneWidth returns the number of terminal columns a rune occupies: 2 for East Asian wide and
+// fullwidth characters (CJK, most emoji), 0 for combining marks and zero-width characters, otherwise 1
+func RuneWidth(r rune) int {
+	if r == 0x200B || r == 0x200D || r == 0xFE0F || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// DisplayWidth returns the number of terminal columns text occupies
+func DisplayWidth(text string) int {
+	columns := 0
+	for _, r := range text {
+		columns += RuneWidth(r)
+	}
+	return columns
+}
+
+// Truncate shortens text to at most width columns, marking the cut with an ellipsis
+func Truncate(text string, width int) string {
+	if DisplayWidth(text) <= width {
+		return text
+	}
+	limit := width - 1 // Room for the ellipsis
+	if width <= 1 {
+		limit = max(width, 0)
+	}
+
+	columns := 0
+	for i, r := range text {
+		columns += RuneWidth(r)
+		if columns > limit {
+			if width <= 1 {
+				return text[:i]
+			}
+			return text[:i] + "…"
+		}
+	}
+	return text
+}
+
+// RedirectToStderr sends everything printed for the user to stderr, keeping stdout free for data
+// such as an extracted subtitle. It returns the original stdout.
+func RedirectToStderr() *os.File {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	color.Output = color.Error
+	return stdout
+}
@@ -0,0 +1,232 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"subscalpelmkv/internal/model"
+)
+
+// Entry records one successfully processed MKV file
+type Entry struct {
+	Path        string    `json:"path"`
+	ModTime     time.Time `json:"mtime"`
+	Size        int64     `json:"size"`
+	Tracks      []int     `json:"tracks"`
+	Outputs     []string  `json:"outputs"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// DefaultPath returns the location of the history file, stored next to the user config
+func DefaultPath() string {
+	if configDir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(configDir, "subscalpelmkv", "history.jsonl")
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(homeDir, ".subscalpelmkv_history.jsonl")
+	}
+	return "subscalpelmkv_history.jsonl"
+}
+
+// NewEntry builds a history entry for an input file and the extraction jobs run on it
+func NewEntry(inputFileName string, jobs []model.ExtractionJob) (Entry, error) {
+	absPath, err := filepath.Abs(inputFileName)
+	if err != nil {
+		return Entry{}, err
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{
+		Path:        absPath,
+		ModTime:     info.ModTime(),
+		Size:        info.Size(),
+		ProcessedAt: time.Now(),
+	}
+	for _, job := range jobs {
+		entry.Tracks = append(entry.Tracks, job.OriginalTrack.Properties.Number)
+		entry.Outputs = append(entry.Outputs, job.OutFileName)
+	}
+	return entry, nil
+}
+
+// Record appends an entry to the history file, creating it if needed
+func Record(entry Entry) error {
+	path := DefaultPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %v", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to encode history entry: %v", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write history file: %v", err)
+	}
+	return file.Close()
+}
+
+// Load reads all entries from the history file in the order they were recorded.
+// A missing history file yields no entries; malformed lines are skipped.
+func Load() ([]Entry, error) {
+	file, err := os.Open(DefaultPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %v", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %v", err)
+	}
+	return entries, nil
+}
+
+// Prune removes the entries processed before the given time from the history file and returns how
+// many were removed. The file is replaced in one step, so a failed prune leaves it as it was.
+func Prune(before time.Time) (int, error) {
+	entries, err := Load()
+	if err != nil || len(entries) == 0 {
+		return 0, err
+	}
+	kept := Since(entries, before)
+	if len(kept) == len(entries) {
+		return 0, nil
+	}
+
+	path := DefaultPath()
+	file, err := os.CreateTemp(filepath.Dir(path), "history-*.jsonl")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create history file: %v", err)
+	}
+	defer os.Remove(file.Name())
+
+	writer := bufio.NewWriter(file)
+	for _, entry := range kept {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			file.Close()
+			return 0, fmt.Errorf("failed to encode history entry: %v", err)
+		}
+		writer.Write(append(data, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return 0, fmt.Errorf("failed to write history file: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write history file: %v", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to replace history file: %v", err)
+	}
+	return len(entries) - len(kept), nil
+}
+
+// Since returns the entries processed at or after the given time
+func Since(entries []Entry, since time.Time) []Entry {
+	var filtered []Entry
+	for _, entry := range entries {
+		if !entry.ProcessedAt.Before(since) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// FindProcessed returns the most recent entry for the file that covers all the given track numbers,
+// if the file has not changed (same path, size and modification time) since it was processed
+func FindProcessed(entries []Entry, inputFileName string, trackNumbers []int) (Entry, bool) {
+	absPath, err := filepath.Abs(inputFileName)
+	if err != nil {
+		return Entry{}, false
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return Entry{}, false
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Path == absPath && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) && entry.hasTracks(trackNumbers) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// hasTracks reports whether every track number was extracted in this entry
+func (e Entry) hasTracks(trackNumbers []int) bool {
+	extracted := make(map[int]bool, len(e.Tracks))
+	for _, number := range e.Tracks {
+		extracted[number] = true
+	}
+	for _, number := range trackNumbers {
+		if !extracted[number] {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSince parses a --since or --prune value: a date (2006-01-02), an RFC 3339 timestamp,
+// or a duration before now such as 36h, 7d or 2w
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if len(value) > 1 {
+		unit := value[len(value)-1]
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n >= 0 {
+			switch unit {
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date or duration '%s' (use a date like 2024-01-31 or a duration like 7d)", value)
+}
@@ -0,0 +1,82 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Selection is the last interactive selection and exclusion used for the files of one directory
+type Selection struct {
+	Selection string    `json:"selection"`
+	Exclusion string    `json:"exclusion"`
+	SavedAt   time.Time `json:"saved_at"`
+}
+
+// SelectionsPath returns the location of the file remembering interactive selections, next to the history file
+func SelectionsPath() string {
+	return filepath.Join(filepath.Dir(DefaultPath()), "selections.json")
+}
+
+// LastSelection returns the selection last remembered for the directory of inputFileName
+func LastSelection(inputFileName string) (Selection, bool) {
+	selections, err := loadSelections()
+	if err != nil {
+		return Selection{}, false
+	}
+	selection, found := selections[selectionKey(inputFileName)]
+	return selection, found
+}
+
+// RememberSelection stores the selection and exclusion used for inputFileName as the default for its directory
+func RememberSelection(inputFileName, selection, exclusion string) error {
+	selections, err := loadSelections()
+	if err != nil {
+		selections = make(map[string]Selection) // Start over rather than keep a damaged file
+	}
+	selections[selectionKey(inputFileName)] = Selection{
+		Selection: selection,
+		Exclusion: exclusion,
+		SavedAt:   time.Now(),
+	}
+
+	data, err := json.MarshalIndent(selections, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode selections: %v", err)
+	}
+	path := SelectionsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write selections file: %v", err)
+	}
+	return nil
+}
+
+// loadSelections reads the remembered selections keyed by directory; a missing file yields none
+func loadSelections() (map[string]Selection, error) {
+	data, err := os.ReadFile(SelectionsPath())
+	if os.IsNotExist(err) {
+		return make(map[string]Selection), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read selections file: %v", err)
+	}
+	selections := make(map[string]Selection)
+	if err := json.Unmarshal(data, &selections); err != nil {
+		return nil, fmt.Errorf("failed to parse selections file: %v", err)
+	}
+	return selections, nil
+}
+
+// selectionKey returns the absolute directory of a file, under which its selection is remembered
+func selectionKey(inputFileName string) string {
+	dir := filepath.Dir(inputFileName)
+	if absDir, err := filepath.Abs(dir); err == nil {
+		return absDir
+	}
+	return dir
+}
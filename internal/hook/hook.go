@@ -0,0 +1,201 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/model"
+)
+
+// PostHookContext holds the values substituted into a post-extraction hook command
+type PostHookContext struct {
+	Output   string // Path of the extracted subtitle file
+	Language string // Track language code
+	Format   string // Subtitle format (file extension)
+	Source   string // Path of the source MKV file
+}
+
+// NewPostHookContext builds the hook context for a track extracted to outFileName.
+// It is shared by the dry-run preview and the real run so both expand the same values.
+func NewPostHookContext(sourceFileName string, track model.MKVTrack, outFileName string) PostHookContext {
+	return PostHookContext{
+		Output:   outFileName,
+		Language: track.Properties.Language,
+		Format:   strings.TrimPrefix(filepath.Ext(outFileName), "."),
+		Source:   sourceFileName,
+	}
+}
+
+// PostHookPlaceholders lists the placeholders understood by BuildPostHookCommand
+var PostHookPlaceholders = []string{"{output}", "{language}", "{format}", "{source}"}
+
+// ValidatePostHook checks that a post-hook template only uses known placeholders
+func ValidatePostHook(template string) error {
+	return model.ValidatePlaceholders(template, PostHookPlaceholders, nil)
+}
+
+// BuildPostHookCommand expands the placeholders in a post-hook template
+// Supported placeholders: {output}, {language}, {format}, {source}
+// Values are quoted for the platform shell so paths with spaces survive
+// All placeholders are replaced in a single pass, so values containing placeholder text are left as is
+func BuildPostHookCommand(template string, ctx PostHookContext) string {
+	replacer := strings.NewReplacer(
+		"{output}", quoteShellArg(ctx.Output),
+		"{language}", quoteShellArg(ctx.Language),
+		"{format}", quoteShellArg(ctx.Format),
+		"{source}", quoteShellArg(ctx.Source),
+	)
+	return replacer.Replace(template)
+}
+
+// RunPostHook executes the post-hook command for a single extracted file
+func RunPostHook(ctx context.Context, template string, hookContext PostHookContext) error {
+	command := BuildPostHookCommand(template, hookContext)
+	var env []string
+	if runtime.GOOS == "windows" {
+		command, env = buildWindowsPostHookCommand(template, hookContext)
+	}
+
+	cmd := shellCommand(ctx, command)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("post-hook failed for %s: %v", filepath.Base(hookContext.Output), err)
+	}
+	return nil
+}
+
+// buildWindowsPostHookCommand expands the placeholders of a post-hook template to references to
+// environment variables holding the values. cmd.exe expands %VAR% even inside quotes, so a file named
+// 100%DATA%.srt would be rewritten if its name were part of the command line; the text a variable
+// expands to is not expanded again.
+func buildWindowsPostHookCommand(template string, ctx PostHookContext) (string, []string) {
+	replacer := strings.NewReplacer(
+		"{output}", `"%SUBSCALPEL_OUTPUT%"`,
+		"{language}", `"%SUBSCALPEL_LANGUAGE%"`,
+		"{format}", `"%SUBSCALPEL_FORMAT%"`,
+		"{source}", `"%SUBSCALPEL_SOURCE%"`,
+	)
+	env := []string{
+		"SUBSCALPEL_OUTPUT=" + ctx.Output,
+		"SUBSCALPEL_LANGUAGE=" + ctx.Language,
+		"SUBSCALPEL_FORMAT=" + ctx.Format,
+		"SUBSCALPEL_SOURCE=" + ctx.Source,
+	}
+	return replacer.Replace(template), env
+}
+
+// RunPostHooks executes the post-hook once per extracted file, continuing past failures
+func RunPostHooks(ctx context.Context, template, sourceFileName string, jobs []model.ExtractionJob) error {
+	if template == "" || len(jobs) == 0 {
+		return nil
+	}
+
+	var failed int
+	for _, job := range jobs {
+		if err := RunPostHook(ctx, template, NewPostHookContext(sourceFileName, job.OriginalTrack, job.OutFileName)); err != nil {
+			format.PrintError(err.Error())
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("post-hook failed for %d of %d file(s)", failed, len(jobs))
+	}
+
+	format.PrintSuccess(fmt.Sprintf("Post-hook completed for %d file(s)", len(jobs)))
+	return nil
+}
+
+// PreHookTrack describes a track that is planned for extraction
+type PreHookTrack struct {
+	Number   int    `json:"number"`
+	Id       int    `json:"id"`
+	Language string `json:"language"`
+	Name     string `json:"name"`
+	CodecId  string `json:"codec_id"`
+	Format   string `json:"format"`
+	Forced   bool   `json:"forced"`
+	Default  bool   `json:"default"`
+	Output   string `json:"output"`
+}
+
+// PreHookPayload is the JSON document written to the pre-hook's stdin
+type PreHookPayload struct {
+	Source string         `json:"source"`
+	Tracks []PreHookTrack `json:"tracks"`
+}
+
+// NewPreHookTrack builds the pre-hook description of a planned track extraction
+func NewPreHookTrack(track model.MKVTrack, outFileName string) PreHookTrack {
+	return PreHookTrack{
+		Number:   track.Properties.Number,
+		Id:       track.Id,
+		Language: track.Properties.Language,
+		Name:     track.Properties.TrackName,
+		CodecId:  track.Properties.CodecId,
+		Format:   model.GetSubtitleFormatFromCodec(track.Properties.CodecId),
+		Forced:   track.Properties.Forced,
+		Default:  track.Properties.Default,
+		Output:   outFileName,
+	}
+}
+
+// RunPreHook executes the pre-hook with the payload as JSON on stdin
+// It returns false when the hook vetoes processing by exiting with a non-zero code. The exit codes
+// the shell uses when it cannot find or run the command are errors, so a typo does not skip every file.
+func RunPreHook(ctx context.Context, command string, payload PreHookPayload) (bool, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode pre-hook input: %v", err)
+	}
+
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if isCommandNotFound(exitErr.ExitCode()) {
+				return false, fmt.Errorf("failed to run pre-hook %q: the shell could not run it (exit code %d)", command, exitErr.ExitCode())
+			}
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to run pre-hook: %v", err)
+	}
+	return true, nil
+}
+
+// isCommandNotFound reports whether a shell exit code means the command could not be found or
+// executed: 126 and 127 for sh, 9009 for cmd.exe
+func isCommandNotFound(exitCode int) bool {
+	if runtime.GOOS == "windows" {
+		return exitCode == 9009
+	}
+	return exitCode == 126 || exitCode == 127
+}
+
+// quoteShellArg quotes a value so it is passed to the shell as a single argument. On Windows the
+// quoted value does not stop cmd.exe from expanding %VAR%, so post-hooks run with
+// buildWindowsPostHookCommand instead, and the quoted form only shows the command in dry runs.
+func quoteShellArg(value string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,87 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"subscalpelmkv/internal/model"
+)
+
+// NamerInput is the JSON document written to the name command's stdin
+type NamerInput struct {
+	Source  string       `json:"source"`
+	Track   PreHookTrack `json:"track"` // Output holds the name the filename template gives the track
+	Chapter int          `json:"chapter,omitempty"`
+}
+
+// CommandNamer names output files by running an external command, which receives a NamerInput on
+// stdin and prints the file name on the first line of stdout
+type CommandNamer struct {
+	Command string
+
+	mu    sync.Mutex
+	names map[string]namerResult // The same track is named several times per run
+}
+
+type namerResult struct {
+	name string
+	err  error
+}
+
+// NewCommandNamer creates a namer running command through the platform shell
+func NewCommandNamer(command string) *CommandNamer {
+	return &CommandNamer{Command: command, names: make(map[string]namerResult)}
+}
+
+// Name runs the command for a track and returns the file name it printed
+func (n *CommandNamer) Name(inputFileName string, track model.MKVTrack, templateName string, config model.OutputConfig) (string, error) {
+	key := fmt.Sprintf("%s\x00%d\x00%d\x00%s", inputFileName, track.Id, config.Chapter, templateName)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if result, exists := n.names[key]; exists {
+		return result.name, result.err
+	}
+
+	name, err := n.run(NamerInput{
+		Source:  inputFileName,
+		Track:   NewPreHookTrack(track, templateName),
+		Chapter: config.Chapter,
+	})
+	n.names[key] = namerResult{name, err}
+	return name, err
+}
+
+// run executes the command with the input as JSON on stdin
+func (n *CommandNamer) run(input NamerInput) (string, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode name command input: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	// Names are built from the filename template without a context, like the template itself
+	cmd := shellCommand(context.Background(), n.Command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("name command failed: %v: %s", err, message)
+		}
+		return "", fmt.Errorf("name command failed: %v", err)
+	}
+
+	name, _, _ := strings.Cut(stdout.String(), "\n")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("name command printed no file name")
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("name command printed a path, not a file name: %s", name)
+	}
+	return name, nil
+}
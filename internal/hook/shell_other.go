@@ -0,0 +1,13 @@
+//go:build !windows
+
+package hook
+
+import (
+	"context"
+	"os/exec"
+)
+
+// shellCommand wraps a command line in sh
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package hook
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// shellCommand wraps a command line in cmd.exe. The command line is passed as is rather than as an
+// argument, which Go would escape with backslashes that cmd.exe does not understand; /S makes cmd.exe
+// strip only the outer quotes, so the values quoted by quoteShellArg reach it unchanged.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cmd")
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `cmd /S /C "` + command + `"`}
+	return cmd
+}
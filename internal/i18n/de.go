@@ -0,0 +1,135 @@
+package i18n
+
+// germanMessages is the German message catalog
+var germanMessages = map[string]string{
+	"Extract all tracks? Y/n:":                "Alle Spuren extrahieren? Y/n:",
+	" (press enter for yes)":                  " (Eingabetaste für Ja)",
+	"Error reading input: %v":                 "Fehler beim Lesen der Eingabe: %v",
+	"Please enter 'Y' for yes or 'N' for no.": "Bitte 'Y' für Ja oder 'N' für Nein eingeben.",
+	"Track Selection":                         "Spurauswahl",
+	"Enter selection (comma-separated):":      "Auswahl eingeben (durch Kommas getrennt):",
+	"Language: eng,spa,fre  •  Track ID: 14,16,18  •  Format: srt,ass,sup  •  Mixed: eng,14,srt": "Sprache: eng,spa,fre  •  Spur-ID: 14,16,18  •  Format: srt,ass,sup  •  Gemischt: eng,14,srt",
+	"Selection:":                          "Auswahl:",
+	" (press enter to accept all)":        " (Eingabetaste, um alle zu übernehmen)",
+	"Track Exclusions (Optional)":         "Spurausschlüsse (optional)",
+	"Enter exclusions (comma-separated):": "Ausschlüsse eingeben (durch Kommas getrennt):",
+	"Language: chi,kor  •  Track ID: 15,17  •  Format: sup,sub  •  Status: disabled  •  Mixed: chi,15,sup": "Sprache: chi,kor  •  Spur-ID: 15,17  •  Format: sup,sub  •  Status: disabled  •  Gemischt: chi,15,sup",
+	"Exclusions:":                           "Ausschlüsse:",
+	" (press enter to skip)":                " (Eingabetaste zum Überspringen)",
+	"Unknown language code '%s' - skipping": "Unbekannter Sprachcode '%s' – wird übersprungen",
+	"Unknown language code, format, or invalid track ID '%s' - skipping":           "Unbekannter Sprachcode, unbekanntes Format oder ungültige Spur-ID '%s' – wird übersprungen",
+	"Unknown exclusion language code, format, or invalid track ID '%s' - skipping": "Unbekannter Ausschluss-Sprachcode, unbekanntes Format oder ungültige Spur-ID '%s' – wird übersprungen",
+	"Unknown language code, format, or invalid track ID '%s'":                      "Unbekannter Sprachcode, unbekanntes Format oder ungültige Spur-ID '%s'",
+	"Unknown exclusion language code, format, or invalid track ID '%s'":            "Unbekannter Ausschluss-Sprachcode, unbekanntes Format oder ungültige Spur-ID '%s'",
+	"Usage":                                  "Verwendung",
+	"Selection Options":                      "Auswahloptionen",
+	"Output Options":                         "Ausgabeoptionen",
+	"Commands":                               "Befehle",
+	"Examples":                               "Beispiele",
+	"Default filename template":              "Standard-Dateinamenvorlage",
+	"Language codes":                         "Sprachcodes",
+	"Configuration":                          "Konfiguration",
+	"Drag-and-drop mode":                     "Drag-and-Drop-Modus",
+	"Available Subtitle Tracks":              "Verfügbare Untertitelspuren",
+	"Unknown":                                "Unbekannt",
+	"No subtitle tracks found in this file.": "In dieser Datei wurden keine Untertitelspuren gefunden.",
+	"track":                                  "Spur",
+	"tracks":                                 "Spuren",
+	"language":                               "Sprache",
+	"languages":                              "Sprachen",
+	"format":                                 "Format",
+	"formats":                                "Formate",
+	"file":                                   "Datei",
+	"files":                                  "Dateien",
+	"%d total %s, %d %s, %d %s":              "%d %s insgesamt, %d %s, %d %s",
+	"%d valid %s, %d total %s, %d %s, %d %s • %d errors": "Gültig: %d %s, %d %s insgesamt, %d %s, %d %s • %d Fehler",
+	"%d %s, %d total %s, %d %s, %d %s":                   "%d %s, %d %s insgesamt, %d %s, %d %s",
+	"Processing file: %s":                                "Verarbeite Datei: %s",
+	"Error: %v":                                          "Fehler: %v",
+	"Press enter to exit...":                             "Eingabetaste zum Beenden drücken...",
+	"File does not exist or is a directory: %s":          "Datei existiert nicht oder ist ein Verzeichnis: %s",
+	"File is not an MKV or WebM file: %s":                "Datei ist keine MKV- oder WebM-Datei: %s",
+	"Error analyzing file: %v":                           "Fehler beim Analysieren der Datei: %v",
+	"Files to Process":                                   "Zu verarbeitende Dateien",
+	"Tracks: %d":                                         "Spuren: %d",
+	"Languages: ":                                        "Sprachen: ",
+	"Formats: ":                                          "Formate: ",
+	"Processing History":                                 "Verarbeitungsverlauf",
+	"Processing History since %s":                        "Verarbeitungsverlauf seit %s",
+	"No processed files recorded":                        "Keine verarbeiteten Dateien erfasst",
+	"%d file(s) • history file: %s":                      "%d Datei(en) • Verlaufsdatei: %s",
+	"tracks: %s":                                         "Spuren: %s",
+	"Track Processing":                                   "Spurverarbeitung",
+	"Extracting all subtitle tracks":                     "Alle Untertitelspuren werden extrahiert",
+	"Selecting tracks matching %s":                       "Auswahl der Spuren nach %s",
+	"Excluding tracks matching %s":                       "Ausschluss der Spuren nach %s",
+	"%s; excluding %s":                                   "%s; ausgeschlossen: %s",
+	"languages: %s":                                      "Sprachen: %s",
+	"track IDs: %s":                                      "Spur-IDs: %s",
+	"formats: %s":                                        "Formate: %s",
+	"status: %s":                                         "Status: %s",
+	"Extract MKV Subtitles":                              "MKV-Untertitel extrahieren",
+	"Extract MKV Subtitles v%s":                          "MKV-Untertitel extrahieren v%s",
+	"Step %d:":                                           "Schritt %d:",
+	"Track":                                              "Spur",
+	"Processing:":                                        "Verarbeitung:",
+	"Version Information":                                "Versionsinformationen",
+	"none (built-in defaults)":                           "keine (eingebaute Standardwerte)",
+	"Selectors":                                          "Selektoren",
+	"Analyzing tracks...":                                "Spuren werden analysiert...",
+	"Analyzing %d/%d:":                                   "Analyse %d/%d:",
+	"Configuration Check":                                "Konfigurationsprüfung",
+	"No configuration file found, built-in defaults are used. Searched:": "Keine Konfigurationsdatei gefunden, es werden die eingebauten Standardwerte verwendet. Durchsucht:",
+	"%s is valid": "%s ist gültig",
+	"Extract more? [s]ame file, [o]ther file:": "Weitere extrahieren? [s] gleiche Datei, [o] andere Datei:",
+	" (press enter to exit)":                   " (Eingabetaste zum Beenden)",
+	"Please enter 'S' for the same file, 'O' for another file, or press enter to exit.": "Bitte 'S' für die gleiche Datei oder 'O' für eine andere Datei eingeben oder mit der Eingabetaste beenden.",
+	"No MKV files found in %s":                           "Keine MKV-Dateien in %s gefunden",
+	"Files in %s":                                        "Dateien in %s",
+	"File number:":                                       "Dateinummer:",
+	" (press enter to go back)":                          " (Eingabetaste für zurück)",
+	"Please enter a number from 1 to %d.":                "Bitte eine Zahl von 1 bis %d eingeben.",
+	"Menu: enter %s to pick tracks from a numbered list": "Menü: %s eingeben, um Spuren aus einer nummerierten Liste zu wählen",
+	"No tracks selected":                                 "Keine Spuren ausgewählt",
+	"Track Menu":                                         "Spurmenü",
+	"Toggle: 1 3 5  •  Range: 1-4  •  all  •  none  •  Press enter to confirm": "Umschalten: 1 3 5  •  Bereich: 1-4  •  all  •  none  •  Eingabetaste zum Bestätigen",
+	"Toggle:":                                                    "Umschalten:",
+	" (press enter to confirm)":                                  " (Eingabetaste zum Bestätigen)",
+	"Not a menu number or range: '%s'":                           "Keine Menünummer und kein Bereich: '%s'",
+	"Please enter numbers from 1 to %d.":                         "Bitte Zahlen von 1 bis %d eingeben.",
+	"Could not remember the selection: %v":                       "Auswahl konnte nicht gespeichert werden: %v",
+	"Last Selection":                                             "Letzte Auswahl",
+	"Use the last selection for this folder? Y/n:":               "Letzte Auswahl für diesen Ordner verwenden? Y/n:",
+	"Track %d has no language tag":                               "Spur %d hat keine Sprachkennung",
+	"Extract it with the selected languages? y/N:":               "Mit den gewählten Sprachen extrahieren? y/N:",
+	" (press enter for no)":                                      " (Eingabetaste für Nein)",
+	"Full Dialogue":                                              "Vollständige Dialoge",
+	"Signs & Songs":                                              "Schilder & Lieder",
+	"Waiting for the file to finish copying...":                  "Warten, bis die Datei fertig kopiert ist...",
+	"Extract all tracks? y/N:":                                   "Alle Spuren extrahieren? y/N:",
+	" (press enter for %s)":                                      " (Eingabetaste für %s)",
+	"Files Matching Differently":                                 "Abweichend getroffene Dateien",
+	"Most files: %s":                                             "Die meisten Dateien: %s",
+	"Refine files (e.g., 1 3 or 1-2):":                           "Dateien anpassen (z. B. 1 3 oder 1-2):",
+	" (press enter to use the selection for all)":                " (Eingabetaste, um die Auswahl für alle zu verwenden)",
+	"Enter a selection for this file, or %s to leave it out":     "Auswahl für diese Datei eingeben, oder %s, um sie auszulassen",
+	"Selection for %s:":                                          "Auswahl für %s:",
+	" (press enter to keep the batch selection)":                 " (Eingabetaste, um die Stapelauswahl zu behalten)",
+	"Skipping %s":                                                "%s wird übersprungen",
+	"no matching tracks":                                         "keine passenden Spuren",
+	"Audit: %s":                                                  "Prüfung: %s",
+	"%s: missing %s":                                             "%s: %s fehlt",
+	"All %d file(s) have subtitles in every required language":   "Alle %d Datei(en) haben Untertitel in jeder geforderten Sprache",
+	"%d of %d file(s) lack a required language":                  "%d von %d Datei(en) fehlt eine geforderte Sprache",
+	"Dry run: %d cue(s) would be removed, no files were changed": "Testlauf: %d Untertitelzeile(n) würden entfernt, keine Dateien wurden geändert",
+	"Removed %d cue(s)":                                          "%d Untertitelzeile(n) entfernt",
+	"Library Statistics":                                         "Bibliotheksstatistik",
+	"%d file(s), %d subtitle track(s)":                           "%d Datei(en), %d Untertitelspur(en)",
+	"Languages":                                                  "Sprachen",
+	"Formats":                                                    "Formate",
+	"%5d track(s) in %5d file(s) (%s)":                           "%5d Spur(en) in %5d Datei(en) (%s)",
+	"Files without subtitles (%d)":                               "Dateien ohne Untertitel (%d)",
+	"Files with only image-based subtitles (%d)":                 "Dateien mit nur bildbasierten Untertiteln (%d)",
+	"Files that could not be analyzed (%d)":                      "Dateien, die nicht analysiert werden konnten (%d)",
+	"Removed %d history entries processed before %s":             "%d Verlaufseinträge vor %s entfernt",
+}
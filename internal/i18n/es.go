@@ -0,0 +1,135 @@
+package i18n
+
+// spanishMessages is the Spanish message catalog
+var spanishMessages = map[string]string{
+	"Extract all tracks? Y/n:":                "¿Extraer todas las pistas? Y/n:",
+	" (press enter for yes)":                  " (pulse Intro para sí)",
+	"Error reading input: %v":                 "Error al leer la entrada: %v",
+	"Please enter 'Y' for yes or 'N' for no.": "Introduzca 'Y' para sí o 'N' para no.",
+	"Track Selection":                         "Selección de pistas",
+	"Enter selection (comma-separated):":      "Introduzca la selección (separada por comas):",
+	"Language: eng,spa,fre  •  Track ID: 14,16,18  •  Format: srt,ass,sup  •  Mixed: eng,14,srt": "Idioma: eng,spa,fre  •  ID de pista: 14,16,18  •  Formato: srt,ass,sup  •  Mixto: eng,14,srt",
+	"Selection:":                          "Selección:",
+	" (press enter to accept all)":        " (pulse Intro para aceptar todas)",
+	"Track Exclusions (Optional)":         "Exclusiones de pistas (opcional)",
+	"Enter exclusions (comma-separated):": "Introduzca las exclusiones (separadas por comas):",
+	"Language: chi,kor  •  Track ID: 15,17  •  Format: sup,sub  •  Status: disabled  •  Mixed: chi,15,sup": "Idioma: chi,kor  •  ID de pista: 15,17  •  Formato: sup,sub  •  Estado: disabled  •  Mixto: chi,15,sup",
+	"Exclusions:":                           "Exclusiones:",
+	" (press enter to skip)":                " (pulse Intro para omitir)",
+	"Unknown language code '%s' - skipping": "Código de idioma desconocido '%s': se omite",
+	"Unknown language code, format, or invalid track ID '%s' - skipping":           "Código de idioma o formato desconocido, o ID de pista no válido '%s': se omite",
+	"Unknown exclusion language code, format, or invalid track ID '%s' - skipping": "Código de idioma o formato de exclusión desconocido, o ID de pista no válido '%s': se omite",
+	"Unknown language code, format, or invalid track ID '%s'":                      "Código de idioma o formato desconocido, o ID de pista no válido '%s'",
+	"Unknown exclusion language code, format, or invalid track ID '%s'":            "Código de idioma o formato de exclusión desconocido, o ID de pista no válido '%s'",
+	"Usage":                                  "Uso",
+	"Selection Options":                      "Opciones de selección",
+	"Output Options":                         "Opciones de salida",
+	"Commands":                               "Comandos",
+	"Examples":                               "Ejemplos",
+	"Default filename template":              "Plantilla de nombre de archivo predeterminada",
+	"Language codes":                         "Códigos de idioma",
+	"Configuration":                          "Configuración",
+	"Drag-and-drop mode":                     "Modo arrastrar y soltar",
+	"Available Subtitle Tracks":              "Pistas de subtítulos disponibles",
+	"Unknown":                                "Desconocido",
+	"No subtitle tracks found in this file.": "No se encontraron pistas de subtítulos en este archivo.",
+	"track":                                  "pista",
+	"tracks":                                 "pistas",
+	"language":                               "idioma",
+	"languages":                              "idiomas",
+	"format":                                 "formato",
+	"formats":                                "formatos",
+	"file":                                   "archivo",
+	"files":                                  "archivos",
+	"%d total %s, %d %s, %d %s":              "%d %s en total, %d %s, %d %s",
+	"%d valid %s, %d total %s, %d %s, %d %s • %d errors": "Válidos: %d %s, %d %s en total, %d %s, %d %s • %d errores",
+	"%d %s, %d total %s, %d %s, %d %s":                   "%d %s, %d %s en total, %d %s, %d %s",
+	"Processing file: %s":                                "Procesando archivo: %s",
+	"Error: %v":                                          "Error: %v",
+	"Press enter to exit...":                             "Pulse Intro para salir...",
+	"File does not exist or is a directory: %s":          "El archivo no existe o es un directorio: %s",
+	"File is not an MKV or WebM file: %s":                "El archivo no es un archivo MKV o WebM: %s",
+	"Error analyzing file: %v":                           "Error al analizar el archivo: %v",
+	"Files to Process":                                   "Archivos a procesar",
+	"Tracks: %d":                                         "Pistas: %d",
+	"Languages: ":                                        "Idiomas: ",
+	"Formats: ":                                          "Formatos: ",
+	"Processing History":                                 "Historial de procesamiento",
+	"Processing History since %s":                        "Historial de procesamiento desde %s",
+	"No processed files recorded":                        "No hay archivos procesados registrados",
+	"%d file(s) • history file: %s":                      "%d archivo(s) • archivo de historial: %s",
+	"tracks: %s":                                         "pistas: %s",
+	"Track Processing":                                   "Procesamiento de pistas",
+	"Extracting all subtitle tracks":                     "Extrayendo todas las pistas de subtítulos",
+	"Selecting tracks matching %s":                       "Seleccionando pistas que coinciden con %s",
+	"Excluding tracks matching %s":                       "Excluyendo pistas que coinciden con %s",
+	"%s; excluding %s":                                   "%s; excluyendo %s",
+	"languages: %s":                                      "idiomas: %s",
+	"track IDs: %s":                                      "IDs de pista: %s",
+	"formats: %s":                                        "formatos: %s",
+	"status: %s":                                         "estado: %s",
+	"Extract MKV Subtitles":                              "Extraer subtítulos MKV",
+	"Extract MKV Subtitles v%s":                          "Extraer subtítulos MKV v%s",
+	"Step %d:":                                           "Paso %d:",
+	"Track":                                              "Pista",
+	"Processing:":                                        "Procesando:",
+	"Version Information":                                "Información de versión",
+	"none (built-in defaults)":                           "ninguno (valores predeterminados integrados)",
+	"Selectors":                                          "Selectores",
+	"Analyzing tracks...":                                "Analizando pistas...",
+	"Analyzing %d/%d:":                                   "Analizando %d/%d:",
+	"Configuration Check":                                "Comprobación de la configuración",
+	"No configuration file found, built-in defaults are used. Searched:": "No se encontró ningún archivo de configuración; se usan los valores predeterminados integrados. Ubicaciones buscadas:",
+	"%s is valid": "%s es válido",
+	"Extract more? [s]ame file, [o]ther file:": "¿Extraer más? [s] mismo archivo, [o] otro archivo:",
+	" (press enter to exit)":                   " (pulse Intro para salir)",
+	"Please enter 'S' for the same file, 'O' for another file, or press enter to exit.": "Introduzca 'S' para el mismo archivo, 'O' para otro archivo o pulse Intro para salir.",
+	"No MKV files found in %s":                           "No se encontraron archivos MKV en %s",
+	"Files in %s":                                        "Archivos en %s",
+	"File number:":                                       "Número de archivo:",
+	" (press enter to go back)":                          " (pulse Intro para volver)",
+	"Please enter a number from 1 to %d.":                "Introduzca un número del 1 al %d.",
+	"Menu: enter %s to pick tracks from a numbered list": "Menú: introduzca %s para elegir pistas de una lista numerada",
+	"No tracks selected":                                 "No se seleccionó ninguna pista",
+	"Track Menu":                                         "Menú de pistas",
+	"Toggle: 1 3 5  •  Range: 1-4  •  all  •  none  •  Press enter to confirm": "Alternar: 1 3 5  •  Rango: 1-4  •  all  •  none  •  Pulse Intro para confirmar",
+	"Toggle:":                                                    "Alternar:",
+	" (press enter to confirm)":                                  " (pulse Intro para confirmar)",
+	"Not a menu number or range: '%s'":                           "No es un número ni un rango del menú: '%s'",
+	"Please enter numbers from 1 to %d.":                         "Introduzca números del 1 al %d.",
+	"Could not remember the selection: %v":                       "No se pudo recordar la selección: %v",
+	"Last Selection":                                             "Última selección",
+	"Use the last selection for this folder? Y/n:":               "¿Usar la última selección para esta carpeta? Y/n:",
+	"Track %d has no language tag":                               "La pista %d no tiene etiqueta de idioma",
+	"Extract it with the selected languages? y/N:":               "¿Extraerla con los idiomas seleccionados? y/N:",
+	" (press enter for no)":                                      " (pulse Intro para no)",
+	"Full Dialogue":                                              "Diálogo completo",
+	"Signs & Songs":                                              "Carteles y canciones",
+	"Waiting for the file to finish copying...":                  "Esperando a que termine la copia del archivo...",
+	"Extract all tracks? y/N:":                                   "¿Extraer todas las pistas? y/N:",
+	" (press enter for %s)":                                      " (pulse Intro para %s)",
+	"Files Matching Differently":                                 "Archivos con coincidencias distintas",
+	"Most files: %s":                                             "La mayoría de los archivos: %s",
+	"Refine files (e.g., 1 3 or 1-2):":                           "Ajustar archivos (p. ej., 1 3 o 1-2):",
+	" (press enter to use the selection for all)":                " (pulse Intro para usar la selección en todos)",
+	"Enter a selection for this file, or %s to leave it out":     "Introduzca una selección para este archivo, o %s para omitirlo",
+	"Selection for %s:":                                          "Selección para %s:",
+	" (press enter to keep the batch selection)":                 " (pulse Intro para mantener la selección del lote)",
+	"Skipping %s":                                                "Omitiendo %s",
+	"no matching tracks":                                         "ninguna pista coincidente",
+	"Audit: %s":                                                  "Auditoría: %s",
+	"%s: missing %s":                                             "%s: falta %s",
+	"All %d file(s) have subtitles in every required language":   "Los %d archivo(s) tienen subtítulos en todos los idiomas requeridos",
+	"%d of %d file(s) lack a required language":                  "A %d de %d archivo(s) les falta un idioma requerido",
+	"Dry run: %d cue(s) would be removed, no files were changed": "Simulación: se eliminarían %d línea(s) de subtítulos, no se modificó ningún archivo",
+	"Removed %d cue(s)":                                          "Se eliminaron %d línea(s) de subtítulos",
+	"Library Statistics":                                         "Estadísticas de la biblioteca",
+	"%d file(s), %d subtitle track(s)":                           "%d archivo(s), %d pista(s) de subtítulos",
+	"Languages":                                                  "Idiomas",
+	"Formats":                                                    "Formatos",
+	"%5d track(s) in %5d file(s) (%s)":                           "%5d pista(s) en %5d archivo(s) (%s)",
+	"Files without subtitles (%d)":                               "Archivos sin subtítulos (%d)",
+	"Files with only image-based subtitles (%d)":                 "Archivos solo con subtítulos basados en imágenes (%d)",
+	"Files that could not be analyzed (%d)":                      "Archivos que no se pudieron analizar (%d)",
+	"Removed %d history entries processed before %s":             "Se eliminaron %d entradas del historial anteriores a %s",
+}
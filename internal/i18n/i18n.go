@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// SupportedLanguages lists the UI languages that have a message catalog
+var SupportedLanguages = []language.Tag{language.English, language.Spanish, language.German}
+
+// Message catalogs keyed by the English message, which doubles as the fallback text
+var messageCatalogs = map[language.Tag]map[string]string{
+	language.Spanish: spanishMessages,
+	language.German:  germanMessages,
+}
+
+var (
+	builder = catalog.NewBuilder(catalog.Fallback(language.English))
+	printer = message.NewPrinter(language.English, message.Catalog(builder))
+	current = language.English
+)
+
+func init() {
+	for tag, messages := range messageCatalogs {
+		for key, translation := range messages {
+			builder.SetString(tag, key, translation)
+		}
+	}
+}
+
+// SetLanguage selects the UI language. An empty value falls back to the LC_ALL, LC_MESSAGES and LANG
+// environment variables; unsupported languages fall back to English.
+func SetLanguage(lang string) {
+	if lang == "" {
+		lang = languageFromEnvironment()
+	}
+
+	current = MatchLanguage(lang)
+	printer = message.NewPrinter(current, message.Catalog(builder))
+}
+
+// MatchLanguage returns the supported UI language closest to a language tag or POSIX locale (e.g., "de_DE.UTF-8")
+func MatchLanguage(lang string) language.Tag {
+	// POSIX locales look like de_DE.UTF-8 or es_ES@euro
+	lang, _, _ = strings.Cut(lang, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	lang = strings.ReplaceAll(lang, "_", "-")
+	if lang == "" || lang == "C" || lang == "POSIX" {
+		return language.English
+	}
+
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return language.English
+	}
+	_, index, confidence := language.NewMatcher(SupportedLanguages).Match(tag)
+	if confidence == language.No {
+		return language.English
+	}
+	return SupportedLanguages[index]
+}
+
+// Language returns the current UI language
+func Language() language.Tag {
+	return current
+}
+
+// T returns the message for the current UI language, formatted with args like fmt.Sprintf
+func T(key string, args ...interface{}) string {
+	return printer.Sprintf(key, args...)
+}
+
+// languageFromEnvironment reads the locale from the environment in POSIX precedence order
+func languageFromEnvironment() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,205 @@
+package langdetect
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MinWords is the number of words below which Detect does not guess
+const MinWords = 50
+
+// Candidate is a language with its share of the evidence
+type Candidate struct {
+	Language string  // ISO 639-2/B code, as used by Matroska
+	Score    float64 // Share of words (Latin script) or letters (other scripts) typical of the language
+}
+
+// stopwords holds frequent short words of the languages written in Latin script. They are enough to
+// tell a subtitle track's language apart, apart from close relatives which score alike.
+var stopwords = map[string][]string{
+	"eng": {"the", "you", "and", "to", "is", "it", "that", "of", "what", "this", "we", "in", "don't", "i'm", "it's", "have", "are", "your", "for", "be", "was", "with", "not", "just", "know"},
+	"dut": {"de", "het", "een", "en", "ik", "je", "niet", "is", "dat", "van", "wat", "we", "hij", "zijn", "maar", "met", "op", "voor", "er", "heb", "ben", "nee", "ja", "hebben", "wel"},
+	"ger": {"der", "die", "das", "und", "ich", "du", "nicht", "ist", "es", "sie", "wir", "ein", "eine", "zu", "mit", "was", "den", "auf", "ja", "mir", "mich", "sind", "hast", "habe", "auch"},
+	"fre": {"le", "la", "les", "de", "et", "je", "tu", "vous", "est", "pas", "que", "un", "une", "il", "ce", "qui", "c'est", "on", "en", "ne", "suis", "moi", "oui", "du", "des"},
+	"spa": {"el", "la", "de", "que", "y", "no", "es", "en", "lo", "un", "una", "los", "por", "qué", "me", "se", "te", "con", "para", "está", "sí", "eso", "pero", "mi", "las"},
+	"por": {"o", "a", "de", "que", "e", "não", "é", "um", "uma", "eu", "você", "se", "do", "da", "em", "os", "para", "com", "isso", "está", "mas", "me", "sim", "ele", "foi"},
+	"ita": {"il", "la", "di", "che", "e", "non", "è", "un", "una", "per", "mi", "ti", "lo", "sono", "ma", "ho", "cosa", "si", "con", "questo", "io", "hai", "gli", "del", "sei"},
+	"swe": {"och", "det", "att", "jag", "är", "du", "inte", "en", "på", "som", "vi", "har", "med", "han", "vad", "för", "den", "ett", "kan", "hon", "mig", "så", "nej", "ja", "var"},
+	"dan": {"og", "det", "at", "jeg", "er", "du", "ikke", "en", "på", "som", "vi", "har", "med", "han", "hvad", "for", "den", "et", "kan", "hun", "mig", "så", "nej", "ja", "var"},
+	"nor": {"og", "det", "at", "jeg", "er", "du", "ikke", "en", "på", "som", "vi", "har", "med", "han", "hva", "for", "den", "et", "kan", "hun", "meg", "så", "nei", "ja", "var"},
+	"fin": {"ja", "on", "ei", "se", "että", "minä", "sinä", "hän", "me", "mitä", "en", "oli", "ole", "kun", "mutta", "tämä", "sen", "niin", "jos", "kuin", "nyt", "vain", "olen", "olet", "mä"},
+	"pol": {"nie", "to", "się", "w", "i", "na", "jest", "że", "z", "co", "jak", "tak", "ja", "ty", "mnie", "do", "ale", "mi", "jestem", "czy", "już", "o", "go", "tu", "był"},
+	"cze": {"je", "to", "se", "na", "že", "v", "a", "jsem", "ne", "co", "tak", "já", "ty", "mi", "jsi", "ale", "jak", "by", "tady", "už", "být", "mě", "vás", "si", "ano"},
+	"tur": {"bir", "ve", "bu", "ne", "ben", "sen", "de", "da", "mi", "için", "çok", "var", "değil", "o", "ama", "evet", "hayır", "şey", "beni", "seni", "gibi", "daha", "neden", "nasıl", "burada"},
+	"hun": {"a", "az", "és", "nem", "hogy", "is", "egy", "van", "ez", "meg", "de", "csak", "mi", "te", "én", "már", "igen", "mit", "itt", "vagy", "jó", "kell", "most", "azt", "ne"},
+	"rum": {"și", "nu", "de", "să", "în", "la", "e", "ce", "este", "o", "un", "pe", "mai", "cu", "am", "ai", "eu", "tu", "da", "asta", "dar", "mă", "te", "sunt", "ne"},
+	"ind": {"yang", "aku", "kau", "tidak", "ini", "itu", "dan", "apa", "di", "ke", "kita", "dia", "ada", "saya", "kamu", "akan", "dengan", "untuk", "tak", "ya", "bisa", "sudah", "mereka", "tahu", "harus"},
+}
+
+// scriptLanguages maps Unicode scripts to the language usually written in them
+var scriptLanguages = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hiragana, "jpn"},
+	{unicode.Katakana, "jpn"},
+	{unicode.Hangul, "kor"},
+	{unicode.Han, "chi"},
+	{unicode.Cyrillic, "rus"},
+	{unicode.Arabic, "ara"},
+	{unicode.Hebrew, "heb"},
+	{unicode.Greek, "gre"},
+	{unicode.Thai, "tha"},
+	{unicode.Devanagari, "hin"},
+}
+
+// stopwordLanguage maps each stopword to the languages using it
+var stopwordLanguage = func() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// Detect ranks the likely languages of a text, best first. It returns nothing when the text is too
+// short or matches no known language.
+func Detect(text string) []Candidate {
+	if candidates := detectScript(text); candidates != nil {
+		return candidates
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	if len(words) < MinWords {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, word := range words {
+		languages := stopwordLanguage[word] // Contractions such as don't or c'est are stopwords as a whole
+		if len(languages) == 0 {
+			languages = stopwordLanguage[strings.Trim(word, "'")]
+		}
+		for _, language := range languages {
+			counts[language]++
+		}
+	}
+	return rank(counts, len(words))
+}
+
+// detectScript ranks languages by the script of the letters when most of them are not Latin
+func detectScript(text string) []Candidate {
+	counts := make(map[string]int)
+	letters, latin := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.Is(unicode.Latin, r) {
+			latin++
+			continue
+		}
+		for _, script := range scriptLanguages {
+			if unicode.Is(script.table, r) {
+				counts[script.language]++
+				break
+			}
+		}
+	}
+	if letters < MinWords || latin*2 >= letters {
+		return nil
+	}
+
+	// Japanese mixes kana with Han characters
+	if counts["jpn"] > 0 && counts["jpn"]*10 >= counts["chi"] {
+		counts["jpn"] += counts["chi"]
+		delete(counts, "chi")
+	}
+	return rank(counts, letters)
+}
+
+// rank turns evidence counts into candidates ordered by score
+func rank(counts map[string]int, total int) []Candidate {
+	var candidates []Candidate
+	for language, count := range counts {
+		candidates = append(candidates, Candidate{Language: language, Score: float64(count) / float64(total)})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Language < candidates[j].Language
+	})
+	if len(candidates) == 0 || candidates[0].Score < 0.1 {
+		return nil
+	}
+	return candidates
+}
+
+// scriptRelatives lists the other languages written in the script a language is detected by
+var scriptRelatives = map[string][]string{
+	"rus": {"ukr", "bel", "bul", "srp", "mac", "kaz", "mon"},
+	"ara": {"per", "urd", "kur"},
+	"chi": {"jpn"},
+	"hin": {"mar", "nep", "san"},
+}
+
+// terminologyCodes maps ISO 639-2/T codes to the bibliographic codes Detect returns
+var terminologyCodes = map[string]string{
+	"nld": "dut", "deu": "ger", "fra": "fre", "ces": "cze", "ron": "rum", "zho": "chi", "ell": "gre",
+	"fas": "per", "mkd": "mac", "nob": "nor", "nno": "nor",
+}
+
+// Verdict compares a track's language tag with the language detected in its text
+type Verdict struct {
+	Detected string // Best detected language, empty when the text gave no answer
+	Checked  bool   // Detection gave an answer and the tag is und or a language Detect knows
+	Agrees   bool   // The tag is the detected language, or scored too close to it to tell them apart
+}
+
+// Verify detects the language of text and compares it with the ISO 639-2 tag of its track
+func Verify(tag, text string) Verdict {
+	candidates := Detect(text)
+	if len(candidates) == 0 {
+		return Verdict{}
+	}
+
+	tag = strings.ToLower(tag)
+	if code, exists := terminologyCodes[tag]; exists {
+		tag = code
+	}
+	best := candidates[0]
+	verdict := Verdict{Detected: best.Language}
+	if tag == "und" || tag == "" {
+		verdict.Checked = true
+		return verdict
+	}
+
+	for _, candidate := range candidates {
+		// Close relatives (Danish and Norwegian, Spanish and Portuguese) share many stopwords
+		if candidate.Language == tag && candidate.Score >= best.Score*0.8 {
+			verdict.Agrees = true
+		}
+	}
+	for _, relative := range scriptRelatives[best.Language] {
+		if relative == tag {
+			verdict.Agrees = true
+		}
+	}
+
+	_, knownByWords := stopwords[tag]
+	verdict.Checked = knownByWords || verdict.Agrees
+	for _, script := range scriptLanguages {
+		if script.language == tag || slices.Contains(scriptRelatives[script.language], tag) {
+			verdict.Checked = true
+		}
+	}
+	return verdict
+}
@@ -0,0 +1,105 @@
+package lock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Lock is held on a source file until Release is called. It keeps several instances from processing
+// the same file at once, which would make them overwrite each other's temporary .mks and outputs.
+// The lock file is locked with the operating system's advisory locks, which it releases when the
+// holder exits, so a crashed instance leaves no lock behind that others would have to take over.
+type Lock struct {
+	path string
+	file *os.File
+}
+
+// HeldError reports that another process holds the lock of a file
+type HeldError struct {
+	PID int
+}
+
+func (e *HeldError) Error() string {
+	return fmt.Sprintf("being processed by another instance (process %d)", e.PID)
+}
+
+// errLocked is returned by lockFile when another process holds the lock
+var errLocked = errors.New("file is locked")
+
+// Dir returns the directory holding the lock files, shared by all instances on the machine
+func Dir() string {
+	return filepath.Join(os.TempDir(), "subscalpelmkv-locks")
+}
+
+// Acquire takes the lock of inputFileName. If a running process holds it, the error is a *HeldError.
+func Acquire(inputFileName string) (*Lock, error) {
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %v", err)
+	}
+	path := lockPath(inputFileName)
+
+	// Another attempt follows when the holder removed the lock file between opening and locking it
+	for attempt := 0; attempt < 3; attempt++ {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open lock file: %v", err)
+		}
+		if err := lockFile(file); err != nil {
+			data, _ := io.ReadAll(file)
+			file.Close()
+			if errors.Is(err, errLocked) {
+				pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+				return nil, &HeldError{PID: pid}
+			}
+			return nil, fmt.Errorf("failed to lock file: %v", err)
+		}
+
+		// A lock on a file no longer at path guards nothing, since the next instance creates a new one
+		if info, err := file.Stat(); err == nil {
+			if pathInfo, err := os.Stat(path); err != nil || !os.SameFile(info, pathInfo) {
+				unlockFile(file)
+				file.Close()
+				continue
+			}
+		}
+
+		if err := writePID(file); err != nil {
+			unlockFile(file)
+			file.Close()
+			return nil, fmt.Errorf("failed to write lock file: %v", err)
+		}
+		return &Lock{path: path, file: file}, nil
+	}
+	return nil, errors.New("lock file keeps being replaced")
+}
+
+// writePID replaces the contents of the lock file with the ID of this process, reported to the
+// instances that find the file locked
+func writePID(file *os.File) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return err
+}
+
+// Release removes the lock so other instances can process the file
+func (l *Lock) Release() {
+	releaseLockFile(l.path, l.file)
+}
+
+// lockPath names the lock file of a source after a hash of its absolute path
+func lockPath(inputFileName string) string {
+	if absPath, err := filepath.Abs(inputFileName); err == nil {
+		inputFileName = absPath
+	}
+	sum := sha256.Sum256([]byte(inputFileName))
+	return filepath.Join(Dir(), hex.EncodeToString(sum[:8])+".lock")
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package lock
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive flock on the file without waiting
+func lockFile(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLocked
+	}
+	return err
+}
+
+// unlockFile releases the flock on the file
+func unlockFile(file *os.File) {
+	syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
+
+// releaseLockFile removes the lock file while still holding it, so an instance that opened it in the
+// meantime sees it is gone once it gets the lock, then unlocks it
+func releaseLockFile(path string, file *os.File) {
+	os.Remove(path)
+	unlockFile(file)
+	file.Close()
+}
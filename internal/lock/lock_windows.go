@@ -0,0 +1,38 @@
+//go:build windows
+
+package lock
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockOffset places the locked byte far past the process ID, which other instances read while the
+// file is locked
+const lockOffset = 0x7fffffff
+
+// lockFile takes an exclusive lock on one byte of the file without waiting
+func lockFile(file *os.File) error {
+	overlapped := windows.Overlapped{OffsetHigh: lockOffset}
+	err := windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &overlapped)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLocked
+	}
+	return err
+}
+
+// unlockFile releases the lock taken by lockFile
+func unlockFile(file *os.File) {
+	overlapped := windows.Overlapped{OffsetHigh: lockOffset}
+	windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, &overlapped)
+}
+
+// releaseLockFile unlocks and closes the lock file before removing it, since Windows cannot remove
+// an open file. While another instance has it open the removal fails, and that instance keeps using it.
+func releaseLockFile(path string, file *os.File) {
+	unlockFile(file)
+	file.Close()
+	os.Remove(path)
+}
@@ -0,0 +1,101 @@
+package mkv
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"subscalpelmkv/internal/model"
+)
+
+// simpleChapterPattern matches the lines of mkvextract's simple chapter format:
+// CHAPTER01=00:01:30.500 and CHAPTER01NAME=Opening
+var simpleChapterPattern = regexp.MustCompile(`^CHAPTER(\d+)(NAME)?=(.*)$`)
+
+// ReadChapters returns the chapters of an MKV file ordered by start time, using mkvextract's simple format.
+// Chapters starting at the same time (such as those of several editions) are listed once.
+func ReadChapters(ctx context.Context, inputFileName string) ([]model.Chapter, error) {
+	tempFile, err := os.CreateTemp("", "subscalpelmkv-chapters-*.txt")
+	if err != nil {
+		return nil, fmt.Errorf("could not create temporary chapters file: %v", err)
+	}
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	if output, err := command(ctx, toolPath(ctx, "mkvextract"), inputFileName, "chapters", "--simple", tempFile.Name()).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("error reading chapters: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	file, err := os.Open(tempFile.Name())
+	if err != nil {
+		return nil, fmt.Errorf("error reading chapters: %v", err)
+	}
+	defer file.Close()
+
+	byNumber := make(map[int]*model.Chapter)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		match := simpleChapterPattern.FindStringSubmatch(strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\uFEFF")))
+		if match == nil {
+			continue
+		}
+		number, _ := strconv.Atoi(match[1])
+		chapter := byNumber[number]
+		if chapter == nil {
+			chapter = &model.Chapter{Number: number, Start: -1}
+			byNumber[number] = chapter
+		}
+		if match[2] == "NAME" {
+			chapter.Name = match[3]
+		} else if start, err := parseChapterTimestamp(match[3]); err == nil {
+			chapter.Start = start
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading chapters: %v", err)
+	}
+
+	var chapters []model.Chapter
+	seenStarts := make(map[time.Duration]bool)
+	for _, chapter := range byNumber {
+		if chapter.Start < 0 || seenStarts[chapter.Start] {
+			continue
+		}
+		seenStarts[chapter.Start] = true
+		chapters = append(chapters, *chapter)
+	}
+	sort.Slice(chapters, func(i, j int) bool {
+		return chapters[i].Start < chapters[j].Start
+	})
+	for i := range chapters {
+		chapters[i].Number = i + 1 // Renumber after dropping duplicates so {chapter} has no gaps
+	}
+	return chapters, nil
+}
+
+// parseChapterTimestamp parses HH:MM:SS.fff, with any number of fractional digits
+func parseChapterTimestamp(value string) (time.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid chapter timestamp: %s", value)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid chapter timestamp: %s", value)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid chapter timestamp: %s", value)
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chapter timestamp: %s", value)
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second)), nil
+}
@@ -0,0 +1,161 @@
+package mkv
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/naming"
+)
+
+// DemuxAllTracks extracts the given tracks, every attachment and the chapters of an MKV file in a
+// single mkvextract call, for --all-tracks. Tracks are named through the output template,
+// attachments go to an attachments/ directory and chapters to <basename>.chapters.xml beside them.
+func DemuxAllTracks(ctx context.Context, inputFileName string, mkvInfo *model.MKVInfo, tracks []model.MKVTrack, outputConfig model.OutputConfig) ([]model.ExtractionJob, error) {
+	outDir := naming.OutputDirectory(inputFileName, outputConfig)
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create output directory %s: %v", outDir, err)
+	}
+
+	var jobs []model.ExtractionJob
+	args := []string{"--gui-mode", inputFileName, "tracks"}
+	for _, track := range tracks {
+		outFileName, err := naming.SubtitlesFileName(inputFileName, track, outputConfig)
+		if err != nil {
+			emit(ctx, Event{Kind: EventWarning, Stage: StageDemux, File: inputFileName, Message: err.Error()})
+		}
+		jobs = append(jobs, model.ExtractionJob{
+			Track:         track,
+			OriginalTrack: track,
+			OutFileName:   outFileName,
+			MksFileName:   inputFileName,
+		})
+		args = append(args, fmt.Sprintf("%d:%s", track.Id, outFileName))
+	}
+
+	attachmentDir := filepath.Join(outDir, "attachments")
+	if len(mkvInfo.Attachments) > 0 {
+		if err := os.MkdirAll(attachmentDir, 0755); err != nil {
+			return nil, fmt.Errorf("could not create attachments directory %s: %v", attachmentDir, err)
+		}
+		args = append(args, "attachments")
+		usedNames := make(map[string]bool)
+		for _, attachment := range mkvInfo.Attachments {
+			name := filepath.Base(attachment.FileName)
+			if usedNames[strings.ToLower(name)] {
+				name = fmt.Sprintf("%d_%s", attachment.Id, name) // Keep attachments sharing a name apart
+			}
+			usedNames[strings.ToLower(name)] = true
+			args = append(args, fmt.Sprintf("%d:%s", attachment.Id, filepath.Join(attachmentDir, name)))
+		}
+	}
+
+	chaptersFile := ""
+	if len(mkvInfo.Chapters) > 0 {
+		chaptersFile = filepath.Join(outDir, naming.TrimExtension(filepath.Base(inputFileName))+".chapters.xml")
+		args = append(args, "chapters", chaptersFile)
+	}
+
+	emit(ctx, Event{Kind: EventStart, Stage: StageDemux, File: inputFileName})
+	stderrOutput, err := runWithProgress(ctx, StageDemux, inputFileName, toolPath(ctx, "mkvextract"), args...)
+	emit(ctx, Event{Kind: EventEnd, Stage: StageDemux, File: inputFileName, Err: err})
+	if err != nil {
+		emit(ctx, Event{Kind: EventError, Stage: StageDemux, File: inputFileName, Message: fmt.Sprintf("Error demuxing %s: %v", filepath.Base(inputFileName), err)})
+		reportOutput(ctx, "mkvextract stderr", stderrOutput)
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		trackWritten(ctx, StageDemux, job.OriginalTrack.Properties.Number, job.OriginalTrack, job.OutFileName)
+	}
+	if len(mkvInfo.Attachments) > 0 {
+		emit(ctx, Event{Kind: EventSuccess, Stage: StageDemux, File: inputFileName, Message: fmt.Sprintf("Extracted %d attachment(s) to %s", len(mkvInfo.Attachments), attachmentDir)})
+	}
+	if chaptersFile != "" {
+		emit(ctx, Event{Kind: EventSuccess, Stage: StageDemux, File: inputFileName, Message: fmt.Sprintf("Extracted chapters to %s", chaptersFile)})
+	}
+	emit(ctx, Event{Kind: EventSuccess, Stage: StageDemux, File: inputFileName, Message: fmt.Sprintf("Successfully extracted %d track(s)", len(jobs))})
+
+	return jobs, nil
+}
+
+// runWithProgress runs an MKVToolNix command in --gui-mode, reporting its progress lines as events of
+// the stage. It returns the command's stderr output and the warnings and errors it reported in GUI
+// mode, for error reporting.
+func runWithProgress(ctx context.Context, stage Stage, inputFileName, name string, args ...string) (string, error) {
+	cmd := command(ctx, name, args...)
+
+	// Set up pipe to capture stdout for progress monitoring
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to create stdout pipe: %v", err)
+	}
+
+	// Also capture stderr to prevent blocking if the command writes errors/warnings
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to create stderr pipe: %v", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start %s: %v", name, err)
+	}
+
+	// Start a goroutine to consume stderr to prevent blocking
+	var stderrOutput strings.Builder
+	stderrDone := make(chan struct{})
+	go func() {
+		defer close(stderrDone)
+		scanner := bufio.NewScanner(stderr)
+		// Increase buffer size for stderr as well
+		buf := make([]byte, 0, 64*1024)
+		scanner.Buffer(buf, 1024*1024)
+
+		for scanner.Scan() {
+			stderrOutput.WriteString(scanner.Text() + "\n")
+		}
+	}()
+
+	emit(ctx, Event{Kind: EventProgress, Stage: stage, File: inputFileName, Percent: 0})
+
+	// Monitor stdout for progress information and the messages GUI mode prints there
+	var guiMessages strings.Builder
+	scanner := bufio.NewScanner(stdout)
+	// Increase buffer size to handle potentially long lines
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024) // Allow up to 1MB lines
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if percentage, isProgress := parseProgressLine(line); isProgress {
+			emit(ctx, Event{Kind: EventProgress, Stage: stage, File: inputFileName, Percent: percentage})
+		} else if message, isMessage := strings.CutPrefix(line, "#GUI#error "); isMessage {
+			guiMessages.WriteString(message + "\n")
+		} else if message, isMessage := strings.CutPrefix(line, "#GUI#warning "); isMessage {
+			guiMessages.WriteString(message + "\n")
+		}
+	}
+
+	<-stderrDone
+	cmdErr := cmd.Wait()
+	return guiMessages.String() + stderrOutput.String(), cmdErr
+}
+
+// parseProgressLine extracts percentage from mkvmerge progress output
+func parseProgressLine(line string) (int, bool) {
+	// In GUI mode, progress lines look like: "#GUI#progress 45%"
+	if strings.HasPrefix(line, "#GUI#progress ") && strings.HasSuffix(line, "%") {
+		percentStr := strings.TrimPrefix(line, "#GUI#progress ")
+		percentStr = strings.TrimSuffix(percentStr, "%")
+		if percentage, err := strconv.Atoi(strings.TrimSpace(percentStr)); err == nil {
+			return percentage, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,91 @@
+package mkv
+
+import (
+	"context"
+	"fmt"
+
+	"subscalpelmkv/internal/model"
+)
+
+// EventKind tells what an Event reports
+type EventKind int
+
+const (
+	EventStart    EventKind = iota // A stage began
+	EventProgress                  // The running MKVToolNix command reported a new percentage
+	EventEnd                       // A stage finished; Err is set when it failed
+	EventTrack                     // A track was written to OutFileName
+	EventInfo                      // Message is informational
+	EventWarning                   // Message reports a problem that did not stop the stage
+	EventSuccess                   // Message summarizes finished work
+	EventError                     // Message describes a failure, reported before the error is returned
+)
+
+// Stage is a long-running step of an extraction
+type Stage int
+
+const (
+	StageAnalyze Stage = iota // mkvmerge -J reads the track list
+	StagePrepare              // mkvmerge copies the selected subtitle tracks into a temporary .mks file
+	StageExtract              // mkvextract writes the selected subtitle tracks
+	StageDemux                // mkvextract writes every track, attachment and chapter (--all-tracks)
+)
+
+// String returns the stage's name, e.g. "prepare"
+func (s Stage) String() string {
+	switch s {
+	case StageAnalyze:
+		return "analyze"
+	case StagePrepare:
+		return "prepare"
+	case StageExtract:
+		return "extract"
+	case StageDemux:
+		return "demux"
+	}
+	return fmt.Sprintf("stage(%d)", int(s))
+}
+
+// Event reports the progress of an extraction to the function set with WithProgressFunc or SetProgressFunc
+type Event struct {
+	Kind        EventKind
+	Stage       Stage
+	File        string         // Input file of the stage
+	Percent     int            // Progress of the stage from 0 to 100, for EventProgress
+	Track       model.MKVTrack // Written track, for EventTrack
+	TrackNumber int            // Track number as shown to the user, for EventTrack
+	OutFileName string         // Written file, or both file names of a VobSub track, for EventTrack
+	Message     string         // Text of EventInfo, EventWarning, EventSuccess and EventError
+	Err         error          // Failure of the stage, for EventEnd
+}
+
+// ProgressFunc receives extraction events on the goroutine running the extraction
+type ProgressFunc func(Event)
+
+var progressFunc ProgressFunc
+
+// progressFuncKey is the context key of the function set with WithProgressFunc
+type progressFuncKey struct{}
+
+// SetProgressFunc sets the function receiving extraction events of calls whose context has none set
+// with WithProgressFunc. The package prints nothing itself, so without one extraction runs silently.
+func SetProgressFunc(fn ProgressFunc) {
+	progressFunc = fn
+}
+
+// WithProgressFunc returns a context whose calls pass their extraction events to fn instead of the
+// function set with SetProgressFunc
+func WithProgressFunc(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressFuncKey{}, fn)
+}
+
+// emit passes an event to the progress function of the call, if one is set
+func emit(ctx context.Context, event Event) {
+	fn := progressFunc
+	if ctxFn, found := ctx.Value(progressFuncKey{}).(ProgressFunc); found {
+		fn = ctxFn
+	}
+	if fn != nil {
+		fn(event)
+	}
+}
@@ -1,102 +1,141 @@
 package mkv
 
 import (
-	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
-	"time"
 
-	"subscalpelmkv/internal/format"
 	"subscalpelmkv/internal/model"
-	"subscalpelmkv/internal/util"
 )
 
-// printExtractedTrackSuccess prints the extraction success message in a two-line format matching dry-run style
-func printExtractedTrackSuccess(trackNumber int, track model.MKVTrack, outFileName string) {
-	// Get codec type for display
-	codecType := "Unknown"
-	if ext, exists := model.SubtitleExtensionByCodec[track.Properties.CodecId]; exists {
-		codecType = strings.ToUpper(ext)
+// trackWritten reports an extracted track, naming both files of a VobSub track
+func trackWritten(ctx context.Context, stage Stage, trackNumber int, track model.MKVTrack, outFileName string) {
+	if track.Properties.CodecId == "S_VOBSUB" {
+		// mkvextract writes the .idx file alongside the .sub file
+		baseFileName := strings.TrimSuffix(outFileName, filepath.Ext(outFileName))
+		outFileName = fmt.Sprintf("%s + %s", filepath.Base(baseFileName+".idx"), filepath.Base(baseFileName+".sub"))
 	}
+	emit(ctx, Event{Kind: EventTrack, Stage: stage, Track: track, TrackNumber: trackNumber, OutFileName: outFileName})
+}
 
-	// Build track details string
-	trackDetails := fmt.Sprintf("Track %d (%s)", trackNumber, track.Properties.Language)
-	if track.Properties.TrackName != "" {
-		trackDetails += fmt.Sprintf(" - %s", track.Properties.TrackName)
+// GetTrackInfo gets track information from an MKV file using mkvmerge -J. The JSON is decoded as it
+// streams in, keeping only the fields MKVInfo needs, since files with many attachments or tags
+// produce megabytes of it.
+func GetTrackInfo(ctx context.Context, inputFileName string) (*model.MKVInfo, error) {
+	// mkvmerge can take a while on network storage, so report that something is happening
+	emit(ctx, Event{Kind: EventStart, Stage: StageAnalyze, File: inputFileName})
+	cmd := command(ctx, toolPath(ctx, "mkvmerge"), "-J", inputFileName)
+	stdout, cmdErr := cmd.StdoutPipe()
+	if cmdErr == nil {
+		cmdErr = cmd.Start()
 	}
-
-	// Add format and attributes
-	attributes := []string{codecType}
-	if track.Properties.Forced {
-		attributes = append(attributes, "forced")
+	var mkvInfo *model.MKVInfo
+	var jsonErr error
+	if cmdErr == nil {
+		mkvInfo, jsonErr = decodeMKVInfo(stdout)
+		io.Copy(io.Discard, stdout) // Let mkvmerge finish writing whatever was not decoded
+		cmdErr = cmd.Wait()
 	}
-	if track.Properties.Default {
-		attributes = append(attributes, "default")
+	if cmdErr != nil {
+		cmdErr = fmt.Errorf("error analyzing tracks: %v", cmdErr)
 	}
-
-	// First line: Track details with checkmark
-	format.SuccessColor.Print("  ✓ ")
-	format.BaseFg.Println(fmt.Sprintf("%s [%s]", trackDetails, strings.Join(attributes, ", ")))
-
-	// Second line: Output path with arrow
-	format.PrintExample(fmt.Sprintf("    → %s", outFileName))
-	fmt.Println()
-}
-
-// GetTrackInfo gets track information from an MKV file using mkvmerge -J
-func GetTrackInfo(inputFileName string) (*model.MKVInfo, error) {
-	out, cmdErr := exec.Command("mkvmerge", "-J", inputFileName).Output()
+	emit(ctx, Event{Kind: EventEnd, Stage: StageAnalyze, File: inputFileName, Err: cmdErr})
 	if cmdErr != nil {
-		return nil, fmt.Errorf("error analyzing tracks: %v", cmdErr)
+		return nil, cmdErr
 	}
-
-	var mkvInfo model.MKVInfo
-	jsonErr := json.Unmarshal(out, &mkvInfo)
 	if jsonErr != nil {
 		return nil, fmt.Errorf("error parsing track information: %v", jsonErr)
 	}
 
-	if !(strings.ToLower(strings.TrimSpace(mkvInfo.Container.Type)) == "matroska") {
+	containerType := strings.ToLower(strings.TrimSpace(mkvInfo.Container.Type))
+	if containerType != "matroska" && containerType != "webm" {
 		return nil, errors.New("file is not a valid Matroska container")
 	}
+	model.ClassifyRoles(mkvInfo.Tracks)
 
+	return mkvInfo, nil
+}
+
+// decodeMKVInfo decodes the fields of MKVInfo from mkvmerge -J output, skipping the other top-level
+// fields (tags, warnings, identification details) token by token instead of holding them in memory
+func decodeMKVInfo(r io.Reader) (*model.MKVInfo, error) {
+	var mkvInfo model.MKVInfo
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if token != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object, got %v", token)
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token {
+		case "container":
+			err = decoder.Decode(&mkvInfo.Container)
+		case "tracks":
+			err = decoder.Decode(&mkvInfo.Tracks)
+		case "attachments":
+			err = decoder.Decode(&mkvInfo.Attachments)
+		case "chapters":
+			err = decoder.Decode(&mkvInfo.Chapters)
+		default:
+			err = skipJSONValue(decoder)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &mkvInfo, nil
 }
 
+// skipJSONValue reads past the next value of the decoder, however deeply nested
+func skipJSONValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
 // ExtractSubtitles extracts a subtitle track from an MKV file
-func ExtractSubtitles(inputFileName string, track model.MKVTrack, outFileName string, originalTrackNumber int) error {
-	cmd := exec.Command(
-		"mkvextract",
+func ExtractSubtitles(ctx context.Context, inputFileName string, track model.MKVTrack, outFileName string, originalTrackNumber int) error {
+	cmd := command(
+		ctx,
+		toolPath(ctx, "mkvextract"),
 		fmt.Sprintf("%v", inputFileName),
 		"tracks",
 		fmt.Sprintf("%d:%v", track.Id, outFileName),
 	)
 	output, cmdErr := cmd.Output()
 	if cmdErr != nil {
-		format.PrintError(fmt.Sprintf("Error extracting track %d: %v", track.Id, cmdErr))
-		fmt.Println(string(output))
+		emit(ctx, Event{Kind: EventError, Stage: StageExtract, File: inputFileName, Message: fmt.Sprintf("Error extracting track %d: %v", track.Id, cmdErr)})
+		reportOutput(ctx, "mkvextract", string(output))
 		return cmdErr
 	}
 
-	// Handle special case for S_VOBSUB which creates both .idx and .sub files
-	if track.Properties.CodecId == "S_VOBSUB" {
-		// For VOBSUB, mkvextract creates both .idx and .sub files automatically
-		// The output filename should have .sub extension, and .idx will be created alongside it
-		baseFileName := strings.TrimSuffix(outFileName, filepath.Ext(outFileName))
-		idxFileName := baseFileName + ".idx"
-		subFileName := baseFileName + ".sub"
-		// For VOBSUB, show both files in the output path
-		combinedOutput := fmt.Sprintf("%s + %s", filepath.Base(idxFileName), filepath.Base(subFileName))
-		printExtractedTrackSuccess(originalTrackNumber, track, combinedOutput)
-	} else {
-		printExtractedTrackSuccess(originalTrackNumber, track, outFileName)
-	}
+	trackWritten(ctx, StageExtract, originalTrackNumber, track, outFileName)
 	return nil
 }
 
@@ -107,50 +146,68 @@ type TrackExtractionInfo struct {
 	OutFileName   string
 }
 
-// ExtractMultipleSubtitles extracts multiple subtitle tracks from a single input file in one mkvextract call
-func ExtractMultipleSubtitles(inputFileName string, tracks []TrackExtractionInfo) error {
+// ExtractMultipleSubtitles extracts multiple subtitle tracks from a single input file in one mkvextract call,
+// reporting its progress as it reads the file. mkvextract writes all tracks in the same pass, so they
+// are reported as written once it finishes.
+func ExtractMultipleSubtitles(ctx context.Context, inputFileName string, tracks []TrackExtractionInfo) error {
 	if len(tracks) == 0 {
 		return nil
 	}
 
-	args := []string{inputFileName, "tracks"}
+	args := []string{"--gui-mode", inputFileName, "tracks"}
 
 	for _, trackInfo := range tracks {
 		trackPair := fmt.Sprintf("%d:%s", trackInfo.Track.Id, trackInfo.OutFileName)
 		args = append(args, trackPair)
 	}
 
-	cmd := exec.Command("mkvextract", args...)
-	output, cmdErr := cmd.Output()
+	output, cmdErr := runWithProgress(ctx, StageExtract, inputFileName, toolPath(ctx, "mkvextract"), args...)
 	if cmdErr != nil {
-		format.PrintError(fmt.Sprintf("Error extracting tracks: %v", cmdErr))
-		fmt.Println(string(output))
+		emit(ctx, Event{Kind: EventError, Stage: StageExtract, File: inputFileName, Message: fmt.Sprintf("Error extracting tracks: %v", cmdErr)})
+		reportOutput(ctx, "mkvextract", output)
 		return cmdErr
 	}
 
 	for _, trackInfo := range tracks {
-		track := trackInfo.Track
-		originalTrack := trackInfo.OriginalTrack
-		outFileName := trackInfo.OutFileName
-
-		// Handle special case for S_VOBSUB which creates both .idx and .sub files
-		if track.Properties.CodecId == "S_VOBSUB" {
-			// For VOBSUB, mkvextract creates both .idx and .sub files automatically
-			// The output filename should have .sub extension, and .idx will be created alongside it
-			baseFileName := strings.TrimSuffix(outFileName, filepath.Ext(outFileName))
-			idxFileName := baseFileName + ".idx"
-			subFileName := baseFileName + ".sub"
-			// For VOBSUB, show both files in the output path
-			combinedOutput := fmt.Sprintf("%s + %s", filepath.Base(idxFileName), filepath.Base(subFileName))
-			printExtractedTrackSuccess(originalTrack.Properties.Number, track, combinedOutput)
-		} else {
-			printExtractedTrackSuccess(originalTrack.Properties.Number, track, outFileName)
-		}
+		trackWritten(ctx, StageExtract, trackInfo.OriginalTrack.Properties.Number, trackInfo.Track, trackInfo.OutFileName)
 	}
 
 	return nil
 }
 
+// ExtractFontAttachments extracts the font attachments of an MKV file into outDir
+// Fonts already present in outDir are left untouched. Returns the number of fonts written.
+func ExtractFontAttachments(ctx context.Context, inputFileName string, attachments []model.MKVAttachment, outDir string) (int, error) {
+	args := []string{inputFileName, "attachments"}
+	for _, attachment := range attachments {
+		if !attachment.IsFont() {
+			continue
+		}
+		fontPath := filepath.Join(outDir, filepath.Base(attachment.FileName))
+		if _, err := os.Stat(fontPath); err == nil {
+			continue
+		}
+		args = append(args, fmt.Sprintf("%d:%s", attachment.Id, fontPath))
+	}
+
+	fontCount := len(args) - 2
+	if fontCount == 0 {
+		return 0, nil
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return 0, fmt.Errorf("could not create fonts directory %s: %v", outDir, err)
+	}
+
+	output, cmdErr := command(ctx, toolPath(ctx, "mkvextract"), args...).Output()
+	if cmdErr != nil {
+		reportOutput(ctx, "mkvextract", string(output))
+		return 0, fmt.Errorf("error extracting font attachments: %v", cmdErr)
+	}
+
+	return fontCount, nil
+}
+
 // CleanupTempFile removes the temporary .mks file
 func CleanupTempFile(fileName string) {
 	if fileName != "" {
@@ -160,15 +217,18 @@ func CleanupTempFile(fileName string) {
 	}
 }
 
-// CreateSubtitlesMKS creates a .mks file containing only selected subtitle tracks from the input MKV file
-func CreateSubtitlesMKS(inputFileName string, selection model.TrackSelection, matchesTrackSelection func(model.MKVTrack, model.TrackSelection) bool, outputConfig model.OutputConfig) (string, error) {
+// CreateSubtitlesMKS creates a .mks file containing only the given subtitle tracks of the input MKV file,
+// as analyzed by the caller. It returns the tracks as they appear in the .mks: mkvmerge keeps their
+// order in the source file and numbers them from 0, so the .mks needs no analysis of its own. The
+// returned tracks correspond to the given ones by index.
+func CreateSubtitlesMKS(ctx context.Context, inputFileName string, tracks []model.MKVTrack, outputConfig model.OutputConfig) (string, []model.MKVTrack, error) {
 	// Create temporary .mks file path - use the same directory as the output files
 	var dir string
 	if outputConfig.OutputDir != "" {
 		dir = outputConfig.OutputDir
 		// Always create output directory if it doesn't exist
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			format.PrintWarning(fmt.Sprintf("Could not create output directory %s: %v", dir, err))
+			emit(ctx, Event{Kind: EventWarning, Stage: StagePrepare, File: inputFileName, Message: fmt.Sprintf("Could not create output directory %s: %v", dir, err)})
 			// Fall back to input file directory
 			dir = filepath.Dir(inputFileName)
 		}
@@ -178,26 +238,29 @@ func CreateSubtitlesMKS(inputFileName string, selection model.TrackSelection, ma
 	baseName := strings.TrimSuffix(filepath.Base(inputFileName), filepath.Ext(inputFileName))
 	mksFileName := filepath.Join(dir, baseName+".subtitles.mks")
 
-	format.PrintStep(1, "Preparing selected tracks for extraction...")
+	emit(ctx, Event{Kind: EventStart, Stage: StagePrepare, File: inputFileName})
 
-	// First, get track information from the original file to determine which tracks to include
-	originalMkvInfo, err := GetTrackInfo(inputFileName)
-	if err != nil {
-		return "", fmt.Errorf("failed to analyze original file: %v", err)
+	if len(tracks) == 0 {
+		return "", nil, fmt.Errorf("no subtitle tracks match the specified selection criteria")
 	}
 
-	// Build list of subtitle track IDs that match the selection criteria
-	var selectedTrackIDs []string
-	for _, track := range originalMkvInfo.Tracks {
-		if track.Type == "subtitles" {
-			if matchesTrackSelection(track, selection) {
-				selectedTrackIDs = append(selectedTrackIDs, strconv.Itoa(track.Id))
-			}
-		}
+	// Track IDs in the .mks follow the order of the tracks in the source file
+	sourceIDs := make([]int, len(tracks))
+	for i, track := range tracks {
+		sourceIDs[i] = track.Id
+	}
+	slices.Sort(sourceIDs)
+	mksTracks := make([]model.MKVTrack, len(tracks))
+	for i, track := range tracks {
+		mksTracks[i] = track
+		mksTracks[i].Id, _ = slices.BinarySearch(sourceIDs, track.Id)
 	}
 
-	if len(selectedTrackIDs) == 0 {
-		return "", fmt.Errorf("no subtitle tracks match the specified selection criteria")
+	var selectedTrackIDs, displayTrackNumbers []string
+	for _, track := range tracks {
+		selectedTrackIDs = append(selectedTrackIDs, strconv.Itoa(track.Id))
+		// Track numbers are shown to the user, track IDs are what mkvmerge takes
+		displayTrackNumbers = append(displayTrackNumbers, strconv.Itoa(track.Properties.Number))
 	}
 
 	// Build mkvmerge command with track selection
@@ -210,122 +273,27 @@ func CreateSubtitlesMKS(inputFileName string, selection model.TrackSelection, ma
 		"--no-attachments",
 		"--no-global-tags",
 		"--no-track-tags",
+		"--subtitle-tracks", strings.Join(selectedTrackIDs, ","),
 	}
-
-	// Add subtitle track selection - always specify which tracks to include when we have selections or exclusions
-	hasSelectionCriteria := len(selection.LanguageCodes) > 0 || len(selection.TrackNumbers) > 0 || len(selection.FormatFilters) > 0
-	hasExclusionCriteria := len(selection.Exclusions.LanguageCodes) > 0 || len(selection.Exclusions.TrackNumbers) > 0 || len(selection.Exclusions.FormatFilters) > 0
-	
-	if hasSelectionCriteria || hasExclusionCriteria {
-		subtitleTracks := strings.Join(selectedTrackIDs, ",")
-		args = append(args, "--subtitle-tracks", subtitleTracks)
-
-		// Build display list using track.Properties.Number for user-friendly output
-		var displayTrackNumbers []string
-		for _, track := range originalMkvInfo.Tracks {
-			if track.Type == "subtitles" {
-				if matchesTrackSelection(track, selection) {
-					displayTrackNumbers = append(displayTrackNumbers, strconv.Itoa(track.Properties.Number))
-				}
-			}
-		}
-		format.PrintInfo(fmt.Sprintf("Including subtitle tracks: %s", strings.Join(displayTrackNumbers, ",")))
-	}
+	emit(ctx, Event{Kind: EventInfo, Stage: StagePrepare, File: inputFileName, Message: fmt.Sprintf("Including subtitle tracks: %s", strings.Join(displayTrackNumbers, ","))})
 
 	args = append(args, inputFileName)
-	cmd := exec.Command("mkvmerge", args...)
-
-	// Set up pipe to capture stdout for progress monitoring
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return "", fmt.Errorf("failed to create stdout pipe: %v", err)
-	}
-
-	// Also capture stderr to prevent blocking if mkvmerge writes errors/warnings
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return "", fmt.Errorf("failed to create stderr pipe: %v", err)
-	}
-
-	if err := cmd.Start(); err != nil {
-		return "", fmt.Errorf("failed to start mkvmerge: %v", err)
-	}
-
-	// Start a goroutine to consume stderr to prevent blocking
-	var stderrOutput strings.Builder
-	go func() {
-		scanner := bufio.NewScanner(stderr)
-		// Increase buffer size for stderr as well
-		buf := make([]byte, 0, 64*1024)
-		scanner.Buffer(buf, 1024*1024)
-		
-		for scanner.Scan() {
-			stderrOutput.WriteString(scanner.Text() + "\n")
-		}
-	}()
-
-	// Hide cursor for cleaner progress display
-	fmt.Print("\033[?25l")
-
-	// Show initial 0% progress bar immediately
-	util.ShowProgressBar(0)
-
-	// Create a ticker to update elapsed time every 100ms
-	ticker := time.NewTicker(100 * time.Millisecond)
-	done := make(chan bool)
-	
-	// Start goroutine to update elapsed time
-	go func() {
-		for {
-			select {
-			case <-ticker.C:
-				util.UpdateElapsedTime()
-			case <-done:
-				ticker.Stop()
-				return
-			}
-		}
-	}()
-
-	// Monitor stdout for progress information
-	scanner := bufio.NewScanner(stdout)
-	// Increase buffer size to handle potentially long lines
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024) // Allow up to 1MB lines
-	
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		if percentage, isProgress := util.ParseProgressLine(line); isProgress {
-			util.ShowProgressBar(percentage)
-		}
-	}
-
-	// Stop the ticker
-	done <- true
-	cmdErr := cmd.Wait()
-
-	// Show cursor again
-	fmt.Print("\033[?25h")
-
+	stderrOutput, cmdErr := runWithProgress(ctx, StagePrepare, inputFileName, toolPath(ctx, "mkvmerge"), args...)
+	emit(ctx, Event{Kind: EventEnd, Stage: StagePrepare, File: inputFileName, Err: cmdErr})
 	if cmdErr != nil {
-		// Clear the progress line before showing error
-		fmt.Print("\r\033[K")
-		format.PrintError(fmt.Sprintf("Error creating temporary subtitle file: %v", cmdErr))
-		// If there was stderr output, display it for debugging
-		if stderrStr := stderrOutput.String(); stderrStr != "" {
-			format.PrintError(fmt.Sprintf("mkvmerge stderr: %s", strings.TrimSpace(stderrStr)))
-		}
-		return "", cmdErr
+		emit(ctx, Event{Kind: EventError, Stage: StagePrepare, File: inputFileName, Message: fmt.Sprintf("Error creating temporary subtitle file: %v", cmdErr)})
+		// If there was stderr output, report it for debugging
+		reportOutput(ctx, "mkvmerge stderr", stderrOutput)
+		return "", nil, cmdErr
 	}
 
-	return mksFileName, nil
+	return mksFileName, mksTracks, nil
 }
 
 // ProcessTracks groups extraction jobs by input file and processes them efficiently
-func ProcessTracks(jobs []model.ExtractionJob) error {
+func ProcessTracks(ctx context.Context, jobs []model.ExtractionJob) error {
 	if len(jobs) == 0 {
-		format.PrintWarning("No subtitle tracks to extract")
+		emit(ctx, Event{Kind: EventWarning, Stage: StageExtract, Message: "No subtitle tracks to extract"})
 		return nil
 	}
 
@@ -346,19 +314,68 @@ func ProcessTracks(jobs []model.ExtractionJob) error {
 	successCount := 0
 
 	for inputFile, tracks := range jobsByInputFile {
-		err := ExtractMultipleSubtitles(inputFile, tracks)
+		emit(ctx, Event{Kind: EventStart, Stage: StageExtract, File: inputFile})
+		err := ExtractMultipleSubtitles(ctx, inputFile, tracks)
+		emit(ctx, Event{Kind: EventEnd, Stage: StageExtract, File: inputFile, Err: err})
 		if err != nil {
-			format.PrintError(fmt.Sprintf("Error extracting tracks from %s: %v", inputFile, err))
+			emit(ctx, Event{Kind: EventError, Stage: StageExtract, File: inputFile, Message: fmt.Sprintf("Error extracting tracks from %s: %v", inputFile, err)})
 			return err
 		}
 		successCount += len(tracks)
 	}
 
 	if successCount == 0 {
-		format.PrintWarning("No subtitle tracks were extracted")
+		emit(ctx, Event{Kind: EventWarning, Stage: StageExtract, Message: "No subtitle tracks were extracted"})
 	} else {
-		format.PrintSuccess(fmt.Sprintf("Successfully extracted %d subtitle track(s)", successCount))
+		emit(ctx, Event{Kind: EventSuccess, Stage: StageExtract, Message: fmt.Sprintf("Successfully extracted %d subtitle track(s)", successCount)})
 	}
 
 	return nil
 }
+
+// reportOutput reports the output of a failed MKVToolNix command, if it printed any
+func reportOutput(ctx context.Context, label, output string) {
+	if output = strings.TrimSpace(output); output != "" {
+		emit(ctx, Event{Kind: EventError, Message: fmt.Sprintf("%s: %s", label, output)})
+	}
+}
+
+// ToolNames lists the MKVToolNix programs SubScalpelMKV depends on
+var ToolNames = []string{"mkvmerge", "mkvextract", "mkvpropedit"}
+
+// RequiredToolNames lists the programs extraction cannot run without
+var RequiredToolNames = []string{"mkvmerge", "mkvextract"}
+
+// toolPathsKey is the context key of the programs set with WithToolPaths
+type toolPathsKey struct{}
+
+// WithToolPaths returns a context whose calls run MKVToolNix programs from the given paths, by tool
+// name (e.g. "mkvextract"), instead of looking them up in PATH. Tools without a path are looked up.
+func WithToolPaths(ctx context.Context, paths map[string]string) context.Context {
+	return context.WithValue(ctx, toolPathsKey{}, paths)
+}
+
+// toolPath returns the program a call runs for an MKVToolNix tool
+func toolPath(ctx context.Context, tool string) string {
+	paths, _ := ctx.Value(toolPathsKey{}).(map[string]string)
+	if path := paths[tool]; path != "" {
+		return path
+	}
+	return tool
+}
+
+// ToolVersion returns the first line of `<tool> --version`, e.g. "mkvmerge v80.0 ('Roundabout') 64-bit"
+func ToolVersion(ctx context.Context, tool string) (string, error) {
+	path, err := exec.LookPath(toolPath(ctx, tool))
+	if err != nil {
+		return "", fmt.Errorf("not found in PATH")
+	}
+
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s --version: %v", path, err)
+	}
+
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(firstLine), nil
+}
@@ -0,0 +1,30 @@
+package mkv
+
+import (
+	"context"
+	"os/exec"
+)
+
+// lowPriority is set with SetLowPriority
+var lowPriority bool
+
+// SetLowPriority runs mkvmerge and mkvextract with reduced CPU and I/O priority, so long runs leave
+// room for other programs such as media servers. It returns an error if the platform offers no way
+// to do so, in which case the tools keep running at normal priority.
+func SetLowPriority(enabled bool) error {
+	if enabled {
+		if err := checkLowPriority(); err != nil {
+			return err
+		}
+	}
+	lowPriority = enabled
+	return nil
+}
+
+// command creates the command running an MKVToolNix program, at low priority if requested
+func command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if !lowPriority {
+		return exec.CommandContext(ctx, name, args...)
+	}
+	return lowPriorityCommand(ctx, name, args...)
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package mkv
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// checkLowPriority reports whether commands can be run at low priority, which needs nice
+func checkLowPriority() error {
+	if _, err := exec.LookPath("nice"); err != nil {
+		return fmt.Errorf("nice not found in PATH")
+	}
+	return nil
+}
+
+// lowPriorityCommand runs the program through nice and, where available (Linux), ionice in the
+// lowest best-effort class. Both replace themselves with the program, so cancelling ctx still
+// stops it.
+func lowPriorityCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	wrapped := append([]string{"-n", "19", name}, args...)
+	if _, err := exec.LookPath("ionice"); err == nil {
+		return exec.CommandContext(ctx, "ionice", append([]string{"-c", "2", "-n", "7", "nice"}, wrapped...)...)
+	}
+	return exec.CommandContext(ctx, "nice", wrapped...)
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package mkv
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// belowNormalPriorityClass is BELOW_NORMAL_PRIORITY_CLASS of CreateProcess
+const belowNormalPriorityClass = 0x00004000
+
+// checkLowPriority reports whether commands can be run at low priority; Windows always can
+func checkLowPriority() error {
+	return nil
+}
+
+// lowPriorityCommand starts the program in the below-normal priority class, which Windows also
+// uses for its I/O
+func lowPriorityCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: belowNormalPriorityClass}
+	return cmd
+}
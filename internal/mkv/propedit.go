@@ -0,0 +1,25 @@
+package mkv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"subscalpelmkv/internal/model"
+)
+
+// SetTrackLanguages writes the language of each track into the MKV file in place with mkvpropedit
+func SetTrackLanguages(ctx context.Context, inputFileName string, tracks []model.MKVTrack) error {
+	if len(tracks) == 0 {
+		return nil
+	}
+
+	args := []string{inputFileName}
+	for _, track := range tracks {
+		args = append(args, "--edit", fmt.Sprintf("track:@%d", track.Properties.Number), "--set", "language="+track.Properties.Language)
+	}
+	if output, err := command(ctx, toolPath(ctx, "mkvpropedit"), args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("mkvpropedit failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
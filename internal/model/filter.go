@@ -0,0 +1,469 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TrackFilter is a parsed --filter expression such as 'lang==eng && !forced && format in (srt,ass)'.
+// Unlike the comma lists of --select, which OR their items, it can require several conditions at once.
+//
+// Conditions compare a field with ==, !=, <, <=, >, >=, in (a, b) or contains, and are combined with
+// &&, || and ! and grouped with parentheses. Fields:
+//
+//	lang, format, name, codec    strings; lang and format match as in --select, name and codec ignore case
+//	track, entries               numbers: the track number and the number of index entries (cues)
+//	forced, default, enabled,    flags, used on their own: 'forced', '!default'
+//	text, image
+type TrackFilter struct {
+	Expression string
+	root       filterNode
+}
+
+// ParseTrackFilter parses a filter expression
+func ParseTrackFilter(expression string) (*TrackFilter, error) {
+	tokens, err := tokenizeFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter expression")
+	}
+
+	parser := &filterParser{tokens: tokens}
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !parser.done() {
+		return nil, fmt.Errorf("unexpected '%s' in filter expression", parser.peek().text)
+	}
+	return &TrackFilter{Expression: expression, root: root}, nil
+}
+
+// Matches reports whether a track satisfies the filter
+func (f *TrackFilter) Matches(track MKVTrack) bool {
+	return f.root.matches(track)
+}
+
+// NameContainsFilter returns a filter matching tracks whose name contains text, ignoring case, or
+// with exclude, those whose name does not
+func NameContainsFilter(text string, exclude bool) *TrackFilter {
+	var root filterNode = filterCondition{field: "name", operator: "contains", values: []string{text}}
+	expression := fmt.Sprintf("name contains %q", text)
+	if exclude {
+		root = filterNot{root}
+		expression = "!(" + expression + ")"
+	}
+	return &TrackFilter{Expression: expression, root: root}
+}
+
+// MinEntriesFilter returns a filter matching tracks with at least minEntries index entries. Tracks
+// for which mkvmerge reports no index entries are kept, since their count is unknown.
+func MinEntriesFilter(minEntries int) *TrackFilter {
+	count := strconv.Itoa(minEntries)
+	return &TrackFilter{
+		Expression: fmt.Sprintf("entries >= %d || entries == 0", minEntries),
+		root: filterOr{
+			filterCondition{field: "entries", operator: ">=", values: []string{count}},
+			filterCondition{field: "entries", operator: "==", values: []string{"0"}},
+		},
+	}
+}
+
+// MinCoverageFilter returns a filter matching tracks whose duration covers at least percent of the
+// container's, which skips partial and credits-only tracks. Tracks without a duration tag are kept,
+// as are all tracks when the container duration is unknown.
+func MinCoverageFilter(percent int, containerDuration time.Duration) *TrackFilter {
+	return &TrackFilter{
+		Expression: fmt.Sprintf("coverage >= %d%%", percent),
+		root:       filterCoverage{percent: percent, containerDuration: containerDuration},
+	}
+}
+
+type filterCoverage struct {
+	percent           int
+	containerDuration time.Duration
+}
+
+func (n filterCoverage) matches(track MKVTrack) bool {
+	trackDuration, ok := track.TagDuration()
+	if !ok || n.containerDuration <= 0 {
+		return true
+	}
+	return trackDuration*100 >= n.containerDuration*time.Duration(n.percent)
+}
+
+// CombineTrackFilters returns a filter requiring all of the given filters, skipping nil ones
+func CombineTrackFilters(filters ...*TrackFilter) *TrackFilter {
+	var combined *TrackFilter
+	for _, filter := range filters {
+		switch {
+		case filter == nil:
+		case combined == nil:
+			combined = filter
+		default:
+			combined = &TrackFilter{
+				Expression: fmt.Sprintf("(%s) && (%s)", combined.Expression, filter.Expression),
+				root:       filterAnd{combined.root, filter.root},
+			}
+		}
+	}
+	return combined
+}
+
+// filterFieldKind tells which operators a filter field supports
+type filterFieldKind int
+
+const (
+	filterString filterFieldKind = iota
+	filterNumber
+	filterFlag
+)
+
+var filterFields = map[string]filterFieldKind{
+	"lang":    filterString,
+	"format":  filterString,
+	"name":    filterString,
+	"codec":   filterString,
+	"track":   filterNumber,
+	"entries": filterNumber,
+	"forced":  filterFlag,
+	"default": filterFlag,
+	"enabled": filterFlag,
+	"text":    filterFlag,
+	"image":   filterFlag,
+}
+
+// filterNode is a node of a parsed filter expression
+type filterNode interface {
+	matches(track MKVTrack) bool
+}
+
+type filterAnd struct{ left, right filterNode }
+
+func (n filterAnd) matches(track MKVTrack) bool {
+	return n.left.matches(track) && n.right.matches(track)
+}
+
+type filterOr struct{ left, right filterNode }
+
+func (n filterOr) matches(track MKVTrack) bool {
+	return n.left.matches(track) || n.right.matches(track)
+}
+
+type filterNot struct{ operand filterNode }
+
+func (n filterNot) matches(track MKVTrack) bool { return !n.operand.matches(track) }
+
+type filterFlagNode struct{ field string }
+
+func (n filterFlagNode) matches(track MKVTrack) bool {
+	switch n.field {
+	case "forced":
+		return track.Properties.Forced
+	case "default":
+		return track.Properties.Default
+	case "enabled":
+		return track.Properties.Enabled
+	case "text":
+		return !IsImageSubtitleCodec(track.Properties.CodecId)
+	case "image":
+		return IsImageSubtitleCodec(track.Properties.CodecId)
+	}
+	return false
+}
+
+// filterCondition compares a string or number field with one value, or with any of several for "in"
+type filterCondition struct {
+	field    string
+	operator string
+	values   []string
+}
+
+func (n filterCondition) matches(track MKVTrack) bool {
+	if filterFields[n.field] == filterNumber {
+		return n.matchesNumber(track)
+	}
+
+	matched := false
+	for _, value := range n.values {
+		if n.matchesString(track, value) {
+			matched = true
+			break
+		}
+	}
+	if n.operator == "!=" {
+		return !matched
+	}
+	return matched
+}
+
+// matchesString checks a string field against one value, ignoring != which the caller applies
+func (n filterCondition) matchesString(track MKVTrack, value string) bool {
+	switch n.field {
+	case "lang":
+		return MatchesLanguageFilter(track.LanguageTag(), value)
+	case "format":
+		return MatchesFormatFilter(track.Properties.CodecId, value)
+	case "name":
+		if n.operator == "contains" {
+			return strings.Contains(strings.ToLower(track.Properties.TrackName), strings.ToLower(value))
+		}
+		return strings.EqualFold(track.Properties.TrackName, value)
+	case "codec":
+		if n.operator == "contains" {
+			return strings.Contains(strings.ToLower(track.Properties.CodecId), strings.ToLower(value))
+		}
+		return strings.EqualFold(track.Properties.CodecId, value)
+	}
+	return false
+}
+
+// matchesNumber compares a number field; values were checked to be integers while parsing
+func (n filterCondition) matchesNumber(track MKVTrack) bool {
+	actual := track.Properties.Number
+	if n.field == "entries" {
+		actual = track.Properties.NumberOfIndexEntries
+	}
+
+	for _, value := range n.values {
+		expected, _ := strconv.Atoi(value)
+		var matched bool
+		switch n.operator {
+		case "==", "in":
+			matched = actual == expected
+		case "!=":
+			matched = actual != expected
+		case "<":
+			matched = actual < expected
+		case "<=":
+			matched = actual <= expected
+		case ">":
+			matched = actual > expected
+		case ">=":
+			matched = actual >= expected
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// filterToken is a word, quoted string or operator of a filter expression
+type filterToken struct {
+	text   string
+	quoted bool // Quoted strings are always values, never operators or keywords
+}
+
+// tokenizeFilter splits a filter expression into tokens
+func tokenizeFilter(expression string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string in filter expression")
+			}
+			tokens = append(tokens, filterToken{text: string(runes[i+1 : end]), quoted: true})
+			i = end + 1
+		case strings.ContainsRune("()!<>=&|,", r):
+			operator := string(r)
+			if i+1 < len(runes) {
+				switch pair := string(runes[i : i+2]); pair {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					operator = pair
+				}
+			}
+			if operator == "=" || operator == "&" || operator == "|" {
+				return nil, fmt.Errorf("unknown operator '%s' in filter expression (use ==, && or ||)", operator)
+			}
+			tokens = append(tokens, filterToken{text: operator})
+			i += len(operator)
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("()!<>=&|,'\"", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, filterToken{text: string(runes[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// filterParser is a recursive descent parser over the tokens of a filter expression
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() filterToken {
+	if p.done() {
+		return filterToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+// accept consumes the next token if it is the given unquoted operator or keyword
+func (p *filterParser) accept(text string) bool {
+	if token := p.peek(); !p.done() && !token.quoted && token.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (filterNode, error) {
+	if p.accept("!") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (filterNode, error) {
+	if p.done() {
+		return nil, fmt.Errorf("filter expression ends unexpectedly")
+	}
+	if p.accept("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ')' in filter expression")
+		}
+		return node, nil
+	}
+
+	token := p.peek()
+	field := strings.ToLower(token.text)
+	kind, known := filterFields[field]
+	if token.quoted || !known {
+		return nil, fmt.Errorf("unknown field '%s' in filter expression", token.text)
+	}
+	p.pos++
+	if kind == filterFlag {
+		return filterFlagNode{field}, nil
+	}
+
+	operator := p.peek().text
+	switch {
+	case p.accept("in"):
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return newFilterCondition(field, kind, "in", values)
+	case p.accept("contains"):
+		if kind != filterString {
+			return nil, fmt.Errorf("'contains' needs a text field, not '%s'", field)
+		}
+	case p.accept("=="), p.accept("!="):
+	case p.accept("<"), p.accept("<="), p.accept(">"), p.accept(">="):
+		if kind != filterNumber {
+			return nil, fmt.Errorf("'%s' needs a number field, not '%s'", operator, field)
+		}
+	default:
+		return nil, fmt.Errorf("expected an operator after '%s' in filter expression", field)
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return newFilterCondition(field, kind, operator, []string{value})
+}
+
+// parseList parses the parenthesized, comma-separated values of "in"
+func (p *filterParser) parseList() ([]string, error) {
+	if !p.accept("(") {
+		return nil, fmt.Errorf("expected '(' after 'in' in filter expression")
+	}
+	var values []string
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if p.accept(")") {
+			return values, nil
+		}
+		if !p.accept(",") {
+			return nil, fmt.Errorf("expected ',' or ')' in filter expression")
+		}
+	}
+}
+
+// parseValue parses a bare word or quoted string
+func (p *filterParser) parseValue() (string, error) {
+	token := p.peek()
+	if p.done() || (!token.quoted && strings.ContainsAny(token.text, "()!<>=&|,")) {
+		return "", fmt.Errorf("expected a value in filter expression")
+	}
+	p.pos++
+	return token.text, nil
+}
+
+// newFilterCondition checks that the values of a number field are integers
+func newFilterCondition(field string, kind filterFieldKind, operator string, values []string) (filterNode, error) {
+	if kind == filterNumber {
+		for _, value := range values {
+			if _, err := strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("'%s' needs a number, not '%s'", field, value)
+			}
+		}
+	}
+	return filterCondition{field: field, operator: operator, values: values}, nil
+}
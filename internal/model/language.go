@@ -0,0 +1,324 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Code styles of {language}, as accepted by --lang-style
+const (
+	LanguageStyleISO6391  = "iso639-1"  // en, de; languages without a two-letter code keep their three-letter code
+	LanguageStyleISO6392B = "iso639-2b" // eng, ger: the bibliographic codes Matroska uses
+	LanguageStyleISO6392T = "iso639-2t" // eng, deu: the terminology codes most other tools expect
+	LanguageStyleName     = "name"      // English, German, as {languagename}
+)
+
+// bibliographicCodes maps the ISO 639-2/T codes whose bibliographic (B) code differs to that code
+var bibliographicCodes = map[string]string{
+	"bod": "tib", "ces": "cze", "cym": "wel", "deu": "ger", "ell": "gre",
+	"eus": "baq", "fas": "per", "fra": "fre", "hye": "arm", "isl": "ice",
+	"kat": "geo", "mkd": "mac", "mri": "mao", "msa": "may", "mya": "bur",
+	"nld": "dut", "ron": "rum", "slk": "slo", "sqi": "alb", "zho": "chi",
+}
+
+// ValidateLanguageStyle checks that style is empty or one of the language code styles
+func ValidateLanguageStyle(style string) error {
+	switch style {
+	case "", LanguageStyleISO6391, LanguageStyleISO6392B, LanguageStyleISO6392T, LanguageStyleName:
+		return nil
+	}
+	return fmt.Errorf("unknown language style '%s' (supported: %s, %s, %s, %s)", style, LanguageStyleISO6391, LanguageStyleISO6392B, LanguageStyleISO6392T, LanguageStyleName)
+}
+
+// FormatLanguageCode writes a track's language code in a code style; locale names the language for
+// LanguageStyleName as in GetLocalizedLanguageName. An empty style, und and codes that are not
+// ISO 639 are returned as they are.
+func FormatLanguageCode(code, style, locale string) string {
+	if style == "" {
+		return code
+	}
+	tag, ok := parseLanguage(code)
+	if !ok {
+		return code
+	}
+	if style == LanguageStyleName {
+		return GetLocalizedLanguageName(code, locale)
+	}
+
+	base, _ := tag.Base()
+	switch style {
+	case LanguageStyleISO6391:
+		return base.String()
+	case LanguageStyleISO6392B:
+		if bibliographic, exists := bibliographicCodes[base.ISO3()]; exists {
+			return bibliographic
+		}
+	}
+	return base.ISO3()
+}
+
+// macrolanguageMembers maps individual languages to the macrolanguage they belong to, for the members
+// x/text does not fold into it because they are not its dominant language (cmn and zh, arb and ar are
+// folded already)
+var macrolanguageMembers = map[string]string{
+	// Chinese
+	"yue": "zh", "wuu": "zh", "nan": "zh", "hak": "zh", "hsn": "zh", "gan": "zh", "cdo": "zh",
+	"cjy": "zh", "cpx": "zh", "czh": "zh", "czo": "zh", "mnp": "zh", "lzh": "zh",
+	// Arabic
+	"arz": "ar", "apc": "ar", "ajp": "ar", "ary": "ar", "arq": "ar", "aeb": "ar", "acm": "ar",
+	"afb": "ar", "ars": "ar", "ayl": "ar", "apd": "ar",
+	// Norwegian and Persian
+	"nn": "no", "prs": "fa",
+}
+
+// parseLanguage parses a language code or tag, with deprecated and bibliographic codes replaced (iw is
+// he, ger is de). ok is false for und, empty and unknown codes.
+func parseLanguage(code string) (language.Tag, bool) {
+	tag, err := language.Parse(strings.TrimSpace(code))
+	if err != nil || tag == language.Und {
+		return language.Und, false
+	}
+	return tag, true
+}
+
+// baseLanguage returns the base language of a tag, such as zh for zh-Hant
+func baseLanguage(tag language.Tag) string {
+	base, _ := tag.Base()
+	return base.String()
+}
+
+// canonicalLanguage is the base language of a tag with macrolanguages folded as well (cmn and nob
+// become zh and no), under which tags of one language compare equal
+func canonicalLanguage(tag language.Tag) string {
+	tag, _ = language.All.Canonicalize(tag)
+	return baseLanguage(tag)
+}
+
+// languageSubtags returns the script and region subtags of a tag worth keeping in file names, such
+// as "-Hant" or "-419": the script when given, and the region unless it is the language's usual one
+// (en-US, de-DE, pt-BR)
+func languageSubtags(tag language.Tag) string {
+	var subtags string
+	if script, confidence := tag.Script(); confidence == language.Exact {
+		subtags += "-" + script.String()
+	}
+	if region, confidence := tag.Region(); confidence == language.Exact {
+		if usual, _ := language.Make(baseLanguage(tag)).Region(); usual != region {
+			subtags += "-" + region.String()
+		}
+	}
+	return subtags
+}
+
+// nameSubtags lists words in track names that give a language's script or region, for tracks whose
+// language_ietf does not. The first word the lowercased name contains applies.
+var nameSubtags = []struct {
+	language string
+	words    []string
+	subtags  string
+}{
+	{"zh", []string{"traditional", "繁", "cht", "big5"}, "-Hant"},
+	{"zh", []string{"simplified", "简", "簡", "chs", "gb"}, "-Hans"},
+	{"pt", []string{"brazil", "brasil"}, "-BR"},
+	{"pt", []string{"portugal", "european", "europeu"}, "-PT"},
+	{"es", []string{"latin", "latino", "latinoamérica", "419"}, "-419"},
+	{"es", []string{"spain", "españa", "castilian", "castellano", "european"}, "-ES"},
+	{"fr", []string{"canad", "québec", "quebec"}, "-CA"},
+}
+
+// LanguageTag returns the BCP 47 tag of the track's language: its language_ietf when that has a
+// script or region, otherwise its language refined by the script or region its name implies, such
+// as zh-Hant for "Traditional Chinese" or pt-BR for "Português (Brasil)"
+func (t MKVTrack) LanguageTag() string {
+	code := t.Properties.Language
+	if t.Properties.LanguageIETF != "" {
+		code = t.Properties.LanguageIETF
+	}
+	tag, ok := parseLanguage(code)
+	if !ok {
+		return code
+	}
+	_, scriptConfidence := tag.Script()
+	_, regionConfidence := tag.Region()
+	if scriptConfidence == language.Exact || regionConfidence == language.Exact {
+		return code
+	}
+
+	name := strings.ToLower(t.Properties.TrackName)
+	for _, entry := range nameSubtags {
+		if entry.language != baseLanguage(tag) {
+			continue
+		}
+		for _, word := range entry.words {
+			if strings.Contains(name, word) {
+				return baseLanguage(tag) + entry.subtags
+			}
+		}
+	}
+	return code
+}
+
+// LanguageSubtags returns the script and region subtags of the track's LanguageTag that set it apart
+// from other tracks of its language, such as "-Hant" or "-419", or an empty string
+func (t MKVTrack) LanguageSubtags() string {
+	tag, ok := parseLanguage(t.LanguageTag())
+	if !ok {
+		return ""
+	}
+	return languageSubtags(tag)
+}
+
+// IsLanguageCode checks if a selection item is a two- or three-letter ISO 639 language code, such as
+// en, eng, ger, deu or yue, optionally with script and region subtags (zh-Hant, pt-BR, es-419).
+// Subtitle format names that are also ISO 639-3 codes (ass, srt, sub) are format filters, and status
+// keywords that are (all) are status filters, not languages.
+func IsLanguageCode(item string) bool {
+	base, subtags, _ := strings.Cut(item, "-")
+	if len(base) != 2 && len(base) != 3 {
+		return false
+	}
+	if IsTrackStatusFilter(item) {
+		return false
+	}
+	lowerBase := strings.ToLower(base)
+	for _, ext := range SubtitleExtensionByCodec {
+		if lowerBase == ext && subtags == "" {
+			return false
+		}
+	}
+	_, ok := parseLanguage(item)
+	return ok
+}
+
+// LanguageKey returns the key under which tracks of the same language group, whichever code they are
+// tagged with: eng and en, ger and deu, or cmn and zh give the same key. Scripts and regions that set
+// a tag apart (zh-Hant, pt-PT) are part of the key. A region that implies an unusual script is keyed
+// by the script, as MatchesLanguageFilter compares them, so zh-TW, zh-Hant and zh-Hant-TW group
+// together. Unknown codes are their own key.
+func LanguageKey(code string) string {
+	if tag, ok := parseLanguage(code); ok {
+		return canonicalLanguage(tag) + languageKeySubtags(tag)
+	}
+	return strings.ToLower(code)
+}
+
+// languageKeySubtags returns the subtags of a tag's LanguageKey: the script a region implies when it
+// is not the language's usual one and the tag names no other, otherwise languageSubtags
+func languageKeySubtags(tag language.Tag) string {
+	region, regionConfidence := tag.Region()
+	if regionConfidence != language.Exact {
+		return languageSubtags(tag)
+	}
+	regionScript, _ := language.Make(baseLanguage(tag) + "-" + region.String()).Script()
+	usualScript, _ := language.Make(baseLanguage(tag)).Script()
+	script, scriptConfidence := tag.Script()
+	if regionScript != usualScript && (scriptConfidence != language.Exact || script == regionScript) {
+		return "-" + regionScript.String()
+	}
+	return languageSubtags(tag)
+}
+
+// MatchesLanguageFilter checks if a track's language tag (see MKVTrack.LanguageTag) matches the
+// specified filter. Codes are compared by language, so 2-letter, bibliographic, terminology and
+// deprecated codes of a language match each other, and a macrolanguage filter such as zh or ar
+// matches its individual languages (cmn, yue, arz). A filter with a script or region, such as zh-Hant
+// or pt-BR, only matches tracks tagged with them.
+func MatchesLanguageFilter(trackLanguage, filterLanguage string) bool {
+	if filterLanguage == "" {
+		return true // No filter specified, match all
+	}
+
+	if strings.EqualFold(trackLanguage, filterLanguage) {
+		return true
+	}
+
+	trackTag, trackOK := parseLanguage(trackLanguage)
+	filterTag, filterOK := parseLanguage(filterLanguage)
+	if !trackOK || !filterOK {
+		return false
+	}
+	if !matchesSubtags(trackTag, filterTag) {
+		return false
+	}
+	if canonicalLanguage(trackTag) == canonicalLanguage(filterTag) {
+		return true
+	}
+
+	// The filter's own base is used here, since folding would let nb (Bokmål) match nn (Nynorsk) via no
+	return macrolanguageMembers[canonicalLanguage(trackTag)] == baseLanguage(filterTag)
+}
+
+// matchesSubtags checks that a track's tag has the script and region a filter names. A region also
+// gives the script, as zh-TW is written in Traditional Chinese.
+func matchesSubtags(track, filter language.Tag) bool {
+	if script, confidence := filter.Script(); confidence == language.Exact {
+		trackScript, trackConfidence := track.Script()
+		_, regionConfidence := track.Region()
+		if trackScript != script || (trackConfidence != language.Exact && regionConfidence != language.Exact) {
+			return false
+		}
+	}
+	if region, confidence := filter.Region(); confidence == language.Exact {
+		if trackRegion, trackConfidence := track.Region(); trackRegion != region || trackConfidence != language.Exact {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatLanguage formats the track's language in the given style like FormatLanguageCode, keeping the
+// script and region that set it apart (chi-Hant, pt-BR, Traditional Chinese)
+func (t MKVTrack) FormatLanguage(style, locale string) string {
+	subtags := t.LanguageSubtags()
+	if subtags == "" {
+		return FormatLanguageCode(t.Properties.Language, style, locale)
+	}
+	if style == LanguageStyleName {
+		return GetLocalizedLanguageName(t.LanguageTag(), locale)
+	}
+	return FormatLanguageCode(t.Properties.Language, style, locale) + subtags
+}
+
+// Policies of --und-policy for tracks without a language tag
+const (
+	UndPolicyInclude = "include"
+	UndPolicyExclude = "exclude"
+	UndPolicyPrompt  = "prompt"
+	UndPolicyTreatAs = "treat-as"
+)
+
+// IsUntagged checks if the track has no language tag, or the tag und (undetermined)
+func (t MKVTrack) IsUntagged() bool {
+	code := t.LanguageTag()
+	return code == "" || strings.EqualFold(code, "und")
+}
+
+// ParseUndPolicy splits an --und-policy value into the policy and, for treat-as:<lang>, the language.
+// An empty value is UndPolicyExclude, the behavior without the flag.
+func ParseUndPolicy(value string) (policy, lang string) {
+	if value == "" {
+		return UndPolicyExclude, ""
+	}
+	policy, lang, _ = strings.Cut(value, ":")
+	return policy, lang
+}
+
+// ValidateUndPolicy checks that an --und-policy value is include, exclude, prompt or treat-as:<lang>
+func ValidateUndPolicy(value string) error {
+	policy, lang := ParseUndPolicy(value)
+	switch policy {
+	case UndPolicyInclude, UndPolicyExclude, UndPolicyPrompt:
+		if lang != "" {
+			return fmt.Errorf("policy '%s' takes no language", policy)
+		}
+		return nil
+	case UndPolicyTreatAs:
+		if !IsLanguageCode(lang) {
+			return fmt.Errorf("'%s' is not a language code (e.g., treat-as:eng)", lang)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown policy '%s' (supported: %s, %s, %s or %s:<lang>)", value, UndPolicyInclude, UndPolicyExclude, UndPolicyPrompt, UndPolicyTreatAs)
+}
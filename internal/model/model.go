@@ -1,8 +1,19 @@
 package model
 
 import (
+	"fmt"
 	"math/big"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
 )
 
 // MKVTrackProperties represents the properties of an MKV track
@@ -11,6 +22,7 @@ type MKVTrackProperties struct {
 	TrackName            string  `json:"track_name"`
 	Encoding             string  `json:"encoding"`
 	Language             string  `json:"language"`
+	LanguageIETF         string  `json:"language_ietf"`
 	Number               int     `json:"number"`
 	Forced               bool    `json:"forced_track"`
 	Default              bool    `json:"default_track"`
@@ -18,7 +30,9 @@ type MKVTrackProperties struct {
 	TextSubtitles        bool    `json:"text_subtitles"`
 	NumberOfIndexEntries int     `json:"num_index_entries"`
 	Duration             string  `json:"tag_duration"`
+	NumberOfBytes        string  `json:"tag_number_of_bytes"`
 	UId                  big.Int `json:"uid"`
+	Role                 string  `json:"-"` // TrackRoleDialogue or TrackRoleSigns when ClassifyRoles recognized it
 }
 
 // MKVTrack represents a track in an MKV file
@@ -31,200 +45,71 @@ type MKVTrack struct {
 
 // MKVContainer represents the container information of an MKV file
 type MKVContainer struct {
-	Type string `json:"type"`
-}
-
-// Language code mapping from ISO 639-1 (2-letter) to ISO 639-2/B (3-letter)
-// This includes comprehensive ISO 639 language code support
-var LanguageCodeMapping = map[string]string{
-	// Major languages
-	"en": "eng", // English
-	"es": "spa", // Spanish
-	"fr": "fre", // French
-	"de": "ger", // German
-	"it": "ita", // Italian
-	"pt": "por", // Portuguese
-	"ru": "rus", // Russian
-	"ja": "jpn", // Japanese
-	"ko": "kor", // Korean
-	"zh": "chi", // Chinese
-	"ar": "ara", // Arabic
-	"hi": "hin", // Hindi
-	"th": "tha", // Thai
-	"vi": "vie", // Vietnamese
-	"tr": "tur", // Turkish
-	"pl": "pol", // Polish
-	"nl": "dut", // Dutch
-	"sv": "swe", // Swedish
-	"da": "dan", // Danish
-	"no": "nor", // Norwegian
-	"fi": "fin", // Finnish
-	"cs": "cze", // Czech
-	"hu": "hun", // Hungarian
-	"ro": "rum", // Romanian
-	"bg": "bul", // Bulgarian
-	"hr": "hrv", // Croatian
-	"sk": "slo", // Slovak
-	"sl": "slv", // Slovenian
-	"et": "est", // Estonian
-	"lv": "lav", // Latvian
-	"lt": "lit", // Lithuanian
-	"el": "gre", // Greek
-	"he": "heb", // Hebrew
-	"fa": "per", // Persian
-	"ur": "urd", // Urdu
-	"bn": "ben", // Bengali
-	"ta": "tam", // Tamil
-	"te": "tel", // Telugu
-	"ml": "mal", // Malayalam
-	"kn": "kan", // Kannada
-	"gu": "guj", // Gujarati
-	"pa": "pan", // Punjabi
-	"mr": "mar", // Marathi
-	"ne": "nep", // Nepali
-	"si": "sin", // Sinhala
-	"my": "bur", // Burmese
-	"km": "khm", // Khmer
-	"lo": "lao", // Lao
-	"ka": "geo", // Georgian
-	"am": "amh", // Amharic
-	"sw": "swa", // Swahili
-	"zu": "zul", // Zulu
-	"af": "afr", // Afrikaans
-	"is": "ice", // Icelandic
-	"ga": "gle", // Irish
-	"cy": "wel", // Welsh
-	"eu": "baq", // Basque
-	"ca": "cat", // Catalan
-	"gl": "glg", // Galician
-	"mt": "mlt", // Maltese
-	"sq": "alb", // Albanian
-	"mk": "mac", // Macedonian
-	"be": "bel", // Belarusian
-	"uk": "ukr", // Ukrainian
-	
-	// Additional ISO 639-1 codes
-	"aa": "aar", // Afar
-	"ab": "abk", // Abkhazian
-	"ae": "ave", // Avestan
-	"ak": "aka", // Akan
-	"an": "arg", // Aragonese
-	"as": "asm", // Assamese
-	"av": "ava", // Avaric
-	"ay": "aym", // Aymara
-	"az": "aze", // Azerbaijani
-	"ba": "bak", // Bashkir
-	"bh": "bih", // Bihari languages
-	"bi": "bis", // Bislama
-	"bm": "bam", // Bambara
-	"bo": "tib", // Tibetan
-	"br": "bre", // Breton
-	"bs": "bos", // Bosnian
-	"ce": "che", // Chechen
-	"ch": "cha", // Chamorro
-	"co": "cos", // Corsican
-	"cr": "cre", // Cree
-	"cu": "chu", // Church Slavic
-	"cv": "chv", // Chuvash
-	"dv": "div", // Divehi
-	"dz": "dzo", // Dzongkha
-	"ee": "ewe", // Ewe
-	"eo": "epo", // Esperanto
-	"ff": "ful", // Fulah
-	"fj": "fij", // Fijian
-	"fo": "fao", // Faroese
-	"fy": "fry", // Western Frisian
-	"gd": "gla", // Scottish Gaelic
-	"gn": "grn", // Guarani
-	"gv": "glv", // Manx
-	"ha": "hau", // Hausa
-	"ho": "hmo", // Hiri Motu
-	"ht": "hat", // Haitian
-	"hz": "her", // Herero
-	"ia": "ina", // Interlingua
-	"id": "ind", // Indonesian
-	"ie": "ile", // Interlingue
-	"ig": "ibo", // Igbo
-	"ii": "iii", // Sichuan Yi
-	"ik": "ipk", // Inupiaq
-	"io": "ido", // Ido
-	"iu": "iku", // Inuktitut
-	"jv": "jav", // Javanese
-	"kg": "kon", // Kongo
-	"ki": "kik", // Kikuyu
-	"kj": "kua", // Kuanyama
-	"kk": "kaz", // Kazakh
-	"kl": "kal", // Kalaallisut
-	"kr": "kau", // Kanuri
-	"ks": "kas", // Kashmiri
-	"ku": "kur", // Kurdish
-	"kv": "kom", // Komi
-	"kw": "cor", // Cornish
-	"ky": "kir", // Kirghiz
-	"la": "lat", // Latin
-	"lb": "ltz", // Luxembourgish
-	"lg": "lug", // Ganda
-	"li": "lim", // Limburgish
-	"ln": "lin", // Lingala
-	"lu": "lub", // Luba-Katanga
-	"mg": "mlg", // Malagasy
-	"mh": "mah", // Marshallese
-	"mi": "mao", // Maori
-	"mn": "mon", // Mongolian
-	"mo": "mol", // Moldavian
-	"ms": "may", // Malay
-	"na": "nau", // Nauru
-	"nb": "nob", // Norwegian Bokmål
-	"nd": "nde", // North Ndebele
-	"ng": "ndo", // Ndonga
-	"nn": "nno", // Norwegian Nynorsk
-	"nr": "nbl", // South Ndebele
-	"nv": "nav", // Navajo
-	"ny": "nya", // Chichewa
-	"oc": "oci", // Occitan
-	"oj": "oji", // Ojibwa
-	"om": "orm", // Oromo
-	"or": "ori", // Oriya
-	"os": "oss", // Ossetian
-	"pi": "pli", // Pali
-	"ps": "pus", // Pashto
-	"qu": "que", // Quechua
-	"rm": "roh", // Romansh
-	"rn": "run", // Rundi
-	"rw": "kin", // Kinyarwanda
-	"sa": "san", // Sanskrit
-	"sc": "srd", // Sardinian
-	"sd": "snd", // Sindhi
-	"se": "sme", // Northern Sami
-	"sg": "sag", // Sango
-	"sh": "srp", // Serbo-Croatian (deprecated, use sr)
-	"sm": "smo", // Samoan
-	"sn": "sna", // Shona
-	"so": "som", // Somali
-	"sr": "srp", // Serbian
-	"ss": "ssw", // Swati
-	"st": "sot", // Southern Sotho
-	"su": "sun", // Sundanese
-	"tg": "tgk", // Tajik
-	"ti": "tir", // Tigrinya
-	"tk": "tuk", // Turkmen
-	"tl": "tgl", // Tagalog
-	"tn": "tsn", // Tswana
-	"to": "ton", // Tonga
-	"ts": "tso", // Tsonga
-	"tt": "tat", // Tatar
-	"tw": "twi", // Twi
-	"ty": "tah", // Tahitian
-	"ug": "uig", // Uighur
-	"uz": "uzb", // Uzbek
-	"ve": "ven", // Venda
-	"vo": "vol", // Volapük
-	"wa": "wln", // Walloon
-	"wo": "wol", // Wolof
-	"xh": "xho", // Xhosa
-	"yi": "yid", // Yiddish
-	"yo": "yor", // Yoruba
-	"za": "zha", // Zhuang
+	Type       string                 `json:"type"`
+	Properties MKVContainerProperties `json:"properties"`
+}
+
+// MKVContainerProperties represents the properties of an MKV file's container
+type MKVContainerProperties struct {
+	Duration int64 `json:"duration"` // Nanoseconds; 0 when mkvmerge does not report it
+}
+
+// TagDuration returns the duration of the track from its statistics tags (e.g. "00:22:31.123000000")
+func (t MKVTrack) TagDuration() (time.Duration, bool) {
+	parts := strings.Split(t.Properties.Duration, ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	hours, hoursErr := strconv.Atoi(parts[0])
+	minutes, minutesErr := strconv.Atoi(parts[1])
+	seconds, secondsErr := strconv.ParseFloat(parts[2], 64)
+	if hoursErr != nil || minutesErr != nil || secondsErr != nil {
+		return 0, false
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second)), true
+}
+
+// Bytes each subtitle entry adds to the stream data in the written file: SRT counters and timestamps,
+// ASS Dialogue fields, PGS segment headers and VobSub pack headers
+var outputOverheadPerEntry = map[string]int64{
+	"srt": 40,
+	"ass": 60,
+	"ssa": 60,
+	"vtt": 30,
+	"sup": 13,
+	"sub": 30,
+}
+
+// EstimatedOutputSize estimates the size of the file the track is extracted to from its statistics
+// tags: the bytes of stream data plus the overhead each entry adds in the output format. It reports
+// false when mkvmerge found no statistics tags.
+func (t MKVTrack) EstimatedOutputSize() (int64, bool) {
+	bytes, err := strconv.ParseInt(strings.TrimSpace(t.Properties.NumberOfBytes), 10, 64)
+	if err != nil || bytes < 0 {
+		return 0, false
+	}
+	return bytes + int64(t.Properties.NumberOfIndexEntries)*outputOverheadPerEntry[TrackExtension(t.Properties.CodecId)], true
+}
+
+// MKVAttachment represents a file attached to an MKV file (fonts, cover art)
+type MKVAttachment struct {
+	Id          int    `json:"id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// IsFont reports whether the attachment is a font file
+func (a MKVAttachment) IsFont() bool {
+	contentType := strings.ToLower(a.ContentType)
+	if strings.Contains(contentType, "font") || strings.Contains(contentType, "truetype") || strings.Contains(contentType, "opentype") {
+		return true
+	}
+	switch strings.ToLower(filepath.Ext(a.FileName)) {
+	case ".ttf", ".otf", ".ttc", ".woff", ".woff2":
+		return true
+	}
+	return false
 }
 
 // LanguageNames maps language codes (both 2 and 3 letter) to full language names
@@ -614,48 +499,78 @@ func GetLanguageName(code string) string {
 	return code // Return the code itself if no name is found
 }
 
-// MatchesLanguageFilter checks if a track language matches the specified filter
-// Supports both 2-letter (ISO 639-1) and 3-letter (ISO 639-2) language codes
-func MatchesLanguageFilter(trackLanguage, filterLanguage string) bool {
-	if filterLanguage == "" {
-		return true // No filter specified, match all
-	}
+// NativeLanguageNameLocale names every language in that language itself (Español, Français)
+const NativeLanguageNameLocale = "native"
 
-	if strings.EqualFold(trackLanguage, filterLanguage) {
-		return true
+// ValidateLanguageNameLocale checks that a locale is "native" or a valid BCP 47 tag (e.g., "en", "fr", "pt-BR")
+func ValidateLanguageNameLocale(locale string) error {
+	if locale == "" || strings.EqualFold(locale, NativeLanguageNameLocale) {
+		return nil
 	}
+	_, err := language.Parse(locale)
+	return err
+}
 
-	// Check if filter is 2-letter code and track uses 3-letter code
-	if len(filterLanguage) == 2 {
-		if mappedCode, exists := LanguageCodeMapping[strings.ToLower(filterLanguage)]; exists {
-			return strings.EqualFold(trackLanguage, mappedCode)
-		}
+// GetLocalizedLanguageName returns the full name of a language in the given locale, using the CLDR names.
+// An empty locale gives English names; unknown codes fall back to GetLanguageName.
+func GetLocalizedLanguageName(code, locale string) string {
+	tag, err := language.Parse(code)
+	if err != nil || tag == language.Und {
+		return GetLanguageName(code)
 	}
 
-	// Check if filter is 3-letter code and track uses 2-letter code
-	if len(filterLanguage) == 3 {
-		for twoLetter, threeLetter := range LanguageCodeMapping {
-			if strings.EqualFold(filterLanguage, threeLetter) {
-				return strings.EqualFold(trackLanguage, twoLetter)
+	var name string
+	if strings.EqualFold(locale, NativeLanguageNameLocale) {
+		name = display.Self.Name(tag)
+	} else {
+		namer := display.English.Languages()
+		if locale != "" {
+			if localeTag, err := language.Parse(locale); err == nil {
+				if localized := display.Languages(localeTag); localized != nil {
+					namer = localized
+				}
 			}
 		}
+		name = namer.Name(tag)
+	}
+	if name == "" {
+		return GetLanguageName(code)
 	}
 
-	return false
+	// Capitalize names such as "español" for use in file names
+	first, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(first)) + name[size:]
 }
 
 // MKVInfo represents the complete information about an MKV file
 type MKVInfo struct {
-	Tracks    []MKVTrack   `json:"tracks"`
-	Container MKVContainer `json:"container"`
+	Tracks      []MKVTrack      `json:"tracks"`
+	Container   MKVContainer    `json:"container"`
+	Attachments []MKVAttachment `json:"attachments"`
+	Chapters    []MKVChapters   `json:"chapters"`
+}
+
+// MKVChapters describes one edition of chapters in an MKV file
+type MKVChapters struct {
+	NumEntries int `json:"num_entries"`
+}
+
+// Chapter is one chapter of an MKV file; it runs until the next chapter starts
+type Chapter struct {
+	Number int
+	Name   string
+	Start  time.Duration
 }
 
 // TrackSelection represents the user's track selection criteria
 type TrackSelection struct {
-	LanguageCodes []string
-	TrackNumbers  []int
-	FormatFilters []string // Subtitle format filters (e.g., "srt", "ass", "sup")
-	Exclusions    TrackExclusion // Tracks to exclude from selection
+	LanguageCodes   []string
+	TrackNumbers    []int
+	FormatFilters   []string       // Subtitle format filters (e.g., "srt", "ass", "sup")
+	StatusFilters   []string       // Track status filters (e.g., "disabled")
+	Exclusions      TrackExclusion // Tracks to exclude from selection
+	Filter          *TrackFilter   // Expression every selected track must also satisfy, or nil
+	IncludeUntagged bool           // Whether tracks without a language tag match the language codes
 }
 
 // TrackExclusion represents tracks to exclude from selection
@@ -663,18 +578,257 @@ type TrackExclusion struct {
 	LanguageCodes []string
 	TrackNumbers  []int
 	FormatFilters []string // Subtitle format filters to exclude
+	StatusFilters []string // Track status filters to exclude
 }
 
 // OutputConfig represents output configuration options
 type OutputConfig struct {
-	OutputDir string // Custom output directory
-	Template  string // Filename template with placeholders
-	CreateDir bool   // Whether to create output directory if it doesn't exist
+	OutputDir       string // Custom output directory
+	OutputLayout    string // OutputLayoutPerFile puts each source's subtitles in <OutputDir>/<basename>/
+	Template        string // Filename template with placeholders
+	FieldSeparator  string // Character joining the default template's fields; empty fields are collapsed around it
+	AllTracks       bool   // Demux every track, attachment and the chapters instead of only subtitles
+	Stdout          bool   // Extract a single subtitle track for writing to stdout; OutputDir is a temporary directory
+	SafeNames       string // Filesystem rule set (SafeNamesWindows or SafeNamesExFAT) output names must satisfy, or empty
+	CreateDir       bool   // Whether to create output directory if it doesn't exist
+	Force           bool   // Overwrite existing files at the output paths instead of skipping their tracks
+	Backup          bool   // With Force, keep each overwritten file as <name>.bak or in BackupDir
+	BackupDir       string // Directory receiving the backups instead of the output directory
+	RespectExisting bool   // Skip tracks that already have a matching external subtitle file
+	SkipProcessed   bool   // Skip files recorded in the processing history and unchanged since
+
+	StableFor time.Duration // Time an input file must go unmodified before it is analyzed, 0 to analyze it at once
+
+	TrackFilter    *TrackFilter // --filter expression narrowing the selection, or nil
+	MinCoverage    int          // Minimum percentage of the file's duration a track must cover, 0 for any
+	PerLanguage    string       // Keep only the first, last or Nth selected subtitle track of each language
+	MaxPerLanguage int          // Most subtitle tracks extracted per language after all other filters, 0 for no limit
+	UndPolicy      string       // How tracks without a language tag meet language filters (see ParseUndPolicy), empty to exclude them
+	PlanOnly       bool         // In a batch dry run, record each file's plan in FileResult.Plan instead of listing it
+	SortOrder      string       // Order of batch input files: name (default), size or mtime
+	SortReverse    bool         // Process batch input files in reverse order, e.g. newest first
+
+	LanguageNameLocale string // Locale of {languagename}: "native", a BCP 47 tag, or empty for English
+	LanguageStyle      string // Code style of {language} (LanguageStyleISO6391 and others), or empty for the code as tagged
+	PreHook            string // Command run before each file; a non-zero exit skips the file
+	PostHook           string // Command run once per extracted file (supports {output}, {language}, {format}, {source})
+	Namer              Namer  // Names output files in place of Template when set
+
+	NoFonts        bool   // Skip extracting font attachments for ASS/SSA tracks
+	StripASS       string // Reduce ASS tracks to dialogue only, keeping "ass" or converting to "srt"
+	StripHI        string // Remove hearing-impaired annotations into a "copy" or in place ("replace")
+	VerifyLanguage bool   // Check the text of text tracks against their language tags
+	FixLanguages   bool   // Write the languages detected for und tracks back into the source file
+	SyncToAudio    bool   // Shift and stretch text tracks to match the speech of the audio track
+	ConvertTo      string // Convert extracted SRT tracks to this format ("ass")
+	StyleTemplate  string // ASS script whose header and style the converted tracks use
+	LineEndings    string // Line endings of the text subtitles ("lf" or "crlf"), empty to keep mkvextract's
+	BOM            bool   // Start the text subtitles with a UTF-8 byte order mark
+	MergeLanguages string // Language pair merged into a bilingual subtitle (e.g., "eng+jpn")
+	MergeFormat    string // Output format of the bilingual subtitle ("srt" or "ass")
+
+	CleanupRules   []string // Regular expressions; matching cues are removed from extracted text tracks
+	PostProcessors []string // Names of the post-processing steps to run first, in this order
+
+	TranslateTo string            // Target language for machine translation of extracted SRT tracks
+	Translation TranslationConfig // Translation backend settings
+
+	SourceURL string // Original URL of a downloaded input, reported to hooks instead of the temporary file
+
+	Notifications []NotificationConfig // Sinks receiving a summary when the run finishes, empty without --notify
+
+	Recipe     *Recipe // Selection recipe replacing the selection of each file, or nil
+	SaveRecipe string  // Path the selected tracks are saved to as a recipe, empty for none
+
+	SplitByChapters bool // Split extracted text tracks into one file per chapter
+	Chapter         int  // Chapter rendered by {chapter} while a chapter's file is named, 0 otherwise
+}
+
+// Namer names output files for organizations whose naming rules a template cannot express
+type Namer interface {
+	// Name returns the file name, without directory, of a track's output. templateName is the name
+	// the filename template gives it. An error falls back to templateName.
+	Name(inputFileName string, track MKVTrack, templateName string, config OutputConfig) (string, error)
+}
+
+// TranslationConfig holds the machine translation backend settings
+type TranslationConfig struct {
+	Provider          string `yaml:"provider"`            // "libretranslate" (default) or "deepl"
+	Endpoint          string `yaml:"endpoint"`            // Base URL of the translation API
+	APIKey            string `yaml:"api_key"`             // API key, if the backend requires one
+	BatchSize         int    `yaml:"batch_size"`          // Number of cues sent per request
+	RequestsPerMinute int    `yaml:"requests_per_minute"` // Maximum request rate
+}
+
+// NotificationConfig configures one sink receiving a summary when a run finishes with --notify
+type NotificationConfig struct {
+	Type string `yaml:"type"` // "discord", "slack" or "webhook" (the summary as JSON)
+	URL  string `yaml:"url"`  // Webhook URL to post to
+	On   string `yaml:"on"`   // "always" (default) or "failure" to post only when something failed
+}
+
+// PromptDefaults configures the answers of the interactive prompts when enter is pressed
+type PromptDefaults struct {
+	ExtractAll     *bool  `yaml:"extract_all"`     // Answer to "Extract all tracks?", yes when unset
+	Selection      string `yaml:"selection"`       // Selection used when the selection prompt is left empty (e.g., "eng")
+	SkipExclusions bool   `yaml:"skip_exclusions"` // Leave out the exclusion prompt
 }
 
 // DefaultOutputTemplate is the default filename template
 const DefaultOutputTemplate = "{basename}.{language}.{trackno}.{trackname}.{forced}.{default}.{extension}"
 
+// Output layouts for a custom output directory
+const (
+	OutputLayoutFlat    = "flat"     // All subtitles directly in the output directory (default)
+	OutputLayoutPerFile = "per-file" // One <basename> subdirectory per source file
+)
+
+// ValidateOutputLayout checks that layout is empty or one of the output layouts
+func ValidateOutputLayout(layout string) error {
+	if layout == "" || layout == OutputLayoutFlat || layout == OutputLayoutPerFile {
+		return nil
+	}
+	return fmt.Errorf("unknown output layout '%s' (supported: %s, %s)", layout, OutputLayoutFlat, OutputLayoutPerFile)
+}
+
+// FieldSeparators maps the separator names accepted by --field-separator to their characters
+var FieldSeparators = map[string]string{
+	"dot":        ".",
+	"underscore": "_",
+	"dash":       "-",
+	"space":      " ",
+}
+
+// ValidateFieldSeparator checks that name is empty or one of FieldSeparators
+func ValidateFieldSeparator(name string) error {
+	if _, exists := FieldSeparators[name]; name == "" || exists {
+		return nil
+	}
+	return fmt.Errorf("unknown field separator '%s' (supported: dot, underscore, dash, space)", name)
+}
+
+// NamingKodi names sidecars movie.<language>[.forced][.default].srt, the flags Kodi reads after the language
+const NamingKodi = "kodi"
+
+// NamingTemplates maps the naming presets accepted by --naming to their filename templates
+var NamingTemplates = map[string]string{
+	NamingKodi: "{basename}.{language}.{forced}.{default}.{extension}",
+}
+
+// ValidateNaming checks that preset is empty or one of NamingTemplates
+func ValidateNaming(preset string) error {
+	if _, exists := NamingTemplates[preset]; preset == "" || exists {
+		return nil
+	}
+	return fmt.Errorf("unknown naming preset '%s' (supported: %s)", preset, NamingKodi)
+}
+
+// Filesystem rule sets accepted by --safe-names
+const (
+	SafeNamesWindows = "windows" // NTFS and SMB shares: also reserved device names and trailing dots
+	SafeNamesExFAT   = "exfat"   // exFAT and FAT32 drives: characters the filesystem rejects
+)
+
+// ValidateSafeNames checks that rules is empty or one of the filesystem rule sets
+func ValidateSafeNames(rules string) error {
+	if rules == "" || rules == SafeNamesWindows || rules == SafeNamesExFAT {
+		return nil
+	}
+	return fmt.Errorf("unknown filesystem '%s' (supported: %s, %s)", rules, SafeNamesWindows, SafeNamesExFAT)
+}
+
+// Line endings of the extracted text subtitles
+const (
+	LineEndingsLF   = "lf"   // Unix line endings
+	LineEndingsCRLF = "crlf" // Windows line endings, needed by some TVs and older players
+)
+
+// ValidateLineEndings checks that lineEndings is empty, lf or crlf
+func ValidateLineEndings(lineEndings string) error {
+	if lineEndings == "" || lineEndings == LineEndingsLF || lineEndings == LineEndingsCRLF {
+		return nil
+	}
+	return fmt.Errorf("unknown line endings '%s' (supported: %s, %s)", lineEndings, LineEndingsLF, LineEndingsCRLF)
+}
+
+// Rules of --per-language besides a track position
+const (
+	PerLanguageFirst = "first"
+	PerLanguageLast  = "last"
+)
+
+// ValidatePerLanguage checks that rule is empty, first, last or a positive track position
+func ValidatePerLanguage(rule string) error {
+	if rule == "" || rule == PerLanguageFirst || rule == PerLanguageLast {
+		return nil
+	}
+	if position, err := strconv.Atoi(rule); err == nil && position > 0 {
+		return nil
+	}
+	return fmt.Errorf("unknown per-language rule '%s' (supported: %s, %s or a track position such as 2)", rule, PerLanguageFirst, PerLanguageLast)
+}
+
+// TemplatePlaceholders lists the placeholders understood by the filename template
+var TemplatePlaceholders = []string{"{basename}", "{language}", "{languagename}", "{trackno}", "{trackname}", "{forced}", "{default}", "{extension}", "{date}", "{time}", "{timestamp}", "{hash}", "{chapter}"}
+
+// TemplateModifiers lists the modifiers a filename template placeholder accepts, as in {language:upper}
+var TemplateModifiers = []string{"lower", "upper", "slug"}
+
+// {hash} is a hex SHA-256 digest shortened to DefaultHashLength characters unless a length is given, as in {hash:12}
+const (
+	DefaultHashLength = 8
+	MaxHashLength     = 64
+)
+
+// ValidateOutputTemplate checks that a filename template only uses known placeholders and modifiers
+func ValidateOutputTemplate(template string) error {
+	return ValidatePlaceholders(template, TemplatePlaceholders, validateTemplateModifier)
+}
+
+// validateTemplateModifier accepts TemplateModifiers on any placeholder and a length on {hash}
+func validateTemplateModifier(placeholder, modifier string) error {
+	if length, err := strconv.Atoi(modifier); err == nil {
+		if placeholder != "{hash}" {
+			return fmt.Errorf("only {hash} takes a length")
+		}
+		if length < 1 || length > MaxHashLength {
+			return fmt.Errorf("hash length must be between 1 and %d", MaxHashLength)
+		}
+		return nil
+	}
+	if !slices.Contains(TemplateModifiers, modifier) {
+		return fmt.Errorf("unknown modifier '%s' (supported: %s)", modifier, strings.Join(TemplateModifiers, ", "))
+	}
+	return nil
+}
+
+// PlaceholderPattern matches a placeholder with an optional modifier, capturing both ({name} or {name:modifier}).
+// Other braces (shell ${VAR}, awk blocks) do not match.
+var PlaceholderPattern = regexp.MustCompile(`\{([a-z_]+)(?::([a-z0-9]+))?\}`)
+
+// ValidatePlaceholders checks that every {placeholder} in template is one of known, and that
+// validModifier accepts any modifier. A nil validModifier rejects all modifiers.
+func ValidatePlaceholders(template string, known []string, validModifier func(placeholder, modifier string) error) error {
+	for _, match := range PlaceholderPattern.FindAllStringSubmatchIndex(template, -1) {
+		if match[0] > 0 && template[match[0]-1] == '$' {
+			continue // Shell variable such as ${name}
+		}
+		placeholder := "{" + template[match[2]:match[3]] + "}"
+		if !slices.Contains(known, placeholder) {
+			return fmt.Errorf("unknown placeholder %s (supported: %s)", placeholder, strings.Join(known, ", "))
+		}
+		if match[4] >= 0 {
+			if validModifier == nil {
+				return fmt.Errorf("placeholder %s does not take modifiers", placeholder)
+			}
+			if err := validModifier(placeholder, template[match[4]:match[5]]); err != nil {
+				return fmt.Errorf("%s: %v", template[match[0]:match[1]], err)
+			}
+		}
+	}
+	return nil
+}
+
 // SubtitleExtensionByCodec maps codec IDs to file extensions
 var SubtitleExtensionByCodec = map[string]string{
 	// Text-based subtitle formats
@@ -686,6 +840,12 @@ var SubtitleExtensionByCodec = map[string]string{
 	"S_ASS":         "ass",
 	"S_SSA":         "ssa",
 
+	// WebVTT track kinds as stored in WebM files
+	"D_WEBVTT/SUBTITLES":    "vtt",
+	"D_WEBVTT/CAPTIONS":     "vtt",
+	"D_WEBVTT/DESCRIPTIONS": "vtt",
+	"D_WEBVTT/METADATA":     "vtt",
+
 	// Image-based subtitle formats
 	"S_HDMV/PGS":  "sup",
 	"S_VOBSUB":    "sub",
@@ -698,6 +858,61 @@ var SubtitleExtensionByCodec = map[string]string{
 	"S_HDMV/TEXTST": "sup",
 }
 
+// MediaExtensionByCodec maps video and audio codec IDs (or their family prefix, such as A_AAC) to the
+// extension of the file mkvextract writes for them
+var MediaExtensionByCodec = map[string]string{
+	// Video
+	"V_MPEG4/ISO/AVC":  "h264",
+	"V_MPEGH/ISO/HEVC": "h265",
+	"V_MPEG4/ISO":      "m4v",
+	"V_MPEG1":          "mpv",
+	"V_MPEG2":          "mpv",
+	"V_AV1":            "ivf",
+	"V_VP8":            "ivf",
+	"V_VP9":            "ivf",
+	"V_MS/VFW/FOURCC":  "avi",
+	"V_REAL":           "rm",
+	"V_THEORA":         "ogv",
+
+	// Audio
+	"A_AAC":      "aac",
+	"A_AC3":      "ac3",
+	"A_EAC3":     "eac3",
+	"A_DTS":      "dts",
+	"A_TRUEHD":   "thd",
+	"A_MLP":      "mlp",
+	"A_FLAC":     "flac",
+	"A_OPUS":     "opus",
+	"A_VORBIS":   "ogg",
+	"A_MPEG/L2":  "mp2",
+	"A_MPEG/L3":  "mp3",
+	"A_PCM":      "wav",
+	"A_ALAC":     "caf",
+	"A_TTA1":     "tta",
+	"A_WAVPACK4": "wv",
+	"A_REAL":     "ra",
+}
+
+// TrackExtension returns the extension of the file a track is extracted to, for subtitle, video and
+// audio codecs. It returns an empty string for unknown codecs.
+func TrackExtension(codecId string) string {
+	if ext, exists := SubtitleExtensionByCodec[codecId]; exists {
+		return ext
+	}
+	// Fall back from e.g. A_AAC/MPEG4/LC to A_AAC/MPEG4 and A_AAC
+	for id := codecId; id != ""; {
+		if ext, exists := MediaExtensionByCodec[id]; exists {
+			return ext
+		}
+		slash := strings.LastIndex(id, "/")
+		if slash < 0 {
+			break
+		}
+		id = id[:slash]
+	}
+	return ""
+}
+
 // GetSubtitleFormatFromCodec returns the subtitle format (extension) for a given codec
 func GetSubtitleFormatFromCodec(codecId string) string {
 	if ext, exists := SubtitleExtensionByCodec[codecId]; exists {
@@ -716,6 +931,58 @@ func MatchesFormatFilter(codecId, formatFilter string) bool {
 	return strings.EqualFold(trackFormat, formatFilter)
 }
 
+// TrackStatusFilters lists the keywords accepted as track status filters. Besides the enabled flag
+// they name whole categories of tracks, so common selections need no formats or track IDs.
+var TrackStatusFilters = []string{"enabled", "disabled", "all-forced", "all-text", "all-image", "all", "dialogue", "signs"}
+
+// imageSubtitleCodecs lists the codecs extracted to bitmap formats. HDMV TextST is text, but it is
+// extracted as a .sup file that cannot be edited like one.
+var imageSubtitleCodecs = map[string]bool{
+	"S_HDMV/PGS":    true,
+	"S_HDMV/TEXTST": true,
+	"S_VOBSUB":      true,
+	"S_DVBSUB":      true,
+	"S_IMAGE/BMP":   true,
+}
+
+// IsImageSubtitleCodec checks if a codec stores subtitles as images rather than text
+func IsImageSubtitleCodec(codecId string) bool {
+	return imageSubtitleCodecs[codecId]
+}
+
+// IsTrackStatusFilter checks if the input is a track status keyword
+func IsTrackStatusFilter(input string) bool {
+	for _, status := range TrackStatusFilters {
+		if strings.EqualFold(input, status) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesStatusFilter checks if a track's enabled flag or category matches the specified status filter
+func MatchesStatusFilter(track MKVTrack, statusFilter string) bool {
+	switch strings.ToLower(statusFilter) {
+	case "enabled":
+		return track.Properties.Enabled
+	case "disabled":
+		return !track.Properties.Enabled
+	case "all-forced":
+		return track.Properties.Forced
+	case "all-text":
+		return !IsImageSubtitleCodec(track.Properties.CodecId)
+	case "all-image":
+		return IsImageSubtitleCodec(track.Properties.CodecId)
+	case "all":
+		return true
+	case "dialogue":
+		return track.Properties.Role != TrackRoleSigns
+	case "signs":
+		return track.Properties.Role == TrackRoleSigns
+	}
+	return false
+}
+
 // ExtractionJob represents a single subtitle extraction task
 type ExtractionJob struct {
 	Track         MKVTrack
@@ -724,6 +991,39 @@ type ExtractionJob struct {
 	MksFileName   string
 }
 
+// FileResult summarizes what processing one input file produced. Skipped counts the selected tracks
+// that --min-coverage, --per-language, --max-per-language, --respect-existing, an existing output
+// without --force, --skip-processed, the pre-hook or another instance's lock left out.
+type FileResult struct {
+	Extracted   int          // Tracks extracted (planned, in a dry run)
+	Skipped     int          // Selected tracks left out by a filter, an existing output or another instance's lock
+	OutputBytes int64        // Combined size of the extracted subtitle files (estimated, in a dry run)
+	Outputs     []OutputFile // Files written, with their sizes
+	Plan        []TrackPlan  // What happens to each track, complete for a batch dry run
+}
+
+// OutputFile is a file written by an extraction
+type OutputFile struct {
+	Path  string
+	Bytes int64
+	Track MKVTrack // Track the file was extracted from
+}
+
+// TrackPlan records what processing does with a track: it is written to OutFileName, or left out
+// for Reason
+type TrackPlan struct {
+	Track       MKVTrack
+	OutFileName string
+	Reason      string
+}
+
+// AddPlan records that tracks are left out for reason
+func (r *FileResult) AddPlan(tracks []MKVTrack, reason string) {
+	for _, track := range tracks {
+		r.Plan = append(r.Plan, TrackPlan{Track: track, Reason: reason})
+	}
+}
+
 // ExtractionResult represents the result of an extraction operation
 type ExtractionResult struct {
 	Job   ExtractionJob
@@ -0,0 +1,174 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RecipeVersion is the version of the recipe format written by NewRecipe
+const RecipeVersion = 1
+
+// Recipe records a selection of subtitle tracks by what identifies them rather than by track
+// number, so it can be applied again to re-released or remuxed files of the same show
+type Recipe struct {
+	Version int           `json:"version"`
+	Source  string        `json:"source,omitempty"` // File the recipe was saved from
+	Tracks  []RecipeTrack `json:"tracks"`
+}
+
+// RecipeTrack identifies one selected track. Its track number is only informational.
+type RecipeTrack struct {
+	UID          string `json:"uid,omitempty"`
+	Number       int    `json:"number"`
+	Language     string `json:"language"`
+	LanguageIETF string `json:"language_ietf,omitempty"`
+	Format       string `json:"format"`
+	Name         string `json:"name,omitempty"`
+	Forced       bool   `json:"forced,omitempty"`
+	Role         string `json:"role,omitempty"`
+	Position     int    `json:"position"` // Among the file's tracks of the same language and format, from 1
+}
+
+// Weights of the properties a track shares with a recipe track, each outweighing all below it
+const (
+	recipeFormatWeight   = 16
+	recipeNameWeight     = 8
+	recipeRoleWeight     = 4
+	recipeForcedWeight   = 2
+	recipePositionWeight = 1
+)
+
+// NewRecipe creates a recipe of the selected subtitle tracks among all tracks of a file
+func NewRecipe(source string, tracks, selected []MKVTrack) Recipe {
+	recipe := Recipe{Version: RecipeVersion, Source: source, Tracks: []RecipeTrack{}}
+	for _, track := range selected {
+		if track.Type != "subtitles" {
+			continue
+		}
+		entry := RecipeTrack{
+			Number:       track.Properties.Number,
+			Language:     track.Properties.Language,
+			LanguageIETF: track.Properties.LanguageIETF,
+			Format:       GetSubtitleFormatFromCodec(track.Properties.CodecId),
+			Name:         track.Properties.TrackName,
+			Forced:       track.Properties.Forced,
+			Role:         track.Properties.Role,
+			Position:     recipePosition(tracks, track),
+		}
+		if track.Properties.UId.Sign() != 0 {
+			entry.UID = track.Properties.UId.String()
+		}
+		recipe.Tracks = append(recipe.Tracks, entry)
+	}
+	return recipe
+}
+
+// Resolve finds the tracks of a file the recipe selects, in file order, and the recipe tracks
+// without a match. A track with the recipe track's UID matches first, as files muxed from the same
+// source keep them; otherwise the track of the same language sharing the most of its format, name,
+// role, forced flag and position does.
+func (r Recipe) Resolve(tracks []MKVTrack) ([]MKVTrack, []RecipeTrack) {
+	claimed := make(map[int]bool)
+	matches := make([]int, len(r.Tracks))
+	for i, entry := range r.Tracks {
+		matches[i] = -1
+		if entry.UID == "" {
+			continue
+		}
+		for j, track := range tracks {
+			if track.Type == "subtitles" && !claimed[j] && track.Properties.UId.String() == entry.UID {
+				matches[i] = j
+				claimed[j] = true
+				break
+			}
+		}
+	}
+
+	var missing []RecipeTrack
+	for i, entry := range r.Tracks {
+		if matches[i] >= 0 {
+			continue
+		}
+		best, bestScore := -1, -1
+		for j, track := range tracks {
+			if track.Type != "subtitles" || claimed[j] || LanguageKey(track.LanguageTag()) != LanguageKey(entry.languageTag()) {
+				continue
+			}
+			if score := entry.score(tracks, track); score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+		if best < 0 {
+			missing = append(missing, entry)
+			continue
+		}
+		matches[i] = best
+		claimed[best] = true
+	}
+
+	var selected []MKVTrack
+	for j, track := range tracks {
+		if claimed[j] {
+			selected = append(selected, track)
+		}
+	}
+	return selected, missing
+}
+
+// String describes a recipe track for messages, e.g. `3 (eng, srt, "Full")`
+func (e RecipeTrack) String() string {
+	description := e.Language + ", " + e.Format
+	if e.LanguageIETF != "" {
+		description = e.LanguageIETF + ", " + e.Format
+	}
+	if e.Name != "" {
+		description += `, "` + e.Name + `"`
+	}
+	return strconv.Itoa(e.Number) + " (" + description + ")"
+}
+
+// languageTag returns the most specific language code of a recipe track
+func (e RecipeTrack) languageTag() string {
+	if e.LanguageIETF != "" {
+		return e.LanguageIETF
+	}
+	return e.Language
+}
+
+// score weighs the properties a track of the recipe track's language shares with it
+func (e RecipeTrack) score(tracks []MKVTrack, track MKVTrack) int {
+	score := 0
+	if GetSubtitleFormatFromCodec(track.Properties.CodecId) == e.Format {
+		score += recipeFormatWeight
+	}
+	if strings.EqualFold(strings.TrimSpace(track.Properties.TrackName), strings.TrimSpace(e.Name)) {
+		score += recipeNameWeight
+	}
+	if track.Properties.Role == e.Role {
+		score += recipeRoleWeight
+	}
+	if track.Properties.Forced == e.Forced {
+		score += recipeForcedWeight
+	}
+	if recipePosition(tracks, track) == e.Position {
+		score += recipePositionWeight
+	}
+	return score
+}
+
+// recipePosition counts the subtitle tracks of a track's language and format up to and including it
+func recipePosition(tracks []MKVTrack, track MKVTrack) int {
+	key := LanguageKey(track.LanguageTag())
+	trackFormat := GetSubtitleFormatFromCodec(track.Properties.CodecId)
+	position := 0
+	for _, other := range tracks {
+		if other.Type != "subtitles" || LanguageKey(other.LanguageTag()) != key || GetSubtitleFormatFromCodec(other.Properties.CodecId) != trackFormat {
+			continue
+		}
+		position++
+		if other.Properties.Number == track.Properties.Number {
+			break
+		}
+	}
+	return position
+}
@@ -0,0 +1,101 @@
+package model
+
+import "strings"
+
+// Roles of subtitle tracks told apart by ClassifyRoles
+const (
+	TrackRoleDialogue = "dialogue" // Full dialogue, as the main subtitle of a language
+	TrackRoleSigns    = "signs"    // Signs & songs: on-screen text and lyrics only
+)
+
+// TypesettingWords are words that mark signs, songs and other typesetting in ASS style names and
+// track names
+var TypesettingWords = map[string]bool{
+	"sign": true, "signs": true, "song": true, "songs": true, "op": true, "ed": true,
+	"kara": true, "karaoke": true, "ts": true, "typeset": true, "title": true,
+	"insert": true, "lyrics": true, "romaji": true, "eyecatch": true, "note": true, "notes": true,
+}
+
+// dialogueWords are track name words that mark a full dialogue track
+var dialogueWords = map[string]bool{
+	"full": true, "dialogue": true, "dialog": true, "complete": true,
+}
+
+// signsEntryRatio is how many times fewer entries an ASS track has than the largest text track of its
+// language when it holds signs & songs rather than dialogue
+const signsEntryRatio = 3
+
+// HasTypesettingWord checks if a style or track name contains one of the TypesettingWords
+func HasTypesettingWord(name string) bool {
+	for _, word := range nameWords(name) {
+		if TypesettingWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// nameWords splits a lowercased name into its runs of letters
+func nameWords(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r < 'a' || r > 'z'
+	})
+}
+
+// ClassifyRoles sets the Role of subtitle tracks that are recognizably full dialogue or signs &
+// songs, as anime releases often carry both for one language. Track names decide first ("Full",
+// "Signs & Songs"), then the forced flag. ASS tracks with a fraction of the entries of another text
+// track of their language count as signs, and the other text tracks of a language with a signs
+// track as dialogue. Other tracks keep an empty Role.
+func ClassifyRoles(tracks []MKVTrack) {
+	maxEntries := make(map[string]int)
+	for i, track := range tracks {
+		if track.Type != "subtitles" {
+			continue
+		}
+		tracks[i].Properties.Role = nameRole(track)
+		if tracks[i].Properties.Role == "" && track.Properties.Forced {
+			tracks[i].Properties.Role = TrackRoleSigns
+		}
+		key := LanguageKey(track.LanguageTag())
+		if !IsImageSubtitleCodec(track.Properties.CodecId) && track.Properties.NumberOfIndexEntries > maxEntries[key] {
+			maxEntries[key] = track.Properties.NumberOfIndexEntries
+		}
+	}
+
+	hasSigns := make(map[string]bool)
+	for i, track := range tracks {
+		if track.Type != "subtitles" {
+			continue
+		}
+		key := LanguageKey(track.LanguageTag())
+		entries := track.Properties.NumberOfIndexEntries
+		trackFormat := GetSubtitleFormatFromCodec(track.Properties.CodecId)
+		if track.Properties.Role == "" && (trackFormat == "ass" || trackFormat == "ssa") && entries > 0 && entries*signsEntryRatio <= maxEntries[key] {
+			tracks[i].Properties.Role = TrackRoleSigns
+		}
+		if tracks[i].Properties.Role == TrackRoleSigns {
+			hasSigns[key] = true
+		}
+	}
+
+	for i, track := range tracks {
+		if track.Type == "subtitles" && track.Properties.Role == "" && hasSigns[LanguageKey(track.LanguageTag())] && !IsImageSubtitleCodec(track.Properties.CodecId) {
+			tracks[i].Properties.Role = TrackRoleDialogue
+		}
+	}
+}
+
+// nameRole classifies a track by the words of its name, or returns an empty string
+func nameRole(track MKVTrack) string {
+	name := strings.ToLower(track.Properties.TrackName)
+	for _, word := range nameWords(name) {
+		if dialogueWords[word] {
+			return TrackRoleDialogue
+		}
+	}
+	if strings.Contains(name, "s&s") || HasTypesettingWord(name) {
+		return TrackRoleSigns
+	}
+	return ""
+}
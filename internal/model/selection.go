@@ -0,0 +1,150 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MaxTrackRange is the most track numbers a range may span. Matroska files hold far fewer tracks,
+// and the limit keeps a range such as 1-1000000000 from being expanded.
+const MaxTrackRange = 4096
+
+// ParseTrackRange parses an inclusive range of track numbers such as "3-7". It reports false when
+// item is not a range with a start no greater than its end, or spans more than MaxTrackRange numbers.
+func ParseTrackRange(item string) ([]int, bool) {
+	startText, endText, found := strings.Cut(item, "-")
+	if !found {
+		return nil, false
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(startText))
+	if err != nil || start < 0 {
+		return nil, false
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(endText))
+	if err != nil || end < start || end-start >= MaxTrackRange {
+		return nil, false
+	}
+
+	trackNums := make([]int, 0, end-start+1)
+	for trackNum := start; trackNum <= end; trackNum++ {
+		trackNums = append(trackNums, trackNum)
+	}
+	return trackNums, true
+}
+
+// ParseTrackSelection parses comma-separated language codes, track numbers or ranges, status keywords
+// and format filters. Items with a leading ! are parsed into the selection's exclusions. Items that
+// are none of these are skipped and returned, negated ones with their !.
+func ParseTrackSelection(input string) (TrackSelection, []string) {
+	var items, negatedItems []string
+	for _, item := range splitSelectionItems(input) {
+		// A leading ! excludes the item instead of selecting it
+		if negated, found := strings.CutPrefix(item, "!"); found {
+			negatedItems = append(negatedItems, negated)
+			continue
+		}
+		items = append(items, item)
+	}
+
+	criteria, unknownItems := parseTrackCriteria(items)
+	selection := TrackSelection{
+		LanguageCodes: criteria.LanguageCodes,
+		TrackNumbers:  criteria.TrackNumbers,
+		FormatFilters: criteria.FormatFilters,
+		StatusFilters: criteria.StatusFilters,
+		Exclusions:    TrackExclusion{},
+	}
+
+	if len(negatedItems) > 0 {
+		exclusion, unknownExclusions := parseTrackCriteria(negatedItems)
+		selection.Exclusions = exclusion
+		for _, item := range unknownExclusions {
+			unknownItems = append(unknownItems, "!"+item)
+		}
+	}
+
+	return selection, unknownItems
+}
+
+// ParseTrackExclusion parses comma-separated exclusion criteria (languages, track numbers or ranges,
+// status keywords, formats). Items that are none of these are skipped and returned.
+func ParseTrackExclusion(input string) (TrackExclusion, []string) {
+	return parseTrackCriteria(splitSelectionItems(input))
+}
+
+// MergeTrackExclusions combines the exclusions given with ! in a selection and those given separately
+func MergeTrackExclusions(a, b TrackExclusion) TrackExclusion {
+	return TrackExclusion{
+		LanguageCodes: append(append([]string{}, a.LanguageCodes...), b.LanguageCodes...),
+		TrackNumbers:  append(append([]int{}, a.TrackNumbers...), b.TrackNumbers...),
+		FormatFilters: append(append([]string{}, a.FormatFilters...), b.FormatFilters...),
+		StatusFilters: append(append([]string{}, a.StatusFilters...), b.StatusFilters...),
+	}
+}
+
+// splitSelectionItems splits a comma-separated list, trimming the items and dropping empty ones
+func splitSelectionItems(input string) []string {
+	var items []string
+	for _, item := range strings.Split(input, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseTrackCriteria sorts selection items into track numbers, languages, status keywords and
+// formats, returning the items that are none of these
+func parseTrackCriteria(items []string) (TrackExclusion, []string) {
+	criteria := TrackExclusion{
+		LanguageCodes: []string{},
+		TrackNumbers:  []int{},
+		FormatFilters: []string{},
+		StatusFilters: []string{},
+	}
+	var unknownItems []string
+
+	for _, item := range items {
+		// Try to parse as track number first
+		if trackNum, err := strconv.Atoi(item); err == nil {
+			criteria.TrackNumbers = append(criteria.TrackNumbers, trackNum)
+			continue
+		}
+
+		// Try to parse as an inclusive range of track numbers, e.g. 3-7
+		if trackNums, ok := ParseTrackRange(item); ok {
+			criteria.TrackNumbers = append(criteria.TrackNumbers, trackNums...)
+			continue
+		}
+
+		// Try to parse as language code
+		if IsLanguageCode(item) {
+			criteria.LanguageCodes = append(criteria.LanguageCodes, item)
+			continue
+		}
+
+		// Try to parse as track status filter
+		if IsTrackStatusFilter(item) {
+			criteria.StatusFilters = append(criteria.StatusFilters, strings.ToLower(item))
+			continue
+		}
+
+		// Try to parse as subtitle format filter
+		isValidFormat := false
+		lowerItem := strings.ToLower(item)
+		for _, ext := range SubtitleExtensionByCodec {
+			if lowerItem == ext {
+				isValidFormat = true
+				break
+			}
+		}
+
+		if isValidFormat {
+			criteria.FormatFilters = append(criteria.FormatFilters, lowerItem)
+		} else {
+			unknownItems = append(unknownItems, item)
+		}
+	}
+
+	return criteria, unknownItems
+}
@@ -0,0 +1,337 @@
+package naming
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"subscalpelmkv/internal/model"
+)
+
+// runStartTime is the time shared by {date}, {time} and {timestamp}, so every file of a batch gets the same value
+var runStartTime = time.Now()
+
+// SubtitlesFileName builds the output path of a track using custom configuration. When the output
+// directory cannot be created or config.Namer fails, it falls back to the input file's directory or
+// the template name and returns the path together with an error describing the fallback.
+func SubtitlesFileName(inputFileName string, track model.MKVTrack, config model.OutputConfig) (string, error) {
+	var fallbacks []error
+	outputDir := OutputDirectory(inputFileName, config)
+
+	// Always create output directory if it doesn't exist and a custom output directory is specified
+	if config.OutputDir != "" {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			fallbacks = append(fallbacks, fmt.Errorf("could not create output directory %s: %v", outputDir, err))
+			// Fall back to input file directory
+			outputDir = filepath.Dir(inputFileName)
+		}
+	}
+
+	fileName := BuildFileNameFromTemplate(inputFileName, track, config)
+	if config.Namer != nil {
+		if name, err := config.Namer.Name(inputFileName, track, fileName, config); err != nil {
+			fallbacks = append(fallbacks, fmt.Errorf("naming track %d: %v; using %s", track.Properties.Number, err, fileName))
+		} else {
+			fileName = name
+		}
+	}
+	fileName = SafeFileName(fileName, config.SafeNames)
+
+	return filepath.Join(outputDir, fileName), errors.Join(fallbacks...)
+}
+
+// BuildFileNameFromTemplate builds a filename from config.Template (the default template when empty).
+// config.LanguageNameLocale selects the language used for {languagename} (empty for English),
+// config.FieldSeparator joins the fields of the default template (empty for a dot) and config.Chapter
+// fills {chapter}. Empty fields are dropped around their separator.
+func BuildFileNameFromTemplate(inputFileName string, track model.MKVTrack, config model.OutputConfig) string {
+	template := config.Template
+	if template == "" {
+		template = model.DefaultOutputTemplate
+	}
+	separator := config.FieldSeparator
+	if separator == "" {
+		separator = "."
+	}
+	if template == model.DefaultOutputTemplate && separator != "." {
+		// Keep the dot before the extension so players still recognize the file type
+		template = strings.ReplaceAll(strings.TrimSuffix(template, ".{extension}"), ".", separator) + ".{extension}"
+	}
+	if config.Chapter > 0 && !strings.Contains(template, "{chapter") {
+		// Chapter files of one track need distinct names even when the template has no {chapter}
+		if strings.HasSuffix(template, ".{extension}") {
+			template = strings.TrimSuffix(template, ".{extension}") + separator + "ch{chapter}.{extension}"
+		} else {
+			template += separator + "ch{chapter}"
+		}
+	}
+
+	fileName := filepath.Base(inputFileName)
+	extension := filepath.Ext(fileName)
+	baseName := strings.TrimSuffix(fileName, extension)
+
+	subtitleExt := model.TrackExtension(track.Properties.CodecId) // Video and audio too, for --all-tracks
+	if subtitleExt == "" {
+		subtitleExt = "srt" // fallback
+	}
+
+	// Special handling for S_VOBSUB: ensure we use .sub extension
+	// (mkvextract will create both .idx and .sub files automatically)
+	if track.Properties.CodecId == "S_VOBSUB" {
+		subtitleExt = "sub"
+	}
+
+	// Format track number with leading zeros
+	trackNo := fmt.Sprintf("%03d", track.Properties.Number)
+
+	replacements := map[string]string{
+		"{basename}":     baseName,
+		"{language}":     sanitizeFileName(track.FormatLanguage(config.LanguageStyle, config.LanguageNameLocale)),
+		"{languagename}": sanitizeFileName(model.GetLocalizedLanguageName(track.LanguageTag(), config.LanguageNameLocale)),
+		"{trackno}":      trackNo,
+		"{trackname}":    sanitizeFileName(track.Properties.TrackName),
+		"{forced}":       "",
+		"{default}":      "",
+		"{extension}":    subtitleExt,
+		"{date}":         runStartTime.Format("2006-01-02"),
+		"{time}":         runStartTime.Format("15-04-05"), // No colons, which Windows forbids in filenames
+		"{timestamp}":    runStartTime.Format("20060102-150405"),
+		"{chapter}":      "",
+	}
+	if config.Chapter > 0 {
+		replacements["{chapter}"] = fmt.Sprintf("%02d", config.Chapter)
+	}
+
+	if track.Properties.Forced {
+		replacements["{forced}"] = "forced"
+	}
+	if track.Properties.Default {
+		replacements["{default}"] = "default"
+	}
+
+	// Expand placeholders in one pass, applying modifiers such as {language:upper}; unknown ones stay as written
+	render := func() string {
+		result := model.PlaceholderPattern.ReplaceAllStringFunc(template, func(field string) string {
+			match := model.PlaceholderPattern.FindStringSubmatch(field)
+			if match[1] == "hash" {
+				return hashPlaceholder(inputFileName, track, match[2]) // Computed only when used
+			}
+			value, known := replacements["{"+match[1]+"}"]
+			if !known {
+				return field
+			}
+			return applyTemplateModifier(value, match[2])
+		})
+
+		// Clean up separators left by empty fields
+		return cleanupFileName(result, separator)
+	}
+
+	// Shorten the least important fields until every path component fits the filesystem limit,
+	// keeping the language, flags and extension intact
+	result := render()
+	for _, field := range []string{"{trackname}", "{basename}"} {
+		for overflow := nameOverflow(result); overflow > 0 && replacements[field] != ""; overflow = nameOverflow(result) {
+			replacements[field] = truncateBytes(replacements[field], len(replacements[field])-overflow)
+			result = render()
+		}
+	}
+
+	return result
+}
+
+// maxFileNameBytes is the longest file name ext4, Btrfs and APFS accept. NTFS and exFAT count
+// 255 UTF-16 units instead, which a name within 255 UTF-8 bytes never exceeds.
+const maxFileNameBytes = 255
+
+// nameOverflow returns how many bytes the longest component of path exceeds maxFileNameBytes by
+func nameOverflow(path string) int {
+	overflow := 0
+	for _, component := range strings.Split(filepath.ToSlash(path), "/") {
+		overflow = max(overflow, len(component)-maxFileNameBytes)
+	}
+	return overflow
+}
+
+// truncateBytes shortens s to at most n bytes without splitting a UTF-8 sequence, dropping trailing spaces
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return strings.TrimRight(s[:n], " ")
+}
+
+// hashPlaceholder renders {hash}: the first DefaultHashLength (or the given length) hex characters of
+// trackHash, with any other modifier applied to the result
+func hashPlaceholder(inputFileName string, track model.MKVTrack, modifier string) string {
+	hash := trackHash(inputFileName, track)
+	length := model.DefaultHashLength
+	if n, err := strconv.Atoi(modifier); err == nil {
+		length = n
+		modifier = ""
+	}
+	if length > 0 && length < len(hash) {
+		hash = hash[:length]
+	}
+	return applyTemplateModifier(hash, modifier)
+}
+
+var (
+	fileHashes   = make(map[string]string)
+	fileHashesMu sync.Mutex
+)
+
+// trackHash returns a hex SHA-256 digest identifying a track: of its Matroska UID, which is
+// generated randomly at muxing so separate cuts of a title differ, or of the source file contents
+// when the track has no UID. File digests are cached, and an unreadable file yields an empty value.
+func trackHash(inputFileName string, track model.MKVTrack) string {
+	if track.Properties.UId.Sign() != 0 {
+		sum := sha256.Sum256([]byte(track.Properties.UId.String()))
+		return hex.EncodeToString(sum[:])
+	}
+
+	fileHashesMu.Lock()
+	defer fileHashesMu.Unlock()
+	if hash, cached := fileHashes[inputFileName]; cached {
+		return hash
+	}
+
+	file, err := os.Open(inputFileName)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return ""
+	}
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	fileHashes[inputFileName] = hash
+	return hash
+}
+
+// applyTemplateModifier transforms a placeholder value by one of model.TemplateModifiers; an empty
+// or unknown modifier leaves it unchanged
+func applyTemplateModifier(value, modifier string) string {
+	switch modifier {
+	case "lower":
+		return strings.ToLower(value)
+	case "upper":
+		return strings.ToUpper(value)
+	case "slug":
+		return slugify(value)
+	}
+	return value
+}
+
+// slugify lowercases text and joins its letter and digit runs with dashes, e.g. "Signs & Songs" -> "signs-songs"
+func slugify(text string) string {
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			pendingDash = false
+		} else {
+			pendingDash = true
+		}
+	}
+	return b.String()
+}
+
+// sanitizeFileName removes or replaces characters that are invalid in filenames
+func sanitizeFileName(filename string) string {
+	if filename == "" {
+		return ""
+	}
+
+	// Replace problematic characters with safe alternatives
+	replacements := map[string]string{
+		"/":  "-", // Forward slash
+		"\\": "-", // Backslash
+		":":  "-", // Colon
+		"*":  "",  // Asterisk
+		"?":  "",  // Question mark
+		"\"": "",  // Double quote
+		"<":  "",  // Less than
+		">":  "",  // Greater than
+		"|":  "-", // Pipe
+	}
+
+	result := filename
+	for invalid, replacement := range replacements {
+		result = strings.ReplaceAll(result, invalid, replacement)
+	}
+
+	// Remove leading/trailing spaces and dots
+	result = strings.Trim(result, " .")
+
+	return result
+}
+
+// cleanupFileName removes empty segments and cleans up the filename. Besides dots, runs of
+// separator are collapsed and trimmed from the ends of each dot-separated segment.
+func cleanupFileName(filename, separator string) string {
+	parts := strings.Split(filename, ".")
+	var cleanParts []string
+
+	for _, part := range parts {
+		if separator != "." && separator != "" {
+			for strings.Contains(part, separator+separator) {
+				part = strings.ReplaceAll(part, separator+separator, separator)
+			}
+			part = strings.Trim(part, separator)
+		}
+		if part != "" {
+			cleanParts = append(cleanParts, part)
+		}
+	}
+
+	return strings.Join(cleanParts, ".")
+}
+
+// ResolveOutputDirectory resolves special output directory markers based on the input file
+func ResolveOutputDirectory(outputDir, inputFileName string) string {
+	if outputDir == "__BASENAME_SUBTITLES__" || outputDir == "BATCH_BASENAME_SUBTITLES" {
+		baseName := TrimExtension(filepath.Base(inputFileName))
+		return filepath.Join(filepath.Dir(inputFileName), baseName+"-subtitles")
+	}
+	return outputDir
+}
+
+// OutputDirectory returns the directory the subtitles extracted from inputFileName are written to
+func OutputDirectory(inputFileName string, config model.OutputConfig) string {
+	switch {
+	case config.OutputDir == "":
+		return filepath.Dir(inputFileName)
+	case config.OutputDir == "__BASENAME_SUBTITLES__" || config.OutputDir == "BATCH_BASENAME_SUBTITLES":
+		return ResolveOutputDirectory(config.OutputDir, inputFileName)
+	case config.OutputLayout == model.OutputLayoutPerFile:
+		return filepath.Join(config.OutputDir, SafeFileName(TrimExtension(filepath.Base(inputFileName)), config.SafeNames))
+	}
+	return config.OutputDir
+}
+
+// TrimExtension removes the file extension from a filename
+func TrimExtension(filename string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename))
+}
@@ -0,0 +1,66 @@
+package naming
+
+import (
+	"path/filepath"
+	"strings"
+
+	"subscalpelmkv/internal/model"
+)
+
+// windowsReservedNames are device names Windows refuses as file names, even with an extension (CON.srt)
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// SafeFileName makes each component of a relative output path valid under a --safe-names rule set
+// (model.SafeNamesWindows or model.SafeNamesExFAT). An empty rule set leaves the path unchanged.
+func SafeFileName(path, rules string) string {
+	if rules == "" {
+		return path
+	}
+
+	components := strings.Split(filepath.ToSlash(path), "/")
+	for i, component := range components {
+		components[i] = safeComponent(component, rules)
+	}
+	return filepath.FromSlash(strings.Join(components, "/"))
+}
+
+// safeComponent applies a rule set to a single file or directory name
+func safeComponent(name, rules string) string {
+	// Both filesystems reject control characters and the characters sanitizeFileName handles
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r < 0x20:
+		case strings.ContainsRune(`:\|`, r):
+			b.WriteByte('-')
+		case strings.ContainsRune(`*?"<>`, r):
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	// Spaces before a dot or at the end, as in "Movie .eng.srt", are dropped or rejected by Windows clients
+	segments := strings.Split(b.String(), ".")
+	for i := range segments {
+		segments[i] = strings.TrimRight(segments[i], " ")
+	}
+	result := strings.Join(segments, ".")
+
+	if rules == model.SafeNamesWindows {
+		// Windows strips trailing dots, so such a file could not be opened by its name again
+		result = strings.TrimRight(result, ". ")
+		stem, _, _ := strings.Cut(result, ".")
+		if windowsReservedNames[strings.ToUpper(stem)] {
+			result = "_" + result
+		}
+	}
+
+	if result == "" {
+		return "_"
+	}
+	return result
+}
@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"subscalpelmkv/internal/model"
+)
+
+// Sink types of NotificationConfig.Type
+const (
+	TypeDiscord = "discord"
+	TypeSlack   = "slack"
+	TypeWebhook = "webhook"
+)
+
+// When a sink posts, from NotificationConfig.On
+const (
+	OnAlways  = "always"
+	OnFailure = "failure"
+)
+
+// maxErrors is how many file errors a summary message lists
+const maxErrors = 5
+
+// maxDiscordLength keeps messages below Discord's limit of 2000 characters per message
+const maxDiscordLength = 1900
+
+// Summary describes a finished run
+type Summary struct {
+	Mode      string        `json:"mode"` // "batch" or "extract"
+	Files     int           `json:"files"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Extracted int           `json:"extracted"` // Tracks extracted over all files
+	Skipped   int           `json:"skipped"`   // Selected tracks left out, e.g. for existing outputs
+	Errors    []string      `json:"errors"`    // "file: error" for the first failed files
+	Duration  time.Duration `json:"-"`
+}
+
+// AddFile counts the outcome of one processed file
+func (s *Summary) AddFile(fileName string, result model.FileResult, err error) {
+	s.Files++
+	s.Extracted += result.Extracted
+	s.Skipped += result.Skipped
+	if err != nil {
+		s.Failed++
+		if len(s.Errors) < maxErrors {
+			s.Errors = append(s.Errors, fmt.Sprintf("%s: %v", fileName, err))
+		}
+	} else {
+		s.Succeeded++
+	}
+}
+
+// Text renders the summary as a chat message
+func (s Summary) Text() string {
+	var text strings.Builder
+	status := "finished"
+	if s.Failed > 0 {
+		status = "failed"
+	}
+	fmt.Fprintf(&text, "SubScalpelMKV %s %s: %d file(s), %d processed, %d failed, %d track(s) extracted",
+		s.Mode, status, s.Files, s.Succeeded, s.Failed, s.Extracted)
+	if s.Skipped > 0 {
+		fmt.Fprintf(&text, ", %d skipped", s.Skipped)
+	}
+	fmt.Fprintf(&text, " in %v", s.Duration.Round(time.Second))
+	for _, message := range s.Errors {
+		text.WriteString("\n• " + message)
+	}
+	if s.Failed > len(s.Errors) {
+		fmt.Fprintf(&text, "\n• and %d more", s.Failed-len(s.Errors))
+	}
+	return text.String()
+}
+
+// Validate checks a sink's type, URL and condition
+func Validate(sink model.NotificationConfig) error {
+	switch strings.ToLower(sink.Type) {
+	case TypeDiscord, TypeSlack, TypeWebhook:
+	default:
+		return fmt.Errorf("unknown type '%s' (supported: %s, %s, %s)", sink.Type, TypeDiscord, TypeSlack, TypeWebhook)
+	}
+	parsed, err := url.Parse(sink.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url '%s' is not an http(s) URL", sink.URL)
+	}
+	switch strings.ToLower(sink.On) {
+	case "", OnAlways, OnFailure:
+	default:
+		return fmt.Errorf("unknown condition '%s' (supported: %s, %s)", sink.On, OnAlways, OnFailure)
+	}
+	return nil
+}
+
+// Send posts the summary to every sink whose condition it meets, returning the errors of the sinks
+// that could not be reached
+func Send(ctx context.Context, sinks []model.NotificationConfig, summary Summary) []error {
+	client := &http.Client{Timeout: 15 * time.Second}
+	var errs []error
+	for _, sink := range sinks {
+		if strings.EqualFold(sink.On, OnFailure) && summary.Failed == 0 {
+			continue
+		}
+
+		var payload interface{}
+		switch strings.ToLower(sink.Type) {
+		case TypeDiscord:
+			text := summary.Text()
+			if len(text) > maxDiscordLength {
+				text = strings.ToValidUTF8(text[:maxDiscordLength], "") + "…"
+			}
+			payload = map[string]string{"content": text}
+		case TypeSlack:
+			payload = map[string]string{"text": summary.Text()}
+		default:
+			if summary.Errors == nil {
+				summary.Errors = []string{}
+			}
+			payload = struct {
+				Summary
+				Text            string  `json:"text"`
+				DurationSeconds float64 `json:"duration_seconds"`
+			}{summary, summary.Text(), summary.Duration.Seconds()}
+		}
+
+		if err := post(ctx, client, sink.URL, payload); err != nil {
+			errs = append(errs, fmt.Errorf("%s notification failed: %v", strings.ToLower(sink.Type), err))
+		}
+	}
+	return errs
+}
+
+// post sends a JSON payload to a webhook URL, accepting any 2xx response
+func post(ctx context.Context, client *http.Client, webhookURL string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		// The URL carries the webhook's secret token, so it stays out of the message
+		if urlErr, ok := err.(*url.Error); ok {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if message := strings.TrimSpace(string(data)); message != "" {
+			return fmt.Errorf("server returned %s: %s", resp.Status, message)
+		}
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	return nil
+}
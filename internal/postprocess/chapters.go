@@ -0,0 +1,151 @@
+package postprocess
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/subtitle"
+	"subscalpelmkv/internal/util"
+)
+
+// SplitByChapters replaces every extracted SRT/ASS/SSA file with one file per chapter, with cue times
+// relative to the chapter start. Cues crossing a chapter boundary are cut at it and chapters without
+// cues are left out. It returns the jobs with each split track replaced by one job per chapter file;
+// image-based tracks are kept whole.
+func SplitByChapters(inputFileName string, jobs []model.ExtractionJob, chapters []model.Chapter, outputConfig model.OutputConfig) ([]model.ExtractionJob, error) {
+	var result []model.ExtractionJob
+	splitCount := 0
+	for _, job := range jobs {
+		ext := strings.ToLower(filepath.Ext(job.OutFileName))
+		if ext != ".srt" && ext != ".ass" && ext != ".ssa" {
+			format.PrintWarning(fmt.Sprintf("Not splitting track %d: only text subtitles can be split by chapters", job.OriginalTrack.Properties.Number))
+			result = append(result, job)
+			continue
+		}
+
+		// Chapter names are rebuilt from the track, so keep what earlier steps added to the name, such as
+		// the .nonsdh of a --strip-hi copy, or the copy's chapters would overwrite the original's
+		variant := variantSuffix(job.OutFileName, util.BuildSubtitlesFileNameWithConfig(inputFileName, job.OriginalTrack, outputConfig))
+
+		var chapterJobs []model.ExtractionJob
+		for i, chapter := range chapters {
+			end := time.Duration(1<<63 - 1)
+			if i+1 < len(chapters) {
+				end = chapters[i+1].Start
+			}
+
+			chapterConfig := outputConfig
+			chapterConfig.Chapter = chapter.Number
+			outFileName := util.BuildSubtitlesFileNameWithConfig(inputFileName, job.OriginalTrack, chapterConfig)
+			// Keep the extension of the extracted file, which --strip-ass srt may have changed
+			outFileName = strings.TrimSuffix(outFileName, filepath.Ext(outFileName)) + variant + filepath.Ext(job.OutFileName)
+
+			written, err := writeChapter(job.OutFileName, outFileName, chapter.Start, end)
+			if err != nil {
+				return nil, err
+			}
+			if written {
+				chapterJob := job
+				chapterJob.OutFileName = outFileName
+				chapterJobs = append(chapterJobs, chapterJob)
+			}
+		}
+
+		if len(chapterJobs) == 0 {
+			format.PrintWarning(fmt.Sprintf("Track %d has no cues inside the chapters, kept as a single file", job.OriginalTrack.Properties.Number))
+			result = append(result, job)
+			continue
+		}
+		if err := os.Remove(job.OutFileName); err != nil {
+			format.PrintWarning(fmt.Sprintf("Could not remove %s: %v", filepath.Base(job.OutFileName), err))
+		}
+
+		format.SuccessColor.Print("  ✓ ")
+		format.BaseFg.Println(fmt.Sprintf("Track %d: split into %d chapter file(s)", job.OriginalTrack.Properties.Number, len(chapterJobs)))
+		for _, chapterJob := range chapterJobs {
+			format.PrintExample(fmt.Sprintf("    → %s", chapterJob.OutFileName))
+		}
+		result = append(result, chapterJobs...)
+		splitCount++
+	}
+
+	if splitCount == 0 {
+		format.PrintWarning("No text subtitle tracks to split")
+	}
+	return result, nil
+}
+
+// variantSuffix returns what follows the template name of a track in the name of its extracted file,
+// before the extension, or an empty string when the file does not carry the template name
+func variantSuffix(outFileName, templateName string) string {
+	stem := strings.TrimSuffix(outFileName, filepath.Ext(outFileName))
+	templateStem := strings.TrimSuffix(templateName, filepath.Ext(templateName))
+	suffix, _ := strings.CutPrefix(stem, templateStem)
+	if suffix == stem {
+		return ""
+	}
+	return suffix
+}
+
+// writeChapter writes the cues of sourceFile between start and end to outFileName, shifted to start at
+// zero. It reports false without writing anything when no cue falls inside the chapter.
+func writeChapter(sourceFile, outFileName string, start, end time.Duration) (bool, error) {
+	if ext := strings.ToLower(filepath.Ext(sourceFile)); ext == ".ass" || ext == ".ssa" {
+		doc, err := subtitle.ReadASSFile(sourceFile)
+		if err != nil {
+			return false, fmt.Errorf("failed to read %s: %v", filepath.Base(sourceFile), err)
+		}
+
+		var events []subtitle.ASSEvent
+		for _, event := range doc.Events {
+			eventStart, startErr := subtitle.ParseASSTimestamp(doc.Field(event, "Start"))
+			eventEnd, endErr := subtitle.ParseASSTimestamp(doc.Field(event, "End"))
+			if startErr != nil || endErr != nil || eventEnd <= start || eventStart >= end {
+				continue
+			}
+			event.Fields = append([]string(nil), event.Fields...)
+			doc.SetField(&event, "Start", subtitle.FormatASSTimestamp(max(eventStart, start)-start))
+			doc.SetField(&event, "End", subtitle.FormatASSTimestamp(min(eventEnd, end)-start))
+			events = append(events, event)
+		}
+		if len(events) == 0 {
+			return false, nil
+		}
+
+		chapterDoc := *doc
+		chapterDoc.Events = events
+		if err := subtitle.WriteASSFile(outFileName, &chapterDoc); err != nil {
+			return false, fmt.Errorf("failed to write %s: %v", filepath.Base(outFileName), err)
+		}
+		return true, nil
+	}
+
+	cues, err := subtitle.ReadSRTFile(sourceFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %v", filepath.Base(sourceFile), err)
+	}
+
+	var chapterCues []subtitle.Cue
+	for _, cue := range cues {
+		if cue.End <= start || cue.Start >= end {
+			continue
+		}
+		cue.Index = len(chapterCues) + 1
+		cue.Start = max(cue.Start, start) - start
+		cue.End = min(cue.End, end) - start
+		chapterCues = append(chapterCues, cue)
+	}
+	if len(chapterCues) == 0 {
+		return false, nil
+	}
+
+	if err := subtitle.WriteSRTFile(outFileName, chapterCues); err != nil {
+		return false, fmt.Errorf("failed to write %s: %v", filepath.Base(outFileName), err)
+	}
+	return true, nil
+}
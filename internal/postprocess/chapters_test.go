@@ -0,0 +1,71 @@
+package postprocess
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/util"
+)
+
+func TestSplitByChaptersKeepsStripHICopiesApart(t *testing.T) {
+	dir := t.TempDir()
+	inputFileName := filepath.Join(dir, "Movie.mkv")
+	outputConfig := model.OutputConfig{Template: model.DefaultOutputTemplate}
+
+	var track model.MKVTrack
+	track.Type = "subtitles"
+	track.Properties.Number = 3
+	track.Properties.Language = "eng"
+	track.Properties.CodecId = "S_TEXT/UTF8"
+
+	outFileName := util.BuildSubtitlesFileNameWithConfig(inputFileName, track, outputConfig)
+	srt := "1\n00:00:01,000 --> 00:00:02,000\n[door slams] Hello.\n\n" +
+		"2\n00:01:01,000 --> 00:01:02,000\nJOHN: Goodbye.\n"
+	if err := os.WriteFile(outFileName, []byte(srt), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := []model.ExtractionJob{{Track: track, OriginalTrack: track, OutFileName: outFileName}}
+	jobs, err := StripHearingImpaired(jobs, StripHICopy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chapters := []model.Chapter{{Number: 1, Start: 0}, {Number: 2, Start: time.Minute}}
+	jobs, err = SplitByChapters(inputFileName, jobs, chapters, outputConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var outputs []string
+	for _, job := range jobs {
+		outputs = append(outputs, filepath.Base(job.OutFileName))
+		if _, err := os.Stat(job.OutFileName); err != nil {
+			t.Errorf("output %s: %v", filepath.Base(job.OutFileName), err)
+		}
+	}
+	want := []string{"Movie.eng.003.ch01.srt", "Movie.eng.003.ch02.srt", "Movie.eng.003.ch01.nonsdh.srt", "Movie.eng.003.ch02.nonsdh.srt"}
+	if !slices.Equal(outputs, want) {
+		t.Errorf("outputs = %q, want %q", outputs, want)
+	}
+
+	// The copy's chapters must not overwrite the original's, which keep their annotations
+	original, err := os.ReadFile(filepath.Join(dir, "Movie.eng.003.ch01.srt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(original), "[door slams]") {
+		t.Errorf("original chapter lost its annotations:\n%s", original)
+	}
+	copied, err := os.ReadFile(filepath.Join(dir, "Movie.eng.003.ch01.nonsdh.srt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(copied), "[door slams]") {
+		t.Errorf("non-SDH chapter kept its annotations:\n%s", copied)
+	}
+}
@@ -0,0 +1,154 @@
+package postprocess
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/subtitle"
+)
+
+// DefaultCleanupRules are used by --cleanup when the configuration has no cleanup_rules. They match
+// the advertising and credit cues most often found in downloaded subtitles.
+var DefaultCleanupRules = []string{
+	`(?i)downloaded from`,
+	`(?i)\b(subtitles?|subs|synced?|sync and corrections?|corrections?|ripped|encoded)\s+(by|from)\b`,
+	`(?i)https?://|\bwww\.[a-z0-9-]+\.[a-z]{2,}`,
+	`(?i)\b(opensubtitles|addic7ed|subscene|podnapisi)\b`,
+	`(?i)become (a )?vip member`,
+}
+
+// CompileCleanupRules compiles cleanup regular expressions, reporting the first invalid one
+func CompileCleanupRules(rules []string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(rules))
+	for _, rule := range rules {
+		pattern, err := regexp.Compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cleanup rule '%s': %v", rule, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// CleanupCues removes the cues matching any of the rules from the extracted SRT and ASS/SSA tracks
+func CleanupCues(jobs []model.ExtractionJob, rules []string) error {
+	var paths []string
+	for _, job := range jobs {
+		switch strings.ToLower(filepath.Ext(job.OutFileName)) {
+		case ".srt", ".ass", ".ssa":
+			paths = append(paths, job.OutFileName)
+		}
+	}
+	if len(paths) == 0 {
+		format.PrintWarning("No text subtitle tracks to clean up")
+		return nil
+	}
+
+	_, err := CleanupFiles(paths, rules, false)
+	return err
+}
+
+// CleanupFiles removes the cues matching any of the rules from SRT and ASS/SSA files and lists each
+// removed cue. In a dry run the files are left untouched. It returns the number of cues removed.
+func CleanupFiles(paths []string, rules []string, dryRun bool) (int, error) {
+	patterns, err := CompileCleanupRules(rules)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, path := range paths {
+		removed, err := cleanupFile(path, patterns, dryRun)
+		if err != nil {
+			return total, err
+		}
+		total += len(removed)
+
+		switch {
+		case len(removed) == 0:
+			format.PrintInfo(fmt.Sprintf("%s: nothing to remove", filepath.Base(path)))
+			continue
+		case dryRun:
+			format.PrintInfo(fmt.Sprintf("%s: would remove %d cue(s)", filepath.Base(path), len(removed)))
+		default:
+			format.PrintSuccess(fmt.Sprintf("%s: removed %d cue(s)", filepath.Base(path), len(removed)))
+		}
+		for _, cue := range removed {
+			format.ErrorColor.Println(fmt.Sprintf("    - %s  %s", subtitle.FormatSRTTimestamp(cue.Start), strings.ReplaceAll(cue.Text, "\n", " / ")))
+		}
+	}
+	return total, nil
+}
+
+// cleanupFile drops the cues of one file that match a pattern, returning them
+func cleanupFile(path string, patterns []*regexp.Regexp, dryRun bool) ([]subtitle.Cue, error) {
+	var removed []subtitle.Cue
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ass", ".ssa":
+		doc, err := subtitle.ReadASSFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", filepath.Base(path), err)
+		}
+
+		var kept []subtitle.ASSEvent
+		for _, event := range doc.Events {
+			text := subtitle.PlainASSText(doc.Field(event, "Text"))
+			if event.Kind == "Dialogue" && matchesAny(patterns, text) {
+				start, _ := subtitle.ParseASSTimestamp(doc.Field(event, "Start"))
+				removed = append(removed, subtitle.Cue{Start: start, Text: text})
+				continue
+			}
+			kept = append(kept, event)
+		}
+		if len(removed) == 0 || dryRun {
+			return removed, nil
+		}
+
+		doc.Events = kept
+		if err := subtitle.WriteASSFile(path, doc); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %v", filepath.Base(path), err)
+		}
+		return removed, nil
+
+	case ".srt":
+		cues, err := subtitle.ReadSRTFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", filepath.Base(path), err)
+		}
+
+		var kept []subtitle.Cue
+		for _, cue := range cues {
+			if matchesAny(patterns, cue.Text) {
+				removed = append(removed, cue)
+				continue
+			}
+			cue.Index = len(kept) + 1
+			kept = append(kept, cue)
+		}
+		if len(removed) == 0 || dryRun {
+			return removed, nil
+		}
+
+		if err := subtitle.WriteSRTFile(path, kept); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %v", filepath.Base(path), err)
+		}
+		return removed, nil
+	}
+
+	return nil, fmt.Errorf("unsupported subtitle format: %s", filepath.Ext(path))
+}
+
+// matchesAny reports whether text matches one of the patterns
+func matchesAny(patterns []*regexp.Regexp, text string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package postprocess
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/subtitle"
+)
+
+// ConvertASS is the --convert target that turns SRT tracks into ASS scripts
+const ConvertASS = "ass"
+
+// ConvertSRTToASS replaces every extracted SRT file with an ASS script and updates the job's output
+// name. The header and style come from the ASS script at stylePath, or a plain default when it is empty.
+func ConvertSRTToASS(jobs []model.ExtractionJob, stylePath string) error {
+	var header []string
+	var style string
+	if stylePath != "" {
+		var err error
+		if header, style, err = subtitle.StyleTemplate(stylePath); err != nil {
+			return fmt.Errorf("failed to read style template %s: %v", stylePath, err)
+		}
+	}
+
+	convertedCount := 0
+	for i := range jobs {
+		job := &jobs[i]
+		if !strings.EqualFold(filepath.Ext(job.OutFileName), ".srt") {
+			continue
+		}
+
+		cues, err := subtitle.ReadSRTFile(job.OutFileName)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", filepath.Base(job.OutFileName), err)
+		}
+
+		assFileName := strings.TrimSuffix(job.OutFileName, filepath.Ext(job.OutFileName)) + ".ass"
+		if err := subtitle.WriteASSFile(assFileName, subtitle.CuesToASS(cues, header, style)); err != nil {
+			return fmt.Errorf("failed to write %s: %v", filepath.Base(assFileName), err)
+		}
+		if err := os.Remove(job.OutFileName); err != nil {
+			format.PrintWarning(fmt.Sprintf("Could not remove %s: %v", filepath.Base(job.OutFileName), err))
+		}
+		job.OutFileName = assFileName
+
+		format.SuccessColor.Print("  ✓ ")
+		format.BaseFg.Println(fmt.Sprintf("Track %d: converted %d cue(s) to ASS", job.OriginalTrack.Properties.Number, len(cues)))
+		format.PrintExample(fmt.Sprintf("    → %s", job.OutFileName))
+		convertedCount++
+	}
+
+	if convertedCount == 0 {
+		format.PrintWarning("No SRT tracks to convert")
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package postprocess
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/subtitle"
+)
+
+// Output modes for StripHearingImpaired
+const (
+	StripHICopy    = "copy"    // Write a non-SDH copy next to the original
+	StripHIReplace = "replace" // Rewrite the original file without the annotations
+)
+
+// StripHISuffix is added before the extension of the non-SDH copies
+const StripHISuffix = ".nonsdh"
+
+var (
+	// soundDescriptionPattern matches [door slams] and (laughs) annotations, which may span lines
+	soundDescriptionPattern = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)`)
+	// speakerLabelPattern matches an upper-case speaker name opening a line: "JOHN:", "- MAN 2:"
+	speakerLabelPattern = regexp.MustCompile(`^(-\s*)?[A-Z][A-Z0-9 .'&-]*:\s*`)
+	// musicLinePattern matches lines sung or played as music: ♪ la la la ♪
+	musicLinePattern = regexp.MustCompile(`^(<[^>]+>)*\s*[♪♫#]`)
+	// emptyTagPattern matches formatting tags left without content
+	emptyTagPattern = regexp.MustCompile(`<(i|b|u|font)[^>]*>\s*</(i|b|u|font)>`)
+	// htmlTagPattern matches SRT formatting tags
+	htmlTagPattern = regexp.MustCompile(`<[^>]+>`)
+)
+
+// StripHearingImpaired removes sound descriptions, speaker labels and music cues from the extracted
+// SRT and ASS/SSA tracks. In StripHICopy mode the originals are kept and a job is added for each copy.
+func StripHearingImpaired(jobs []model.ExtractionJob, mode string) ([]model.ExtractionJob, error) {
+	result := jobs
+	textCount := 0
+	for _, job := range jobs {
+		ext := filepath.Ext(job.OutFileName)
+		switch strings.ToLower(ext) {
+		case ".srt", ".ass", ".ssa":
+		default:
+			continue
+		}
+
+		textCount++
+
+		outFileName := job.OutFileName
+		if mode == StripHICopy {
+			outFileName = strings.TrimSuffix(job.OutFileName, ext) + StripHISuffix + ext
+		}
+
+		removed, changed, err := stripHIFile(job.OutFileName, outFileName)
+		if err != nil {
+			return nil, err
+		}
+		if removed == 0 && changed == 0 {
+			format.PrintInfo(fmt.Sprintf("Track %d: no hearing-impaired annotations found", job.OriginalTrack.Properties.Number))
+			continue
+		}
+
+		format.SuccessColor.Print("  ✓ ")
+		format.BaseFg.Println(fmt.Sprintf("Track %d: removed %d cue(s), cleaned %d cue(s)",
+			job.OriginalTrack.Properties.Number, removed, changed))
+		format.PrintExample(fmt.Sprintf("    → %s", outFileName))
+
+		if mode == StripHICopy {
+			copyJob := job
+			copyJob.OutFileName = outFileName
+			result = append(result, copyJob)
+		}
+	}
+
+	if textCount == 0 {
+		format.PrintWarning("No text subtitle tracks to strip")
+	}
+	return result, nil
+}
+
+// stripHIFile writes sourceFile without hearing-impaired annotations to outFileName, returning the
+// number of cues dropped because nothing was left and the number of cues shortened. Nothing is
+// written when the file has no annotations.
+func stripHIFile(sourceFile, outFileName string) (int, int, error) {
+	removed, changed := 0, 0
+
+	if ext := strings.ToLower(filepath.Ext(sourceFile)); ext == ".ass" || ext == ".ssa" {
+		doc, err := subtitle.ReadASSFile(sourceFile)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to read %s: %v", filepath.Base(sourceFile), err)
+		}
+
+		var kept []subtitle.ASSEvent
+		for _, event := range doc.Events {
+			if event.Kind != "Dialogue" {
+				kept = append(kept, event)
+				continue
+			}
+			text := subtitle.PlainASSText(doc.Field(event, "Text"))
+			stripped := StripHIText(text)
+			switch {
+			case stripped == "":
+				removed++
+				continue
+			case stripped != text:
+				// Override tags are lost on the lines that change, which is what a non-SDH track wants anyway
+				event.Fields = append([]string(nil), event.Fields...)
+				doc.SetField(&event, "Text", subtitle.ASSText(stripped))
+				changed++
+			}
+			kept = append(kept, event)
+		}
+
+		if removed == 0 && changed == 0 {
+			return 0, 0, nil
+		}
+		doc.Events = kept
+		if err := subtitle.WriteASSFile(outFileName, doc); err != nil {
+			return 0, 0, fmt.Errorf("failed to write %s: %v", filepath.Base(outFileName), err)
+		}
+		return removed, changed, nil
+	}
+
+	cues, err := subtitle.ReadSRTFile(sourceFile)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read %s: %v", filepath.Base(sourceFile), err)
+	}
+
+	var kept []subtitle.Cue
+	for _, cue := range cues {
+		stripped := StripHIText(cue.Text)
+		switch {
+		case stripped == "":
+			removed++
+			continue
+		case stripped != cue.Text:
+			cue.Text = stripped
+			changed++
+		}
+		cue.Index = len(kept) + 1
+		kept = append(kept, cue)
+	}
+
+	if removed == 0 && changed == 0 {
+		return 0, 0, nil
+	}
+	if err := subtitle.WriteSRTFile(outFileName, kept); err != nil {
+		return 0, 0, fmt.Errorf("failed to write %s: %v", filepath.Base(outFileName), err)
+	}
+	return removed, changed, nil
+}
+
+// StripHIText removes sound descriptions, speaker labels and music lines from cue text. It returns
+// an empty string when only annotations were left.
+func StripHIText(text string) string {
+	text = soundDescriptionPattern.ReplaceAllString(text, "")
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if musicLinePattern.MatchString(line) {
+			continue
+		}
+		line = speakerLabelPattern.ReplaceAllString(line, "$1")
+		line = emptyTagPattern.ReplaceAllString(line, "")
+		line = strings.Join(strings.Fields(line), " ")
+		if content := strings.TrimSpace(htmlTagPattern.ReplaceAllString(line, "")); content == "" || content == "-" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	// A dialogue dash only makes sense with more than one speaker left
+	if len(lines) == 1 {
+		lines[0] = strings.TrimSpace(strings.TrimPrefix(lines[0], "-"))
+	}
+	return strings.Join(lines, "\n")
+}
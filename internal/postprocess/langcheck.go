@@ -0,0 +1,93 @@
+package postprocess
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/langdetect"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/subtitle"
+	"subscalpelmkv/internal/util"
+)
+
+// LanguageMismatchMarker is added before the extension of files whose text disagrees with the language tag
+const LanguageMismatchMarker = ".mismatch-"
+
+// VerifyLanguages detects the language of the text in every extracted SRT and ASS/SSA track and warns
+// when it disagrees with the track's language tag. Files of mismatched tracks get a .mismatch-<language>
+// marker before the extension; und tracks take the detected language instead, in the file name and
+// for the later steps. It returns the und tracks with their detected language.
+func VerifyLanguages(inputFileName string, jobs []model.ExtractionJob, outputConfig model.OutputConfig) ([]model.MKVTrack, error) {
+	var detectedTracks []model.MKVTrack
+	checkedCount := 0
+	for i := range jobs {
+		job := &jobs[i]
+		ext := filepath.Ext(job.OutFileName)
+		switch strings.ToLower(ext) {
+		case ".srt", ".ass", ".ssa":
+		default:
+			continue
+		}
+
+		cues, err := subtitle.ReadCues(job.OutFileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", filepath.Base(job.OutFileName), err)
+		}
+		var text strings.Builder
+		for _, cue := range cues {
+			text.WriteString(cue.Text + "\n")
+		}
+
+		track := job.OriginalTrack
+		tag := model.FormatLanguageCode(track.Properties.Language, model.LanguageStyleISO6392T, "")
+		verdict := langdetect.Verify(tag, text.String())
+		if !verdict.Checked {
+			continue
+		}
+		checkedCount++
+
+		switch {
+		case tag == "" || strings.EqualFold(tag, "und"):
+			detectedTrack := track
+			detectedTrack.Properties.Language = verdict.Detected
+			outFileName := util.BuildSubtitlesFileNameWithConfig(inputFileName, detectedTrack, outputConfig)
+			outFileName = strings.TrimSuffix(outFileName, filepath.Ext(outFileName)) + ext
+			if outFileName == job.OutFileName {
+				outFileName = strings.TrimSuffix(job.OutFileName, ext) + "." + verdict.Detected + ext
+			}
+			if err := os.Rename(job.OutFileName, outFileName); err != nil {
+				return nil, fmt.Errorf("failed to rename %s: %v", filepath.Base(job.OutFileName), err)
+			}
+			job.OutFileName = outFileName
+			job.OriginalTrack.Properties.Language = verdict.Detected
+			detectedTracks = append(detectedTracks, job.OriginalTrack)
+
+			format.SuccessColor.Print("  ✓ ")
+			format.BaseFg.Println(fmt.Sprintf("Track %d: untagged, detected %s (%s)", track.Properties.Number, verdict.Detected, model.GetLanguageName(verdict.Detected)))
+			format.PrintExample(fmt.Sprintf("    → %s", outFileName))
+
+		case !verdict.Agrees:
+			outFileName := strings.TrimSuffix(job.OutFileName, ext) + LanguageMismatchMarker + verdict.Detected + ext
+			if err := os.Rename(job.OutFileName, outFileName); err != nil {
+				return nil, fmt.Errorf("failed to rename %s: %v", filepath.Base(job.OutFileName), err)
+			}
+			job.OutFileName = outFileName
+
+			format.PrintWarning(fmt.Sprintf("Track %d is tagged %s but its text looks %s (%s)",
+				track.Properties.Number, track.Properties.Language, verdict.Detected, model.GetLanguageName(verdict.Detected)))
+			format.PrintExample(fmt.Sprintf("    → %s", outFileName))
+
+		default:
+			format.SuccessColor.Print("  ✓ ")
+			format.BaseFg.Println(fmt.Sprintf("Track %d: text matches %s", track.Properties.Number, track.Properties.Language))
+		}
+	}
+
+	if checkedCount == 0 {
+		format.PrintWarning("No text subtitle tracks with enough text to verify")
+	}
+	return detectedTracks, nil
+}
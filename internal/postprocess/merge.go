@@ -0,0 +1,111 @@
+package postprocess
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/subtitle"
+	"subscalpelmkv/internal/util"
+)
+
+// ParseMergeLanguages splits a "primary+secondary" language pair such as "eng+jpn"
+func ParseMergeLanguages(value string) (string, string, error) {
+	parts := strings.Split(value, "+")
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return "", "", fmt.Errorf("expected two languages joined by '+' (e.g., 'eng+jpn'), got '%s'", value)
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), nil
+}
+
+// MergeLanguages writes a bilingual subtitle file from the extracted text tracks of two languages
+func MergeLanguages(inputFileName string, jobs []model.ExtractionJob, outputConfig model.OutputConfig) error {
+	primaryLanguage, secondaryLanguage, err := ParseMergeLanguages(outputConfig.MergeLanguages)
+	if err != nil {
+		return err
+	}
+
+	primaryJob, found := findTextJob(jobs, primaryLanguage)
+	if !found {
+		format.PrintWarning(fmt.Sprintf("Cannot merge languages: no extracted text track for '%s'", primaryLanguage))
+		return nil
+	}
+	secondaryJob, found := findTextJob(jobs, secondaryLanguage)
+	if !found {
+		format.PrintWarning(fmt.Sprintf("Cannot merge languages: no extracted text track for '%s'", secondaryLanguage))
+		return nil
+	}
+
+	primaryCues, err := subtitle.ReadCues(primaryJob.OutFileName)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", filepath.Base(primaryJob.OutFileName), err)
+	}
+	secondaryCues, err := subtitle.ReadCues(secondaryJob.OutFileName)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", filepath.Base(secondaryJob.OutFileName), err)
+	}
+
+	// Name the merged file through the template with a combined language code
+	mergedTrack := primaryJob.OriginalTrack
+	mergedTrack.Properties.Language = primaryLanguage + "+" + secondaryLanguage
+	mergedTrack.Properties.TrackName = ""
+	mergedTrack.Properties.Forced = false
+	mergedTrack.Properties.Default = false
+	mergedTrack.Properties.CodecId = "S_TEXT/UTF8"
+	if strings.EqualFold(outputConfig.MergeFormat, "ass") {
+		mergedTrack.Properties.CodecId = "S_TEXT/ASS"
+	}
+
+	outFileName := util.BuildSubtitlesFileNameWithConfig(inputFileName, mergedTrack, outputConfig)
+	for _, job := range jobs {
+		if job.OutFileName == outFileName {
+			ext := filepath.Ext(outFileName)
+			outFileName = strings.TrimSuffix(outFileName, ext) + "." + mergedTrack.Properties.Language + ext
+			break
+		}
+	}
+
+	if mergedTrack.Properties.CodecId == "S_TEXT/ASS" {
+		err = subtitle.WriteASSFile(outFileName, subtitle.BilingualASS(primaryCues, secondaryCues))
+	} else {
+		err = subtitle.WriteSRTFile(outFileName, subtitle.MergeBilingual(primaryCues, secondaryCues))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", filepath.Base(outFileName), err)
+	}
+
+	format.SuccessColor.Print("  ✓ ")
+	format.BaseFg.Println(fmt.Sprintf("Merged track %d (%s) with track %d (%s)",
+		primaryJob.OriginalTrack.Properties.Number, primaryJob.OriginalTrack.Properties.Language,
+		secondaryJob.OriginalTrack.Properties.Number, secondaryJob.OriginalTrack.Properties.Language))
+	format.PrintExample(fmt.Sprintf("    → %s", outFileName))
+	return nil
+}
+
+// findTextJob returns the first extracted text track in the given language, preferring non-forced tracks
+func findTextJob(jobs []model.ExtractionJob, language string) (model.ExtractionJob, bool) {
+	var fallback *model.ExtractionJob
+	for i, job := range jobs {
+		props := job.OriginalTrack.Properties
+		if !isTextFormat(model.GetSubtitleFormatFromCodec(props.CodecId)) || !model.MatchesLanguageFilter(job.OriginalTrack.LanguageTag(), language) {
+			continue
+		}
+		if !props.Forced {
+			return job, true
+		}
+		if fallback == nil {
+			fallback = &jobs[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return model.ExtractionJob{}, false
+}
+
+// isTextFormat reports whether cues can be read from the given subtitle format
+func isTextFormat(subtitleFormat string) bool {
+	return subtitleFormat == "srt" || subtitleFormat == "ass" || subtitleFormat == "ssa"
+}
@@ -0,0 +1,35 @@
+package postprocess
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/subtitle"
+)
+
+// NormalizeTextFiles rewrites the extracted SRT and ASS/SSA files in the format set by
+// subtitle.SetTextFormat. Files a post-processing step wrote are already in that format and stay untouched.
+func NormalizeTextFiles(jobs []model.ExtractionJob) error {
+	normalizedCount := 0
+	for _, job := range jobs {
+		switch strings.ToLower(filepath.Ext(job.OutFileName)) {
+		case ".srt", ".ass", ".ssa":
+		default:
+			continue
+		}
+		if err := subtitle.NormalizeTextFile(job.OutFileName); err != nil {
+			return fmt.Errorf("failed to normalize %s: %v", filepath.Base(job.OutFileName), err)
+		}
+		normalizedCount++
+	}
+
+	if normalizedCount == 0 {
+		format.PrintWarning("No text subtitle tracks to normalize")
+	} else {
+		format.PrintSuccess(fmt.Sprintf("Normalized %d text subtitle file(s)", normalizedCount))
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package postprocess
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/mkv"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/translate"
+)
+
+// Pipeline holds the extracted tracks of one file while the post-processors run over them
+type Pipeline struct {
+	InputFileName string
+	MKVInfo       *model.MKVInfo // Tracks, attachments and chapters of the source file
+	Config        model.OutputConfig
+	Jobs          []model.ExtractionJob // Extracted subtitle tracks; processors may replace or add jobs
+}
+
+// Processor is a post-processing step of the pipeline
+type Processor struct {
+	Name    string                                 // Name used in post_processors lists, e.g. "cleanup"
+	Title   func(config model.OutputConfig) string // Step message shown before the processor runs
+	Enabled func(p *Pipeline) bool                 // Whether the processor has anything to do for this file
+	Run     func(ctx context.Context, p *Pipeline) error
+}
+
+// processors lists the registered post-processors in their default order
+var processors []Processor
+
+// Register adds a post-processor after the registered ones. Its name must be unique.
+func Register(processor Processor) {
+	if _, exists := LookupProcessor(processor.Name); exists {
+		panic(fmt.Sprintf("post-processor %s registered twice", processor.Name))
+	}
+	processors = append(processors, processor)
+}
+
+// LookupProcessor returns the registered post-processor with the given name
+func LookupProcessor(name string) (Processor, bool) {
+	for _, processor := range processors {
+		if processor.Name == name {
+			return processor, true
+		}
+	}
+	return Processor{}, false
+}
+
+// ProcessorNames returns the names of the registered post-processors in their default order
+func ProcessorNames() []string {
+	names := make([]string, len(processors))
+	for i, processor := range processors {
+		names[i] = processor.Name
+	}
+	return names
+}
+
+// ValidateProcessorName checks that a name of a post_processors list is a registered post-processor
+func ValidateProcessorName(name string) error {
+	if _, exists := LookupProcessor(name); !exists {
+		return fmt.Errorf("unknown post-processor '%s': must be one of %s", name, strings.Join(ProcessorNames(), ", "))
+	}
+	return nil
+}
+
+// orderedProcessors returns the processors named in order first, in that order, followed by the
+// others in their default order
+func orderedProcessors(order []string) []Processor {
+	var ordered []Processor
+	listed := make(map[string]bool)
+	for _, name := range order {
+		if processor, exists := LookupProcessor(name); exists && !listed[name] {
+			ordered = append(ordered, processor)
+			listed[name] = true
+		}
+	}
+	for _, processor := range processors {
+		if !listed[processor.Name] {
+			ordered = append(ordered, processor)
+		}
+	}
+	return ordered
+}
+
+// Run runs the enabled post-processors in the order of p.Config.PostProcessors, numbering their
+// steps from step. It returns the next step number.
+func (p *Pipeline) Run(ctx context.Context, step int) (int, error) {
+	for _, processor := range orderedProcessors(p.Config.PostProcessors) {
+		if !processor.Enabled(p) {
+			continue
+		}
+		fmt.Println()
+		format.PrintStep(step, processor.Title(p.Config))
+		step++
+		if err := processor.Run(ctx, p); err != nil {
+			format.PrintError(err.Error())
+			return step, err
+		}
+	}
+	return step, nil
+}
+
+// translator is created on first use and shared by every file in a run so its rate limit spans the batch
+var translator *translate.Translator
+
+// stepTitle returns a Title function for a fixed step message
+func stepTitle(title string) func(model.OutputConfig) string {
+	return func(model.OutputConfig) string {
+		return title
+	}
+}
+
+// The default order runs cleanup before tracks are split, translated or merged, syncs before the
+// timing is split up by chapters, and converts to ASS after translation, which only reads SRT.
+func init() {
+	Register(Processor{
+		Name:    "strip-ass",
+		Title:   stepTitle("Stripping ASS styling..."),
+		Enabled: func(p *Pipeline) bool { return p.Config.StripASS != "" },
+		Run: func(ctx context.Context, p *Pipeline) error {
+			return StripASSStyling(p.Jobs, p.Config.StripASS)
+		},
+	})
+	Register(Processor{
+		Name:    "cleanup",
+		Title:   stepTitle("Removing advertising and credit cues..."),
+		Enabled: func(p *Pipeline) bool { return len(p.Config.CleanupRules) > 0 },
+		Run: func(ctx context.Context, p *Pipeline) error {
+			return CleanupCues(p.Jobs, p.Config.CleanupRules)
+		},
+	})
+	Register(Processor{
+		Name:    "verify-language",
+		Title:   stepTitle("Verifying subtitle languages..."),
+		Enabled: func(p *Pipeline) bool { return p.Config.VerifyLanguage },
+		Run:     verifyLanguages,
+	})
+	Register(Processor{
+		Name:    "strip-hi",
+		Title:   stepTitle("Removing hearing-impaired annotations..."),
+		Enabled: func(p *Pipeline) bool { return p.Config.StripHI != "" },
+		Run: func(ctx context.Context, p *Pipeline) error {
+			jobs, err := StripHearingImpaired(p.Jobs, p.Config.StripHI)
+			if err != nil {
+				return err
+			}
+			p.Jobs = jobs
+			return nil
+		},
+	})
+	Register(Processor{
+		Name:    "sync",
+		Title:   stepTitle("Synchronizing subtitles to the audio..."),
+		Enabled: func(p *Pipeline) bool { return p.Config.SyncToAudio },
+		Run: func(ctx context.Context, p *Pipeline) error {
+			return SyncToAudio(ctx, p.InputFileName, p.MKVInfo, p.Jobs)
+		},
+	})
+	Register(Processor{
+		Name:    "split-chapters",
+		Title:   stepTitle("Splitting subtitle tracks by chapters..."),
+		Enabled: func(p *Pipeline) bool { return p.Config.SplitByChapters },
+		Run:     splitByChapters,
+	})
+	Register(Processor{
+		Name:    "fonts",
+		Title:   stepTitle("Extracting font attachments..."),
+		Enabled: func(p *Pipeline) bool { return len(fontDirectories(p)) > 0 },
+		Run:     extractFonts,
+	})
+	Register(Processor{
+		Name: "translate",
+		Title: func(config model.OutputConfig) string {
+			return fmt.Sprintf("Translating subtitle tracks to %s...", config.TranslateTo)
+		},
+		Enabled: func(p *Pipeline) bool { return p.Config.TranslateTo != "" },
+		Run: func(ctx context.Context, p *Pipeline) error {
+			if translator == nil {
+				var err error
+				if translator, err = translate.NewTranslator(p.Config.Translation); err != nil {
+					return err
+				}
+			}
+			// The translated files go through the later processors and count as outputs of the file
+			translatedJobs, err := translator.TranslateExtractedTracks(ctx, p.InputFileName, p.Jobs, p.Config)
+			p.Jobs = append(p.Jobs, translatedJobs...)
+			return err
+		},
+	})
+	Register(Processor{
+		Name:    "convert",
+		Title:   stepTitle("Converting SRT tracks to ASS..."),
+		Enabled: func(p *Pipeline) bool { return p.Config.ConvertTo == ConvertASS },
+		Run: func(ctx context.Context, p *Pipeline) error {
+			return ConvertSRTToASS(p.Jobs, p.Config.StyleTemplate)
+		},
+	})
+	Register(Processor{
+		Name: "merge",
+		Title: func(config model.OutputConfig) string {
+			return fmt.Sprintf("Merging %s into a bilingual subtitle...", config.MergeLanguages)
+		},
+		Enabled: func(p *Pipeline) bool { return p.Config.MergeLanguages != "" },
+		Run: func(ctx context.Context, p *Pipeline) error {
+			return MergeLanguages(p.InputFileName, p.Jobs, p.Config)
+		},
+	})
+	Register(Processor{
+		Name:    "line-endings",
+		Title:   stepTitle("Normalizing line endings..."),
+		Enabled: func(p *Pipeline) bool { return p.Config.LineEndings != "" || p.Config.BOM },
+		Run: func(ctx context.Context, p *Pipeline) error {
+			return NormalizeTextFiles(p.Jobs)
+		},
+	})
+}
+
+// verifyLanguages checks the language tags against the text and, with --fix-language-tags, writes
+// the languages detected for und tracks back into the source file
+func verifyLanguages(ctx context.Context, p *Pipeline) error {
+	detectedTracks, err := VerifyLanguages(p.InputFileName, p.Jobs, p.Config)
+	if err != nil {
+		return err
+	}
+	if !p.Config.FixLanguages || len(detectedTracks) == 0 {
+		return nil
+	}
+
+	if p.Config.SourceURL != "" {
+		format.PrintWarning("Language tags of remote files cannot be fixed")
+		return nil
+	}
+	if err := mkv.SetTrackLanguages(ctx, p.InputFileName, detectedTracks); err != nil {
+		return err
+	}
+	format.PrintSuccess(fmt.Sprintf("Tagged %d track(s) of %s with the detected language", len(detectedTracks), filepath.Base(p.InputFileName)))
+	return nil
+}
+
+// splitByChapters splits text tracks into one file per chapter, e.g. the episodes of a multi-episode MKV
+func splitByChapters(ctx context.Context, p *Pipeline) error {
+	if len(p.MKVInfo.Chapters) == 0 {
+		format.PrintWarning("The file has no chapters, subtitles are kept whole")
+		return nil
+	}
+
+	chapters, err := mkv.ReadChapters(ctx, p.InputFileName)
+	if err != nil {
+		return err
+	}
+	jobs, err := SplitByChapters(p.InputFileName, p.Jobs, chapters, p.Config)
+	if err != nil {
+		return err
+	}
+	p.Jobs = jobs
+	return nil
+}
+
+// fontDirectories returns the fonts/ directories beside the extracted ASS/SSA tracks, when the
+// source has fonts to put there (--all-tracks already wrote every attachment)
+func fontDirectories(p *Pipeline) []string {
+	if p.Config.NoFonts || p.Config.AllTracks || !HasFontAttachments(p.MKVInfo) {
+		return nil
+	}
+
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, job := range p.Jobs {
+		ext := strings.ToLower(filepath.Ext(job.OutFileName))
+		if ext != ".ass" && ext != ".ssa" {
+			continue
+		}
+		dir := filepath.Join(filepath.Dir(job.OutFileName), "fonts")
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// extractFonts extracts the font attachments so the ASS/SSA tracks can be rendered as intended
+func extractFonts(ctx context.Context, p *Pipeline) error {
+	for _, fontDir := range fontDirectories(p) {
+		fontCount, err := mkv.ExtractFontAttachments(ctx, p.InputFileName, p.MKVInfo.Attachments, fontDir)
+		if err != nil {
+			return err
+		}
+		if fontCount > 0 {
+			format.PrintSuccess(fmt.Sprintf("Extracted %d font(s) to %s", fontCount, fontDir))
+		} else {
+			format.PrintInfo(fmt.Sprintf("Fonts already present in %s", fontDir))
+		}
+	}
+	return nil
+}
+
+// HasFontAttachments reports whether the MKV file carries any font attachments
+func HasFontAttachments(mkvInfo *model.MKVInfo) bool {
+	for _, attachment := range mkvInfo.Attachments {
+		if attachment.IsFont() {
+			return true
+		}
+	}
+	return false
+}
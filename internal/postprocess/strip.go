@@ -0,0 +1,110 @@
+package postprocess
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/subtitle"
+)
+
+// Output modes for StripASSStyling
+const (
+	StripASSKeepASS = "ass" // Rewrite the ASS file with dialogue only
+	StripASSToSRT   = "srt" // Replace the ASS file with a plain SRT file
+)
+
+// typesettingTagPattern matches override tags used for signs, drawings and other typesetting
+var typesettingTagPattern = regexp.MustCompile(`\\(pos|move|org|i?clip)\(|\\p[1-9]`)
+
+// StripASSStyling reduces every extracted ASS/SSA file to its dialogue text.
+// Comments, karaoke and typesetting events are dropped and override tags are removed.
+// In StripASSToSRT mode the file is replaced by an SRT file and the job's output name is updated.
+func StripASSStyling(jobs []model.ExtractionJob, mode string) error {
+	strippedCount := 0
+	for i := range jobs {
+		job := &jobs[i]
+		ext := strings.ToLower(filepath.Ext(job.OutFileName))
+		if ext != ".ass" && ext != ".ssa" {
+			continue
+		}
+
+		doc, err := subtitle.ReadASSFile(job.OutFileName)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", filepath.Base(job.OutFileName), err)
+		}
+
+		removed := StripASSDocument(doc)
+
+		if mode == StripASSToSRT {
+			cues := doc.Cues()
+			sort.SliceStable(cues, func(a, b int) bool {
+				return cues[a].Start < cues[b].Start
+			})
+
+			srtFileName := strings.TrimSuffix(job.OutFileName, filepath.Ext(job.OutFileName)) + ".srt"
+			if err := subtitle.WriteSRTFile(srtFileName, cues); err != nil {
+				return fmt.Errorf("failed to write %s: %v", filepath.Base(srtFileName), err)
+			}
+			if err := os.Remove(job.OutFileName); err != nil {
+				format.PrintWarning(fmt.Sprintf("Could not remove %s: %v", filepath.Base(job.OutFileName), err))
+			}
+			job.OutFileName = srtFileName
+		} else if err := subtitle.WriteASSFile(job.OutFileName, doc); err != nil {
+			return fmt.Errorf("failed to write %s: %v", filepath.Base(job.OutFileName), err)
+		}
+
+		format.SuccessColor.Print("  ✓ ")
+		format.BaseFg.Println(fmt.Sprintf("Track %d: kept %d dialogue line(s), removed %d event(s)",
+			job.OriginalTrack.Properties.Number, len(doc.Events), removed))
+		format.PrintExample(fmt.Sprintf("    → %s", job.OutFileName))
+		strippedCount++
+	}
+
+	if strippedCount == 0 {
+		format.PrintWarning("No ASS/SSA tracks to strip")
+	}
+	return nil
+}
+
+// StripASSDocument removes non-dialogue events and override tags in place, returning the number of dropped events
+func StripASSDocument(doc *subtitle.ASSDocument) int {
+	var kept []subtitle.ASSEvent
+	for _, event := range doc.Events {
+		if !isDialogueEvent(doc, event) {
+			continue
+		}
+
+		text := subtitle.PlainASSText(doc.Field(event, "Text"))
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		doc.SetField(&event, "Text", subtitle.ASSText(text))
+		doc.SetField(&event, "Effect", "")
+		kept = append(kept, event)
+	}
+
+	removed := len(doc.Events) - len(kept)
+	doc.Events = kept
+	return removed
+}
+
+// isDialogueEvent applies heuristics to tell spoken dialogue apart from typesetting
+func isDialogueEvent(doc *subtitle.ASSDocument, event subtitle.ASSEvent) bool {
+	if event.Kind != "Dialogue" {
+		return false
+	}
+	if strings.TrimSpace(doc.Field(event, "Effect")) != "" {
+		return false
+	}
+	if typesettingTagPattern.MatchString(doc.Field(event, "Text")) {
+		return false
+	}
+
+	return !model.HasTypesettingWord(doc.Field(event, "Style"))
+}
@@ -0,0 +1,138 @@
+package postprocess
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"subscalpelmkv/internal/audiosync"
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/subtitle"
+)
+
+// SyncToAudio shifts and stretches every extracted SRT and ASS/SSA track to line its cues up with
+// the speech of the file's default audio track (or its first audio track), decoded with ffmpeg
+func SyncToAudio(ctx context.Context, inputFileName string, mkvInfo *model.MKVInfo, jobs []model.ExtractionJob) error {
+	audioTrack, found := syncAudioTrack(mkvInfo)
+	if !found {
+		format.PrintWarning("The file has no audio track, subtitles are left as they are")
+		return nil
+	}
+
+	var textJobs []model.ExtractionJob
+	for _, job := range jobs {
+		switch strings.ToLower(filepath.Ext(job.OutFileName)) {
+		case ".srt", ".ass", ".ssa":
+			textJobs = append(textJobs, job)
+		}
+	}
+	if len(textJobs) == 0 {
+		format.PrintWarning("No text subtitle tracks to synchronize")
+		return nil
+	}
+
+	format.PrintInfo(fmt.Sprintf("Detecting speech in audio track %d (%s)...", audioTrack.Properties.Number, audioTrack.Properties.Language))
+	speech, err := audiosync.SpeechActivity(ctx, inputFileName, audioTrack.Id)
+	if err != nil {
+		return fmt.Errorf("failed to analyze audio track %d: %v", audioTrack.Properties.Number, err)
+	}
+
+	for _, job := range textJobs {
+		result, aligned, err := syncFile(job.OutFileName, speech)
+		if err != nil {
+			return err
+		}
+		if !aligned {
+			format.PrintWarning(fmt.Sprintf("Track %d has no dialogue to align, left as it is", job.OriginalTrack.Properties.Number))
+			continue
+		}
+
+		format.SuccessColor.Print("  ✓ ")
+		format.BaseFg.Println(fmt.Sprintf("Track %d: shifted %+.2fs, speed %.4f, %.0f%% agreement with speech",
+			job.OriginalTrack.Properties.Number, result.Offset.Seconds(), result.Scale, result.Agreement*100))
+		format.PrintExample(fmt.Sprintf("    → %s", job.OutFileName))
+	}
+	return nil
+}
+
+// syncFile retimes one subtitle file in place. It reports false, leaving the file alone, when the
+// file has no dialogue to align.
+func syncFile(path string, speech []float64) (audiosync.Result, bool, error) {
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".ass" || ext == ".ssa" {
+		doc, err := subtitle.ReadASSFile(path)
+		if err != nil {
+			return audiosync.Result{}, false, fmt.Errorf("failed to read %s: %v", filepath.Base(path), err)
+		}
+
+		// Align on the dialogue only; signs and songs do not follow speech
+		dialogue := *doc
+		dialogue.Events = make([]subtitle.ASSEvent, len(doc.Events))
+		for i, event := range doc.Events {
+			dialogue.Events[i] = subtitle.ASSEvent{Kind: event.Kind, Fields: append([]string(nil), event.Fields...)}
+		}
+		StripASSDocument(&dialogue)
+		cues := dialogue.Cues()
+		if len(cues) == 0 {
+			return audiosync.Result{}, false, nil
+		}
+		result := audiosync.Align(speech, cues)
+
+		var kept []subtitle.ASSEvent
+		for _, event := range doc.Events {
+			start, startErr := subtitle.ParseASSTimestamp(doc.Field(event, "Start"))
+			end, endErr := subtitle.ParseASSTimestamp(doc.Field(event, "End"))
+			if startErr != nil || endErr != nil {
+				kept = append(kept, event)
+				continue
+			}
+			retimed := audiosync.Apply([]subtitle.Cue{{Start: start, End: end}}, result)
+			if len(retimed) == 0 {
+				continue
+			}
+			event.Fields = append([]string(nil), event.Fields...)
+			doc.SetField(&event, "Start", subtitle.FormatASSTimestamp(retimed[0].Start))
+			doc.SetField(&event, "End", subtitle.FormatASSTimestamp(retimed[0].End))
+			kept = append(kept, event)
+		}
+		doc.Events = kept
+
+		if err := subtitle.WriteASSFile(path, doc); err != nil {
+			return result, false, fmt.Errorf("failed to write %s: %v", filepath.Base(path), err)
+		}
+		return result, true, nil
+	}
+
+	cues, err := subtitle.ReadSRTFile(path)
+	if err != nil {
+		return audiosync.Result{}, false, fmt.Errorf("failed to read %s: %v", filepath.Base(path), err)
+	}
+	if len(cues) == 0 {
+		return audiosync.Result{}, false, nil
+	}
+	result := audiosync.Align(speech, cues)
+	if err := subtitle.WriteSRTFile(path, audiosync.Apply(cues, result)); err != nil {
+		return result, false, fmt.Errorf("failed to write %s: %v", filepath.Base(path), err)
+	}
+	return result, true, nil
+}
+
+// syncAudioTrack picks the default audio track, or the first audio track if none is flagged
+func syncAudioTrack(mkvInfo *model.MKVInfo) (model.MKVTrack, bool) {
+	var audioTracks []model.MKVTrack
+	for _, track := range mkvInfo.Tracks {
+		if track.Type == "audio" {
+			audioTracks = append(audioTracks, track)
+		}
+	}
+	if len(audioTracks) == 0 {
+		return model.MKVTrack{}, false
+	}
+
+	sort.SliceStable(audioTracks, func(i, j int) bool {
+		return audioTracks[i].Properties.Default && !audioTracks[j].Properties.Default
+	})
+	return audioTracks[0], true
+}
@@ -3,12 +3,12 @@ package progress
 import (
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/i18n"
 )
 
 var (
@@ -16,6 +16,7 @@ var (
 	startTime   time.Time
 	once        sync.Once
 	barWidth    = 60
+	totalBytes  int64
 	mu          sync.Mutex
 )
 
@@ -86,10 +87,27 @@ func formatDuration(d time.Duration) string {
 	}
 }
 
+// formatBytes formats a byte count with a decimal unit, e.g. "850 kB" or "1.2 GB"
+func formatBytes(bytes float64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	i := 0
+	for bytes >= 1000 && i < len(units)-1 {
+		bytes /= 1000
+		i++
+	}
+	if i == 0 || bytes >= 100 {
+		return fmt.Sprintf("%.0f %s", bytes, units[i])
+	}
+	return fmt.Sprintf("%.1f %s", bytes, units[i])
+}
+
 // renderProgressBar renders the progress bar to stdout with modern styling
 func renderProgressBar(percentage int) {
-	// Adjust bar width for modern style
+	// Adjust bar width for modern style, leaving room for the throughput when it is known
 	actualBarWidth := 35
+	if totalBytes > 0 {
+		actualBarWidth = 25
+	}
 	filledWidth := int(float64(actualBarWidth) * float64(percentage) / 100.0)
 	emptyWidth := actualBarWidth - filledWidth
 
@@ -99,7 +117,7 @@ func renderProgressBar(percentage int) {
 	// Start with indentation to match other lines
 	progressLine.WriteString("  ")
 	progressLine.WriteString(format.InfoColor.Sprint("►"))
-	progressLine.WriteString(" Processing: ")
+	progressLine.WriteString(" " + i18n.T("Processing:") + " ")
 	
 	// Progress bar
 	progressLine.WriteString(format.ProgressBg.Sprint("["))
@@ -123,6 +141,15 @@ func renderProgressBar(percentage int) {
 	elapsed := time.Since(startTime)
 	elapsedStr := formatDuration(elapsed)
 	progressLine.WriteString(format.BaseDim.Sprintf(" • %s", elapsedStr))
+
+	// Bytes read so far and throughput, estimated from the size of the input and the percentage
+	if totalBytes > 0 {
+		processed := float64(totalBytes) * float64(percentage) / 100.0
+		progressLine.WriteString(format.BaseDim.Sprintf(" • %s", formatBytes(processed)))
+		if elapsed >= 500*time.Millisecond {
+			progressLine.WriteString(format.BaseDim.Sprintf(" • %s/s", formatBytes(processed/elapsed.Seconds())))
+		}
+	}
 	
 	// Print with carriage return to overwrite and clear to end of line
 	fmt.Print("\r" + progressLine.String() + "\033[K")
@@ -139,17 +166,14 @@ func ResetProgressBar() {
 	once = sync.Once{}
 	lastPercent = 0
 	startTime = time.Time{}
+	totalBytes = 0
 }
 
-// ParseProgressLine extracts percentage from mkvmerge progress output
-func ParseProgressLine(line string) (int, bool) {
-	// In GUI mode, progress lines look like: "#GUI#progress 45%"
-	if strings.HasPrefix(line, "#GUI#progress ") && strings.HasSuffix(line, "%") {
-		percentStr := strings.TrimPrefix(line, "#GUI#progress ")
-		percentStr = strings.TrimSuffix(percentStr, "%")
-		if percentage, err := strconv.Atoi(strings.TrimSpace(percentStr)); err == nil {
-			return percentage, true
-		}
-	}
-	return 0, false
+// SetTotalBytes sets the size of the input the running operation reads, so the progress bar can show
+// the bytes processed and the throughput. Zero hides them.
+func SetTotalBytes(bytes int64) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	totalBytes = bytes
 }
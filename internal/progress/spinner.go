@@ -0,0 +1,129 @@
+package progress
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+
+	"subscalpelmkv/internal/format"
+)
+
+// spinnerDelay keeps quick operations from flashing a spinner
+const spinnerDelay = 300 * time.Millisecond
+
+var (
+	spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	activeSpinner *Spinner
+	spinnerMu     sync.Mutex
+)
+
+// Spinner shows an indeterminate activity indicator with elapsed time on a single line.
+// All methods are safe on a nil Spinner, which shows nothing.
+type Spinner struct {
+	mu      sync.Mutex
+	label   string
+	start   time.Time
+	shown   bool
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// StartSpinner shows a spinner with the given label until Stop is called. It returns nil when
+// stdout is not a terminal or another spinner is already running, so an outer spinner keeps the line.
+func StartSpinner(label string) *Spinner {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil
+	}
+
+	spinnerMu.Lock()
+	defer spinnerMu.Unlock()
+	if activeSpinner != nil {
+		return nil
+	}
+
+	s := &Spinner{
+		label:   label,
+		start:   time.Now(),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	activeSpinner = s
+	go s.run()
+	return s
+}
+
+// SetLabel changes the text shown next to the spinner
+func (s *Spinner) SetLabel(label string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.label = label
+	s.mu.Unlock()
+}
+
+// Stop removes the spinner line and waits for the spinner to finish
+func (s *Spinner) Stop() {
+	if s == nil {
+		return
+	}
+
+	spinnerMu.Lock()
+	if activeSpinner != s {
+		spinnerMu.Unlock()
+		return
+	}
+	activeSpinner = nil
+	spinnerMu.Unlock()
+
+	close(s.stop)
+	<-s.stopped
+}
+
+// run draws a frame every 100ms after spinnerDelay and clears the line when stopped
+func (s *Spinner) run() {
+	defer close(s.stopped)
+
+	select {
+	case <-time.After(spinnerDelay):
+	case <-s.stop:
+		return
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for frame := 0; ; frame++ {
+		s.render(spinnerFrames[frame%len(spinnerFrames)])
+		select {
+		case <-ticker.C:
+		case <-s.stop:
+			if s.shown {
+				fmt.Print("\r\033[K\033[?25h")
+			}
+			return
+		}
+	}
+}
+
+// render prints one spinner frame over the current line
+func (s *Spinner) render(frame string) {
+	s.mu.Lock()
+	label := s.label
+	s.mu.Unlock()
+
+	if !s.shown {
+		fmt.Print("\033[?25l") // Hide the cursor while the spinner runs
+		s.shown = true
+	}
+	elapsed := "0s" // Whole seconds only; milliseconds flicker too fast to read
+	if since := time.Since(s.start); since >= time.Second {
+		elapsed = formatDuration(since)
+	}
+	line := fmt.Sprintf("  %s %s", format.InfoColor.Sprint(frame), label)
+	fmt.Print("\r" + line + format.BaseDim.Sprintf(" • %s", elapsed) + "\033[K")
+	os.Stdout.Sync()
+}
@@ -0,0 +1,159 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"subscalpelmkv/internal/mkv"
+	"subscalpelmkv/internal/model"
+)
+
+// Output formats of the statistics report
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+)
+
+// Count is how many subtitle tracks of a language or format a library has, and in how many files
+type Count struct {
+	Name   string `json:"name"` // Language code or subtitle format
+	Tracks int    `json:"tracks"`
+	Files  int    `json:"files"`
+}
+
+// FileError names a file that could not be analyzed
+type FileError struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// Report summarizes the subtitle coverage of a library
+type Report struct {
+	Files            int         `json:"files"`             // Files scanned
+	Analyzed         int         `json:"analyzed"`          // Files whose tracks could be read
+	Tracks           int         `json:"tracks"`            // Subtitle tracks over all files
+	Languages        []Count     `json:"languages"`         // By files, then tracks, most first
+	Formats          []Count     `json:"formats"`           // By tracks, most first
+	WithoutSubtitles []string    `json:"without_subtitles"` // Files without any subtitle track
+	ImageOnly        []string    `json:"image_only"`        // Files whose subtitle tracks are all image-based (PGS, VobSub)
+	Errors           []FileError `json:"errors"`
+
+	languages map[string]*Count
+	formats   map[string]*Count
+}
+
+// NewReport creates an empty report
+func NewReport() *Report {
+	return &Report{
+		Languages:        []Count{},
+		Formats:          []Count{},
+		WithoutSubtitles: []string{},
+		ImageOnly:        []string{},
+		Errors:           []FileError{},
+		languages:        make(map[string]*Count),
+		formats:          make(map[string]*Count),
+	}
+}
+
+// AnalyzeFile reads the subtitle tracks of an MKV file and adds them to the report
+func (r *Report) AnalyzeFile(ctx context.Context, inputFileName string) {
+	r.Files++
+	mkvInfo, err := mkv.GetTrackInfo(ctx, inputFileName)
+	if err != nil {
+		r.Errors = append(r.Errors, FileError{File: inputFileName, Error: err.Error()})
+		return
+	}
+	r.Analyzed++
+
+	var subtitles []model.MKVTrack
+	for _, track := range mkvInfo.Tracks {
+		if track.Type == "subtitles" {
+			subtitles = append(subtitles, track)
+		}
+	}
+	if len(subtitles) == 0 {
+		r.WithoutSubtitles = append(r.WithoutSubtitles, inputFileName)
+		return
+	}
+
+	// Files count once per language and format, however many tracks they have of it
+	seenLanguages := make(map[string]bool)
+	seenFormats := make(map[string]bool)
+	imageOnly := true
+	for _, track := range subtitles {
+		r.Tracks++
+		language := track.Properties.Language
+		if language == "" {
+			language = "und"
+		}
+		addTrack(r.languages, language, seenLanguages)
+
+		trackFormat := model.TrackExtension(track.Properties.CodecId)
+		if trackFormat == "" {
+			trackFormat = track.Properties.CodecId
+		}
+		addTrack(r.formats, trackFormat, seenFormats)
+
+		if !model.IsImageSubtitleCodec(track.Properties.CodecId) {
+			imageOnly = false
+		}
+	}
+	if imageOnly {
+		r.ImageOnly = append(r.ImageOnly, inputFileName)
+	}
+}
+
+// Finish sorts the language and format counts once every file was analyzed
+func (r *Report) Finish() {
+	r.Languages = sortedCounts(r.languages, func(a, b Count) bool {
+		if a.Files != b.Files {
+			return a.Files > b.Files
+		}
+		return a.Tracks > b.Tracks
+	})
+	r.Formats = sortedCounts(r.formats, func(a, b Count) bool {
+		return a.Tracks > b.Tracks
+	})
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// addTrack counts a track under name, and its file the first time the file has a track of it
+func addTrack(counts map[string]*Count, name string, seen map[string]bool) {
+	count, found := counts[name]
+	if !found {
+		count = &Count{Name: name}
+		counts[name] = count
+	}
+	count.Tracks++
+	if !seen[name] {
+		seen[name] = true
+		count.Files++
+	}
+}
+
+// sortedCounts lists counts in the given order, ties by name
+func sortedCounts(counts map[string]*Count, less func(a, b Count) bool) []Count {
+	sorted := make([]Count, 0, len(counts))
+	for _, count := range counts {
+		sorted = append(sorted, *count)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if less(sorted[i], sorted[j]) {
+			return true
+		}
+		if less(sorted[j], sorted[i]) {
+			return false
+		}
+		return strings.Compare(sorted[i].Name, sorted[j].Name) < 0
+	})
+	return sorted
+}
@@ -0,0 +1,278 @@
+package subtitle
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultASSEventFormat is the event format used by ASS (v4+) files
+var DefaultASSEventFormat = []string{"Layer", "Start", "End", "Style", "Name", "MarginL", "MarginR", "MarginV", "Effect", "Text"}
+
+// ASSEvent is a single line of the [Events] section (Dialogue or Comment)
+type ASSEvent struct {
+	Kind   string   // "Dialogue" or "Comment"
+	Fields []string // Values in the order of the document's event format
+}
+
+// ASSDocument holds an ASS/SSA script split around its [Events] section
+type ASSDocument struct {
+	Header  []string // Lines before the [Events] section ([Script Info], [V4+ Styles], ...)
+	Format  []string // Event field names from the Format line
+	Events  []ASSEvent
+	Trailer []string // Lines of sections following [Events] ([Fonts], [Graphics], ...)
+}
+
+// overrideTagPattern matches ASS override blocks such as {\an8\pos(10,10)}
+var overrideTagPattern = regexp.MustCompile(`\{[^}]*\}`)
+
+// ParseASS parses an ASS or SSA script
+func ParseASS(r io.Reader) (*ASSDocument, error) {
+	scanner := bufio.NewScanner(r)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 16*1024*1024)
+
+	doc := &ASSDocument{}
+	const (
+		beforeEvents = iota
+		inEvents
+		afterEvents
+	)
+	state := beforeEvents
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, utf8BOM)
+		}
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			if strings.EqualFold(trimmed, "[Events]") {
+				state = inEvents
+				continue
+			}
+			if state == inEvents {
+				state = afterEvents
+			}
+		}
+
+		switch state {
+		case beforeEvents:
+			doc.Header = append(doc.Header, line)
+		case afterEvents:
+			doc.Trailer = append(doc.Trailer, line)
+		case inEvents:
+			key, value, found := strings.Cut(trimmed, ":")
+			if !found {
+				continue
+			}
+			switch key {
+			case "Format":
+				doc.Format = splitFields(value, -1)
+			case "Dialogue", "Comment":
+				if doc.Format == nil {
+					doc.Format = DefaultASSEventFormat
+				}
+				fields := splitFields(value, len(doc.Format))
+				if len(fields) != len(doc.Format) {
+					return nil, fmt.Errorf("line %d: expected %d fields, found %d", lineNo, len(doc.Format), len(fields))
+				}
+				doc.Events = append(doc.Events, ASSEvent{Kind: key, Fields: fields})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if doc.Format == nil {
+		doc.Format = DefaultASSEventFormat
+	}
+	return doc, nil
+}
+
+// ReadASSFile parses an ASS/SSA file from disk
+func ReadASSFile(path string) (*ASSDocument, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseASS(file)
+}
+
+// WriteASS writes the document back in ASS syntax
+func WriteASS(w io.Writer, doc *ASSDocument) error {
+	bw := bufio.NewWriter(w)
+	for _, line := range doc.Header {
+		bw.WriteString(line + "\n")
+	}
+
+	bw.WriteString("[Events]\n")
+	bw.WriteString("Format: " + strings.Join(doc.Format, ", ") + "\n")
+	for _, event := range doc.Events {
+		bw.WriteString(event.Kind + ": " + strings.Join(event.Fields, ",") + "\n")
+	}
+
+	if len(doc.Trailer) > 0 {
+		bw.WriteString("\n")
+		for _, line := range doc.Trailer {
+			bw.WriteString(line + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteASSFile writes the document to a file on disk in the format set by SetTextFormat
+func WriteASSFile(path string, doc *ASSDocument) error {
+	var buf bytes.Buffer
+	if err := WriteASS(&buf, doc); err != nil {
+		return err
+	}
+	return writeTextFile(path, buf.Bytes())
+}
+
+// FieldIndex returns the position of a named event field, or -1 if the format lacks it
+func (doc *ASSDocument) FieldIndex(name string) int {
+	for i, field := range doc.Format {
+		if strings.EqualFold(field, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Field returns the value of a named field of an event
+func (doc *ASSDocument) Field(event ASSEvent, name string) string {
+	if i := doc.FieldIndex(name); i >= 0 && i < len(event.Fields) {
+		return event.Fields[i]
+	}
+	return ""
+}
+
+// SetField updates the value of a named field of an event
+func (doc *ASSDocument) SetField(event *ASSEvent, name, value string) {
+	if i := doc.FieldIndex(name); i >= 0 && i < len(event.Fields) {
+		event.Fields[i] = value
+	}
+}
+
+// Cues converts the dialogue events to plain-text cues, dropping override tags
+func (doc *ASSDocument) Cues() []Cue {
+	var cues []Cue
+	for _, event := range doc.Events {
+		if event.Kind != "Dialogue" {
+			continue
+		}
+
+		start, err := ParseASSTimestamp(doc.Field(event, "Start"))
+		if err != nil {
+			continue
+		}
+		end, err := ParseASSTimestamp(doc.Field(event, "End"))
+		if err != nil {
+			continue
+		}
+
+		text := PlainASSText(doc.Field(event, "Text"))
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		cues = append(cues, Cue{Index: len(cues) + 1, Start: start, End: end, Text: text})
+	}
+	return cues
+}
+
+// PlainASSText strips override tags from ASS event text and converts ASS line breaks
+func PlainASSText(text string) string {
+	text = overrideTagPattern.ReplaceAllString(text, "")
+	text = strings.ReplaceAll(text, `\N`, "\n")
+	text = strings.ReplaceAll(text, `\n`, "\n")
+	text = strings.ReplaceAll(text, `\h`, " ")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ASSText converts plain cue text to ASS event text
+func ASSText(text string) string {
+	return strings.ReplaceAll(text, "\n", `\N`)
+}
+
+// FormatASSTimestamp formats a duration as an ASS timestamp (H:MM:SS.cc)
+func FormatASSTimestamp(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	cs := d.Milliseconds() / 10
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, (cs/6000)%60, (cs/100)%60, cs%100)
+}
+
+// ParseASSTimestamp parses an ASS timestamp (H:MM:SS.cc)
+func ParseASSTimestamp(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	clock, fraction, _ := strings.Cut(value, ".")
+
+	parts := strings.Split(clock, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid timestamp '%s'", value)
+	}
+
+	var fields [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp '%s'", value)
+		}
+		fields[i] = n
+	}
+
+	// The fraction is usually centiseconds, but be lenient about its precision
+	var fractionDuration time.Duration
+	if fraction != "" {
+		n, err := strconv.Atoi(fraction)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp '%s'", value)
+		}
+		fractionDuration = time.Duration(n) * time.Second
+		for range fraction {
+			fractionDuration /= 10
+		}
+	}
+
+	return time.Duration(fields[0])*time.Hour +
+		time.Duration(fields[1])*time.Minute +
+		time.Duration(fields[2])*time.Second +
+		fractionDuration, nil
+}
+
+// splitFields splits a comma-separated event line; when limit is positive the last field keeps any remaining commas
+func splitFields(value string, limit int) []string {
+	var parts []string
+	if limit > 0 {
+		parts = strings.SplitN(value, ",", limit)
+	} else {
+		parts = strings.Split(value, ",")
+	}
+	for i, part := range parts {
+		if limit <= 0 || i < len(parts)-1 {
+			parts[i] = strings.TrimSpace(part)
+		} else {
+			parts[i] = strings.TrimLeft(part, " ")
+		}
+	}
+	return parts
+}
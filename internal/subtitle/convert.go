@@ -0,0 +1,100 @@
+package subtitle
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultASSHeader is the script header of SRT tracks converted without a style template
+var defaultASSHeader = []string{
+	"[Script Info]",
+	"ScriptType: v4.00+",
+	"WrapStyle: 0",
+	"ScaledBorderAndShadow: yes",
+	"PlayResX: 1920",
+	"PlayResY: 1080",
+	"",
+	"[V4+ Styles]",
+	"Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding",
+	"Style: Default,Arial,64,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,1,2,60,60,50,1",
+	"",
+}
+
+var (
+	// srtStyleTagPattern matches the SRT tags with an ASS equivalent: <i>, </b>, <u>, ...
+	srtStyleTagPattern = regexp.MustCompile(`(?i)<(/?)([ibus])>`)
+	// srtFontColorPattern matches <font color="#RRGGBB">
+	srtFontColorPattern = regexp.MustCompile(`(?i)<font[^>]*color="?#([0-9a-f]{6})"?[^>]*>`)
+	// srtTagPattern matches any remaining SRT tag
+	srtTagPattern = regexp.MustCompile(`<[^>]+>`)
+)
+
+// StyleTemplate returns the header of an ASS script and the style its converted events use: the
+// style named Default, or else the first style of the script
+func StyleTemplate(path string) ([]string, string, error) {
+	doc, err := ReadASSFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var styles []string
+	for _, line := range doc.Header {
+		if key, value, found := strings.Cut(strings.TrimSpace(line), ":"); found && key == "Style" {
+			name, _, _ := strings.Cut(value, ",")
+			styles = append(styles, strings.TrimSpace(name))
+		}
+	}
+	if len(styles) == 0 {
+		return nil, "", fmt.Errorf("%s defines no styles", path)
+	}
+
+	style := styles[0]
+	for _, name := range styles {
+		if strings.EqualFold(name, "Default") {
+			style = name
+			break
+		}
+	}
+	return doc.Header, style, nil
+}
+
+// CuesToASS builds an ASS script from SRT cues, translating the SRT formatting tags to override tags.
+// Without a header the script gets a plain Default style.
+func CuesToASS(cues []Cue, header []string, style string) *ASSDocument {
+	if header == nil {
+		header, style = defaultASSHeader, "Default"
+	}
+
+	doc := &ASSDocument{
+		Header: append([]string(nil), header...),
+		Format: DefaultASSEventFormat,
+	}
+	for _, cue := range cues {
+		doc.Events = append(doc.Events, ASSEvent{
+			Kind:   "Dialogue",
+			Fields: []string{"0", FormatASSTimestamp(cue.Start), FormatASSTimestamp(cue.End), style, "", "0", "0", "0", "", ASSText(srtTagsToASS(cue.Text))},
+		})
+	}
+	return doc
+}
+
+// srtTagsToASS converts <i>, <b>, <u>, <s> and font colors to ASS override tags and drops other tags
+func srtTagsToASS(text string) string {
+	// Braces would open override blocks in ASS
+	text = strings.ReplaceAll(text, "{", "(")
+	text = strings.ReplaceAll(text, "}", ")")
+	text = srtStyleTagPattern.ReplaceAllStringFunc(text, func(tag string) string {
+		match := srtStyleTagPattern.FindStringSubmatch(tag)
+		if match[1] == "/" {
+			return `{\` + strings.ToLower(match[2]) + `0}`
+		}
+		return `{\` + strings.ToLower(match[2]) + `1}`
+	})
+	text = srtFontColorPattern.ReplaceAllStringFunc(text, func(tag string) string {
+		rgb := strings.ToUpper(srtFontColorPattern.FindStringSubmatch(tag)[1])
+		return `{\c&H` + rgb[4:6] + rgb[2:4] + rgb[0:2] + `&}`
+	})
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "</font>", `{\c}`), "</FONT>", `{\c}`)
+	return srtTagPattern.ReplaceAllString(text, "")
+}
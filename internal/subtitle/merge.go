@@ -0,0 +1,95 @@
+package subtitle
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ReadCues reads the cues of a text subtitle file, choosing the parser by extension
+func ReadCues(path string) ([]Cue, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".srt":
+		return ReadSRTFile(path)
+	case ".ass", ".ssa":
+		doc, err := ReadASSFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return doc.Cues(), nil
+	default:
+		return nil, fmt.Errorf("unsupported subtitle format: %s", filepath.Ext(path))
+	}
+}
+
+// MergeBilingual zips two cue lists into one, placing overlapping secondary text on the line below the primary text.
+// Secondary cues that do not overlap any primary cue are kept as cues of their own.
+func MergeBilingual(primary, secondary []Cue) []Cue {
+	merged := make([]Cue, len(primary))
+	copy(merged, primary)
+
+	used := make([]bool, len(secondary))
+	for i := range merged {
+		var extra []string
+		for j, cue := range secondary {
+			if overlaps(merged[i], cue) {
+				extra = append(extra, cue.Text)
+				used[j] = true
+			}
+		}
+		if len(extra) > 0 {
+			merged[i].Text = merged[i].Text + "\n" + strings.Join(extra, "\n")
+		}
+	}
+
+	for j, cue := range secondary {
+		if !used[j] {
+			merged = append(merged, cue)
+		}
+	}
+
+	sort.SliceStable(merged, func(a, b int) bool {
+		return merged[a].Start < merged[b].Start
+	})
+	return merged
+}
+
+// BilingualASS builds an ASS script showing the primary cues at the bottom and the secondary cues at the top
+func BilingualASS(primary, secondary []Cue) *ASSDocument {
+	doc := &ASSDocument{
+		Header: []string{
+			"[Script Info]",
+			"ScriptType: v4.00+",
+			"WrapStyle: 0",
+			"ScaledBorderAndShadow: yes",
+			"PlayResX: 1920",
+			"PlayResY: 1080",
+			"",
+			"[V4+ Styles]",
+			"Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding",
+			"Style: Primary,Arial,64,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,1,2,60,60,50,1",
+			"Style: Secondary,Arial,52,&H00E0E0E0,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,1,8,60,60,50,1",
+			"",
+		},
+		Format: DefaultASSEventFormat,
+	}
+
+	addEvents := func(cues []Cue, style string) {
+		for _, cue := range cues {
+			doc.Events = append(doc.Events, ASSEvent{
+				Kind:   "Dialogue",
+				Fields: []string{"0", FormatASSTimestamp(cue.Start), FormatASSTimestamp(cue.End), style, "", "0", "0", "0", "", ASSText(cue.Text)},
+			})
+		}
+	}
+	addEvents(primary, "Primary")
+	addEvents(secondary, "Secondary")
+
+	return doc
+}
+
+// overlaps reports whether two cues share any screen time
+func overlaps(a, b Cue) bool {
+	return a.Start < b.End && b.Start < a.End
+}
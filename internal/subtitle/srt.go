@@ -0,0 +1,173 @@
+package subtitle
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cue represents a single timed subtitle entry
+type Cue struct {
+	Index int
+	Start time.Duration
+	End   time.Duration
+	Text  string // Cue text, lines separated by "\n"
+}
+
+// utf8BOM is the byte order mark some tools prepend to UTF-8 text files
+const utf8BOM = "\uFEFF"
+
+// ParseSRT parses SubRip content into cues
+func ParseSRT(r io.Reader) ([]Cue, error) {
+	scanner := bufio.NewScanner(r)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	var cues []Cue
+	var current *Cue
+	var textLines []string
+	lineNo := 0
+
+	flush := func() {
+		if current != nil {
+			current.Text = strings.Join(textLines, "\n")
+			cues = append(cues, *current)
+		}
+		current = nil
+		textLines = nil
+	}
+
+	var pendingIndex string
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, utf8BOM)
+		}
+
+		if current == nil {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" {
+				continue
+			}
+			if strings.Contains(trimmed, "-->") {
+				start, end, err := parseTimingLine(trimmed)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %v", lineNo, err)
+				}
+				index, _ := strconv.Atoi(pendingIndex)
+				current = &Cue{Index: index, Start: start, End: end}
+				pendingIndex = ""
+				continue
+			}
+			pendingIndex = trimmed
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		textLines = append(textLines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	return cues, nil
+}
+
+// ReadSRTFile parses an SRT file from disk
+func ReadSRTFile(path string) ([]Cue, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseSRT(file)
+}
+
+// WriteSRT writes cues in SubRip format, renumbering them sequentially
+func WriteSRT(w io.Writer, cues []Cue) error {
+	bw := bufio.NewWriter(w)
+	for i, cue := range cues {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		fmt.Fprintf(bw, "%d\n%s --> %s\n%s\n", i+1, FormatSRTTimestamp(cue.Start), FormatSRTTimestamp(cue.End), cue.Text)
+	}
+	return bw.Flush()
+}
+
+// WriteSRTFile writes cues to an SRT file on disk in the format set by SetTextFormat
+func WriteSRTFile(path string, cues []Cue) error {
+	var buf bytes.Buffer
+	if err := WriteSRT(&buf, cues); err != nil {
+		return err
+	}
+	return writeTextFile(path, buf.Bytes())
+}
+
+// FormatSRTTimestamp formats a duration as an SRT timestamp (HH:MM:SS,mmm)
+func FormatSRTTimestamp(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, (ms/60000)%60, (ms/1000)%60, ms%1000)
+}
+
+// ParseSRTTimestamp parses an SRT timestamp (HH:MM:SS,mmm); a dot separator is also accepted
+func ParseSRTTimestamp(value string) (time.Duration, error) {
+	value = strings.TrimSpace(strings.Replace(value, ".", ",", 1))
+
+	clock, millis, found := strings.Cut(value, ",")
+	if !found {
+		millis = "0"
+	}
+
+	parts := strings.Split(clock, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid timestamp '%s'", value)
+	}
+
+	var fields [4]int
+	for i, part := range append(parts, millis) {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp '%s'", value)
+		}
+		fields[i] = n
+	}
+
+	return time.Duration(fields[0])*time.Hour +
+		time.Duration(fields[1])*time.Minute +
+		time.Duration(fields[2])*time.Second +
+		time.Duration(fields[3])*time.Millisecond, nil
+}
+
+// parseTimingLine parses a "start --> end" line, ignoring trailing position hints
+func parseTimingLine(line string) (time.Duration, time.Duration, error) {
+	startStr, rest, _ := strings.Cut(line, "-->")
+	endFields := strings.Fields(rest)
+	if len(endFields) == 0 {
+		return 0, 0, fmt.Errorf("missing end timestamp in '%s'", line)
+	}
+
+	start, err := ParseSRTTimestamp(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := ParseSRTTimestamp(endFields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
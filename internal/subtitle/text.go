@@ -0,0 +1,52 @@
+package subtitle
+
+import (
+	"bytes"
+	"os"
+)
+
+// TextFormat controls the line endings and byte order mark of the subtitle files written by this package
+type TextFormat struct {
+	CRLF bool // End lines with CRLF instead of LF
+	BOM  bool // Start files with a UTF-8 byte order mark
+}
+
+// textFormat is the format used by WriteSRTFile, WriteASSFile and NormalizeTextFile
+var textFormat TextFormat
+
+// SetTextFormat sets the line endings and byte order mark of the files written from now on
+func SetTextFormat(format TextFormat) {
+	textFormat = format
+}
+
+// encodeText applies the text format to LF-terminated UTF-8 content
+func encodeText(data []byte) []byte {
+	data = bytes.TrimPrefix(data, []byte(utf8BOM))
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if textFormat.CRLF {
+		data = bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+	}
+	if textFormat.BOM {
+		data = append([]byte(utf8BOM), data...)
+	}
+	return data
+}
+
+// writeTextFile writes content to path in the current text format
+func writeTextFile(path string, content []byte) error {
+	return os.WriteFile(path, encodeText(content), 0666)
+}
+
+// NormalizeTextFile rewrites a subtitle file written by another tool, such as mkvextract, in the
+// current text format
+func NormalizeTextFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	normalized := encodeText(data)
+	if bytes.Equal(normalized, data) {
+		return nil
+	}
+	return os.WriteFile(path, normalized, 0666)
+}
@@ -0,0 +1,332 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"subscalpelmkv/internal/format"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/subtitle"
+	"subscalpelmkv/internal/util"
+)
+
+// Supported translation providers
+const (
+	ProviderLibreTranslate = "libretranslate"
+	ProviderDeepL          = "deepl"
+)
+
+// Default backend settings used when the configuration leaves them empty
+const (
+	DefaultLibreTranslateEndpoint = "http://localhost:5000"
+	DefaultDeepLEndpoint          = "https://api-free.deepl.com"
+	DefaultBatchSize              = 50
+	DefaultRequestsPerMinute      = 30
+)
+
+// Backend translates a batch of texts from one language to another
+type Backend interface {
+	Translate(ctx context.Context, texts []string, source, target string) ([]string, error)
+}
+
+// Translator translates subtitle cues in batches while respecting a request rate limit
+type Translator struct {
+	backend     Backend
+	batchSize   int
+	minInterval time.Duration
+	lastRequest time.Time
+}
+
+// NewTranslator creates a translator for the configured backend
+func NewTranslator(cfg model.TranslationConfig) (*Translator, error) {
+	backend, err := newBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	requestsPerMinute := cfg.RequestsPerMinute
+	if requestsPerMinute <= 0 {
+		requestsPerMinute = DefaultRequestsPerMinute
+	}
+
+	return &Translator{
+		backend:     backend,
+		batchSize:   batchSize,
+		minInterval: time.Minute / time.Duration(requestsPerMinute),
+	}, nil
+}
+
+// TranslateCues returns a copy of the cues with their text translated, preserving timestamps
+func (t *Translator) TranslateCues(ctx context.Context, cues []subtitle.Cue, source, target string) ([]subtitle.Cue, error) {
+	translated := make([]subtitle.Cue, len(cues))
+	copy(translated, cues)
+
+	for start := 0; start < len(cues); start += t.batchSize {
+		end := start + t.batchSize
+		if end > len(cues) {
+			end = len(cues)
+		}
+
+		texts := make([]string, 0, end-start)
+		for _, cue := range cues[start:end] {
+			texts = append(texts, cue.Text)
+		}
+
+		if err := t.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		results, err := t.backend.Translate(ctx, texts, source, target)
+		if err != nil {
+			return nil, err
+		}
+		if len(results) != len(texts) {
+			return nil, fmt.Errorf("translation backend returned %d texts for %d cues", len(results), len(texts))
+		}
+
+		for i, text := range results {
+			translated[start+i].Text = text
+		}
+	}
+
+	return translated, nil
+}
+
+// TranslateSRTFile translates an SRT file and writes the result to outFileName
+func (t *Translator) TranslateSRTFile(ctx context.Context, inFileName, outFileName, source, target string) error {
+	cues, err := subtitle.ReadSRTFile(inFileName)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", inFileName, err)
+	}
+
+	translated, err := t.TranslateCues(ctx, cues, source, target)
+	if err != nil {
+		return err
+	}
+
+	if err := subtitle.WriteSRTFile(outFileName, translated); err != nil {
+		return fmt.Errorf("failed to write %s: %v", outFileName, err)
+	}
+	return nil
+}
+
+// TranslateExtractedTracks writes a translated copy of every extracted SRT track next to the original
+// and returns the jobs of the translated files. Reuse one Translator for a whole batch so the request
+// rate limit applies across files.
+func (t *Translator) TranslateExtractedTracks(ctx context.Context, inputFileName string, jobs []model.ExtractionJob, outputConfig model.OutputConfig) ([]model.ExtractionJob, error) {
+	target := ToBackendLanguage(outputConfig.TranslateTo)
+	targetLanguage := outputConfig.TranslateTo
+	if len(targetLanguage) == 2 {
+		// Name the translated file with the 3-letter code mkvmerge would tag it with
+		targetLanguage = model.FormatLanguageCode(targetLanguage, model.LanguageStyleISO6392B, "")
+	}
+
+	var translatedJobs []model.ExtractionJob
+	for _, job := range jobs {
+		track := job.OriginalTrack
+		if !strings.EqualFold(filepath.Ext(job.OutFileName), ".srt") {
+			format.PrintWarning(fmt.Sprintf("Skipping translation of track %d: only SRT tracks can be translated", track.Properties.Number))
+			continue
+		}
+
+		source := ToBackendLanguage(track.Properties.Language)
+		if strings.EqualFold(source, target) {
+			continue
+		}
+
+		translatedTrack := track
+		translatedTrack.Properties.Language = targetLanguage
+		outFileName := util.BuildSubtitlesFileNameWithConfig(inputFileName, translatedTrack, outputConfig)
+		if outFileName == job.OutFileName {
+			// The template has no {language} placeholder, so tag the file name explicitly
+			ext := filepath.Ext(job.OutFileName)
+			outFileName = strings.TrimSuffix(job.OutFileName, ext) + "." + targetLanguage + ext
+		}
+
+		if err := t.TranslateSRTFile(ctx, job.OutFileName, outFileName, source, target); err != nil {
+			return translatedJobs, fmt.Errorf("failed to translate track %d: %v", track.Properties.Number, err)
+		}
+
+		format.SuccessColor.Print("  ✓ ")
+		format.BaseFg.Println(fmt.Sprintf("Track %d (%s → %s)", track.Properties.Number, track.Properties.Language, targetLanguage))
+		format.PrintExample(fmt.Sprintf("    → %s", outFileName))
+
+		translatedJob := job
+		translatedJob.Track.Properties.Language = targetLanguage
+		translatedJob.OriginalTrack = translatedTrack
+		translatedJob.OutFileName = outFileName
+		translatedJobs = append(translatedJobs, translatedJob)
+	}
+
+	if len(translatedJobs) > 0 {
+		format.PrintSuccess(fmt.Sprintf("Successfully translated %d subtitle track(s)", len(translatedJobs)))
+	}
+	return translatedJobs, nil
+}
+
+// waitForRateLimit sleeps until the minimum interval since the last request has passed, or the
+// context is cancelled
+func (t *Translator) waitForRateLimit(ctx context.Context) error {
+	if !t.lastRequest.IsZero() {
+		if wait := t.minInterval - time.Since(t.lastRequest); wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	t.lastRequest = time.Now()
+	return nil
+}
+
+// ToBackendLanguage converts a 2 or 3 letter language code to the 2-letter code translation APIs expect.
+// Both bibliographic (ger, fre) and terminology (deu, fra) ISO 639-2 codes are accepted.
+func ToBackendLanguage(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return code
+	}
+	return model.FormatLanguageCode(code, model.LanguageStyleISO6391, "")
+}
+
+// newBackend creates the backend for the configured provider
+func newBackend(cfg model.TranslationConfig) (Backend, error) {
+	client := &http.Client{Timeout: 60 * time.Second}
+
+	switch strings.ToLower(cfg.Provider) {
+	case "", ProviderLibreTranslate:
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = DefaultLibreTranslateEndpoint
+		}
+		return &libreTranslateBackend{client: client, endpoint: strings.TrimRight(endpoint, "/"), apiKey: cfg.APIKey}, nil
+	case ProviderDeepL:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("the deepl provider requires an api_key")
+		}
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = DefaultDeepLEndpoint
+		}
+		return &deepLBackend{client: client, endpoint: strings.TrimRight(endpoint, "/"), apiKey: cfg.APIKey}, nil
+	default:
+		return nil, fmt.Errorf("unknown translation provider '%s' (supported: %s, %s)", cfg.Provider, ProviderLibreTranslate, ProviderDeepL)
+	}
+}
+
+// libreTranslateBackend talks to a LibreTranslate server
+type libreTranslateBackend struct {
+	client   *http.Client
+	endpoint string
+	apiKey   string
+}
+
+func (b *libreTranslateBackend) Translate(ctx context.Context, texts []string, source, target string) ([]string, error) {
+	if source == "" || source == "und" {
+		source = "auto"
+	}
+
+	request := map[string]interface{}{
+		"q":      texts,
+		"source": source,
+		"target": target,
+		"format": "text",
+	}
+	if b.apiKey != "" {
+		request["api_key"] = b.apiKey
+	}
+
+	var response struct {
+		TranslatedText []string `json:"translatedText"`
+		Error          string   `json:"error"`
+	}
+	if err := postJSON(ctx, b.client, b.endpoint+"/translate", nil, request, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("libretranslate: %s", response.Error)
+	}
+	return response.TranslatedText, nil
+}
+
+// deepLBackend talks to the DeepL v2 API
+type deepLBackend struct {
+	client   *http.Client
+	endpoint string
+	apiKey   string
+}
+
+func (b *deepLBackend) Translate(ctx context.Context, texts []string, source, target string) ([]string, error) {
+	request := map[string]interface{}{
+		"text":        texts,
+		"target_lang": strings.ToUpper(target),
+	}
+	if source != "" && source != "und" {
+		request["source_lang"] = strings.ToUpper(source)
+	}
+
+	var response struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	headers := map[string]string{"Authorization": "DeepL-Auth-Key " + b.apiKey}
+	if err := postJSON(ctx, b.client, b.endpoint+"/v2/translate", headers, request, &response); err != nil {
+		return nil, err
+	}
+
+	results := make([]string, len(response.Translations))
+	for i, translation := range response.Translations {
+		results[i] = translation.Text
+	}
+	return results, nil
+}
+
+// postJSON sends a JSON request and decodes the JSON response
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode translation request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create translation request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("translation request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read translation response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("translation backend returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	if err := json.Unmarshal(data, response); err != nil {
+		return fmt.Errorf("failed to parse translation response: %v", err)
+	}
+	return nil
+}
@@ -1,9 +1,13 @@
 package util
 
 import (
+	"bufio"
+	"cmp"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"subscalpelmkv/internal/format"
@@ -16,13 +20,20 @@ func DiscoverMKVFiles(args []string) ([]string, error) {
 	var validMKVFiles []string
 	var directories []string
 	
-	// First, check each argument
-	for _, arg := range args {
-		if info, err := os.Stat(arg); err == nil {
+	paths, missing := GroupDroppedPaths(args)
+	for _, path := range missing {
+		format.PrintWarning(fmt.Sprintf("File does not exist: %s", path))
+	}
+
+	// First, check each path
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
 			if info.IsDir() {
-				directories = append(directories, arg)
-			} else if IsMKVFile(arg) {
-				validMKVFiles = append(validMKVFiles, arg)
+				directories = append(directories, path)
+			} else if IsMKVFile(path) {
+				validMKVFiles = append(validMKVFiles, path)
+			} else {
+				format.PrintWarning(fmt.Sprintf("File is not an MKV or WebM file: %s", path))
 			}
 		}
 	}
@@ -38,7 +49,70 @@ func DiscoverMKVFiles(args []string) ([]string, error) {
 		validMKVFiles = append(validMKVFiles, files...)
 	}
 	
-	return validMKVFiles, nil
+	return dedupeFiles(validMKVFiles), nil
+}
+
+// GroupDroppedPaths rebuilds the dropped paths from the command line arguments. Paths with spaces
+// arrive split into several arguments when the launcher does not quote them, so at each position
+// the longest run of arguments that joins into an existing path is taken. Leftover quotes from
+// Windows Explorer, such as the one a trailing backslash escapes in "C:\My Dir\", are removed
+// first. Runs of arguments that form no existing path are returned as missing.
+func GroupDroppedPaths(args []string) (paths []string, missing []string) {
+	args = splitStrayQuotes(args)
+
+	var unmatched []string
+	for i := 0; i < len(args); {
+		matched := 0
+		for j := len(args); j > i; j-- {
+			candidate := strings.Join(args[i:j], " ")
+			if _, err := os.Stat(candidate); err == nil {
+				paths = append(paths, candidate)
+				matched = j - i
+				break
+			}
+		}
+		if matched == 0 {
+			unmatched = append(unmatched, args[i])
+			i++
+			continue
+		}
+		if len(unmatched) > 0 {
+			missing = append(missing, strings.Join(unmatched, " "))
+			unmatched = nil
+		}
+		i += matched
+	}
+	if len(unmatched) > 0 {
+		missing = append(missing, strings.Join(unmatched, " "))
+	}
+	return paths, missing
+}
+
+// splitStrayQuotes removes the double quotes left in arguments that do not name an existing path.
+// Windows treats \" as a literal quote, so a quoted directory ending in a backslash swallows the
+// arguments after it: `"C:\My Dir\" "D:\b.mkv"` arrives as `C:\My Dir" D:\b.mkv`.
+func splitStrayQuotes(args []string) []string {
+	var cleaned []string
+	for _, arg := range args {
+		if _, err := os.Stat(arg); err == nil || !strings.Contains(arg, `"`) {
+			cleaned = append(cleaned, arg)
+			continue
+		}
+		// Only the spaces next to a quote separate arguments; paths may begin or end with spaces
+		parts := strings.Split(arg, `"`)
+		for i, part := range parts {
+			if i > 0 {
+				part = strings.TrimLeft(part, " ")
+			}
+			if i < len(parts)-1 {
+				part = strings.TrimRight(part, " ")
+			}
+			if part != "" {
+				cleaned = append(cleaned, part)
+			}
+		}
+	}
+	return cleaned
 }
 
 // ValidateAndFilterMKVFiles validates a list of file paths and returns only valid MKV files
@@ -55,7 +129,41 @@ func ValidateAndFilterMKVFiles(files []string) ([]string, error) {
 		return nil, fmt.Errorf("no MKV files found")
 	}
 	
-	return mkvFiles, nil
+	return dedupeFiles(mkvFiles), nil
+}
+
+// dedupeFiles drops files that name the same file as an earlier one, e.g. a file passed both on
+// its own and inside a scanned directory, or through a symlink. The first spelling is kept.
+func dedupeFiles(files []string) []string {
+	var unique []string
+	seen := make(map[string]string)
+	for _, file := range files {
+		key := canonicalPath(file)
+		if first, exists := seen[key]; exists {
+			if file == first {
+				format.PrintInfo(fmt.Sprintf("Skipping duplicate input %s", file))
+			} else {
+				format.PrintInfo(fmt.Sprintf("Skipping duplicate input %s (same file as %s)", file, first))
+			}
+			continue
+		}
+		seen[key] = file
+		unique = append(unique, file)
+	}
+	return unique
+}
+
+// canonicalPath returns the absolute path of a file with symlinks resolved, or as much of that as
+// can be determined
+func canonicalPath(file string) string {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.Clean(file)
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path
 }
 
 // BuildOutputConfig creates an OutputConfig with special handling for batch mode
@@ -83,16 +191,215 @@ func BuildOutputConfig(outputDir, outputTemplate string, hasOutputFlagWithoutVal
 	return config
 }
 
-// ResolveOutputDirectory resolves special output directory markers based on the input file
-func ResolveOutputDirectory(outputDir, inputFileName string) string {
-	if outputDir == "__BASENAME_SUBTITLES__" || outputDir == "BATCH_BASENAME_SUBTITLES" {
-		baseName := TrimExtension(filepath.Base(inputFileName))
-		return filepath.Join(filepath.Dir(inputFileName), baseName+"-subtitles")
+// Orders of --sort
+const (
+	SortByName  = "name"
+	SortBySize  = "size"
+	SortByMTime = "mtime"
+)
+
+// ValidateSortOrder checks that order is empty or one of the --sort orders
+func ValidateSortOrder(order string) error {
+	switch order {
+	case "", SortByName, SortBySize, SortByMTime:
+		return nil
+	}
+	return fmt.Errorf("unknown sort order '%s' (supported: %s, %s, %s)", order, SortByName, SortBySize, SortByMTime)
+}
+
+// SortFiles orders batch input files by name (the default), size or modification time, smallest
+// and oldest first unless reversed. Ties keep name order so runs are reproducible.
+func SortFiles(files []string, order string, reverse bool) {
+	infos := make(map[string]os.FileInfo, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			infos[file] = info
+		}
+	}
+
+	compare := func(a, b string) int {
+		infoA, infoB := infos[a], infos[b]
+		if infoA != nil && infoB != nil {
+			switch order {
+			case SortBySize:
+				if c := cmp.Compare(infoA.Size(), infoB.Size()); c != 0 {
+					return c
+				}
+			case SortByMTime:
+				if c := infoA.ModTime().Compare(infoB.ModTime()); c != 0 {
+					return c
+				}
+			}
+		}
+		return strings.Compare(a, b)
+	}
+	slices.SortStableFunc(files, func(a, b string) int {
+		if reverse {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+}
+
+// IsFileList reports whether a batch argument names a list of input files (.txt, .m3u or .m3u8)
+func IsFileList(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt", ".m3u", ".m3u8":
+		return true
+	}
+	return false
+}
+
+// ReadFileList reads the input paths of a list file, one per line. Blank lines and lines starting
+// with # (which includes M3U directives) are ignored. Relative paths are relative to the list file.
+func ReadFileList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file list: %v", err)
+	}
+	defer file.Close()
+
+	var files []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(filepath.Dir(path), line)
+		}
+		files = append(files, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file list: %v", err)
 	}
-	return outputDir
+	return files, nil
 }
 
-// TrimExtension removes the file extension from a filename
-func TrimExtension(filename string) string {
-	return strings.TrimSuffix(filename, filepath.Ext(filename))
-}
\ No newline at end of file
+// maxBraceExpansions caps how many patterns ExpandBraces produces, so a range like {1..100000000}
+// fails instead of hanging
+const maxBraceExpansions = 10000
+
+// ExpandBraces expands the brace expressions of a batch pattern as bash does: "{mkv,mks}" into its
+// alternatives and "{1..3}" or "{01..12}" into a sequence, keeping the zero padding of the bounds.
+// Braces without a comma or range are kept literally. Patterns expanding to more than
+// maxBraceExpansions patterns are rejected.
+func ExpandBraces(pattern string) ([]string, error) {
+	start, end := findBraceGroup(pattern)
+	if start < 0 {
+		return []string{pattern}, nil
+	}
+
+	prefix, body, suffix := pattern[:start], pattern[start+1:end], pattern[end+1:]
+	alternatives := splitBraceBody(body)
+	if len(alternatives) == 1 {
+		sequence, ok, err := expandBraceRange(body)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// Not an expression: keep the braces and expand the rest of the pattern
+			rests, err := ExpandBraces(suffix)
+			if err != nil {
+				return nil, err
+			}
+			var expanded []string
+			for _, rest := range rests {
+				expanded = append(expanded, prefix+"{"+body+"}"+rest)
+			}
+			return expanded, nil
+		}
+		alternatives = sequence
+	}
+
+	var expanded []string
+	for _, alternative := range alternatives {
+		patterns, err := ExpandBraces(prefix + alternative + suffix)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, patterns...)
+		if len(expanded) > maxBraceExpansions {
+			return nil, fmt.Errorf("braces expand to more than %d patterns", maxBraceExpansions)
+		}
+	}
+	return expanded, nil
+}
+
+// findBraceGroup returns the positions of the first opening brace and its matching closing brace
+func findBraceGroup(pattern string) (int, int) {
+	start, depth := -1, 0
+	for i, r := range pattern {
+		switch r {
+		case '{':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+				if depth == 0 {
+					return start, i
+				}
+			}
+		}
+	}
+	return -1, -1
+}
+
+// splitBraceBody splits the body of a brace group at its top-level commas
+func splitBraceBody(body string) []string {
+	var parts []string
+	depth, last := 0, 0
+	for i, r := range body {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, body[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, body[last:])
+}
+
+// expandBraceRange expands a numeric range such as "1..3", "10..8" or "01..12". Ranges of more
+// than maxBraceExpansions numbers are rejected.
+func expandBraceRange(body string) ([]string, bool, error) {
+	first, last, found := strings.Cut(body, "..")
+	if !found {
+		return nil, false, nil
+	}
+	from, fromErr := strconv.Atoi(first)
+	to, toErr := strconv.Atoi(last)
+	if fromErr != nil || toErr != nil {
+		return nil, false, nil
+	}
+	// Unsigned so the distance between extreme bounds does not overflow
+	if distance := uint64(max(from, to)) - uint64(min(from, to)); distance >= maxBraceExpansions {
+		return nil, false, fmt.Errorf("brace range {%s} has more than %d numbers", body, maxBraceExpansions)
+	}
+
+	width := 0
+	if (len(first) > 1 && strings.HasPrefix(first, "0")) || (len(last) > 1 && strings.HasPrefix(last, "0")) {
+		width = max(len(first), len(last))
+	}
+	step := 1
+	if to < from {
+		step = -1
+	}
+	var sequence []string
+	for n := from; ; n += step {
+		sequence = append(sequence, fmt.Sprintf("%0*d", width, n))
+		if n == to {
+			break
+		}
+	}
+	return sequence, true, nil
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGroupDroppedPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"My Show S01E01.mkv", "My Show S01E02.mkv", "b.mkv", "trailing.mkv "} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	myDir := filepath.Join(dir, "My Dir")
+	if err := os.Mkdir(myDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantPaths   []string
+		wantMissing []string
+	}{
+		{
+			name:      "two files with spaces",
+			args:      append(strings.Fields(path("My Show S01E01.mkv")), strings.Fields(path("My Show S01E02.mkv"))...),
+			wantPaths: []string{path("My Show S01E01.mkv"), path("My Show S01E02.mkv")},
+		},
+		{
+			name:        "existing and missing paths",
+			args:        append(append(strings.Fields(path("My Show S01E01.mkv")), strings.Fields(path("Gone Show.mkv"))...), path("b.mkv")),
+			wantPaths:   []string{path("My Show S01E01.mkv"), path("b.mkv")},
+			wantMissing: []string{path("Gone Show.mkv")},
+		},
+		{
+			// "C:\My Dir\" "D:\b.mkv" arrives as C:\My Dir" D:\b.mkv, split at the space in the directory
+			name:      "stray quote after a directory",
+			args:      strings.SplitN(myDir+`" `+path("b.mkv"), " ", 2),
+			wantPaths: []string{myDir, path("b.mkv")},
+		},
+		{
+			name:      "trailing space kept away from quotes",
+			args:      []string{`"` + path("trailing.mkv ")},
+			wantPaths: []string{path("trailing.mkv ")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			paths, missing := GroupDroppedPaths(test.args)
+			if !slices.Equal(paths, test.wantPaths) {
+				t.Errorf("paths = %q, want %q", paths, test.wantPaths)
+			}
+			if !slices.Equal(missing, test.wantMissing) {
+				t.Errorf("missing = %q, want %q", missing, test.wantMissing)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"subscalpelmkv/internal/model"
+)
+
+// LoadRecipe reads a selection recipe saved by SaveRecipe
+func LoadRecipe(path string) (*model.Recipe, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recipe: %v", err)
+	}
+	var recipe model.Recipe
+	if err := json.Unmarshal(data, &recipe); err != nil {
+		return nil, fmt.Errorf("failed to parse recipe %s: %v", path, err)
+	}
+	if recipe.Version != model.RecipeVersion {
+		return nil, fmt.Errorf("recipe %s has unsupported version %d", path, recipe.Version)
+	}
+	if len(recipe.Tracks) == 0 {
+		return nil, fmt.Errorf("recipe %s selects no tracks", path)
+	}
+	return &recipe, nil
+}
+
+// SaveRecipe writes a selection recipe as indented JSON
+func SaveRecipe(path string, recipe model.Recipe) error {
+	data, err := json.MarshalIndent(recipe, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recipe: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write recipe: %v", err)
+	}
+	return nil
+}
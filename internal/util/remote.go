@@ -0,0 +1,195 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"subscalpelmkv/internal/format"
+)
+
+// IsRemoteURL checks if the input is an http(s) URL rather than a local path
+func IsRemoteURL(input string) bool {
+	lower := strings.ToLower(input)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// downloadStallTimeout aborts a download when no data arrives for this long
+const downloadStallTimeout = 60 * time.Second
+
+// downloadClient bounds connection setup and waiting for response headers; stalled bodies are
+// handled by stallTimeoutReader since the transfer itself may legitimately take hours
+var downloadClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   15 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+	},
+}
+
+// DownloadToTemp downloads a remote MKV file into a new temporary directory, keeping its file name
+// so templates see the original basename. The returned cleanup function removes the download;
+// it also runs if the user presses Ctrl-C before cleanup is called.
+func DownloadToTemp(ctx context.Context, rawURL string) (string, func(), error) {
+	ctx, stopSignals := signal.NotifyContext(ctx, os.Interrupt)
+	defer stopSignals()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid URL %s: %v", rawURL, err)
+	}
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to download %s: %v", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("failed to download %s: %s", rawURL, resp.Status)
+	}
+
+	tempDir, err := os.MkdirTemp("", "subscalpelmkv-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	cleanup := func() {
+		os.RemoveAll(tempDir)
+	}
+
+	localFileName := filepath.Join(tempDir, remoteFileName(rawURL, resp.Header.Get("Content-Disposition")))
+	file, err := os.Create(localFileName)
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to create %s: %v", localFileName, err)
+	}
+
+	format.PrintInfo(fmt.Sprintf("Downloading %s", rawURL))
+	var reader io.Reader = newStallTimeoutReader(resp.Body, downloadStallTimeout, cancel)
+	if resp.ContentLength > 0 {
+		ResetProgressBar()
+		reader = &downloadProgressReader{reader: reader, total: resp.ContentLength}
+	}
+
+	_, copyErr := io.Copy(file, reader)
+	closeErr := file.Close()
+	if copyErr != nil {
+		cleanup()
+		if ctx.Err() != nil {
+			return "", nil, fmt.Errorf("download of %s was interrupted or stalled", rawURL)
+		}
+		return "", nil, fmt.Errorf("failed to download %s: %v", rawURL, copyErr)
+	}
+	if closeErr != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write %s: %v", localFileName, closeErr)
+	}
+
+	return localFileName, cleanupOnInterrupt(cleanup), nil
+}
+
+// cleanupOnInterrupt runs cleanup and exits when Ctrl-C is pressed, until the returned function is called
+func cleanupOnInterrupt(cleanup func()) func() {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-interrupts:
+			cleanup()
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(interrupts)
+			close(done)
+			cleanup()
+		})
+	}
+}
+
+// stallTimeoutReader cancels a download when no data has been read for the timeout
+type stallTimeoutReader struct {
+	reader io.Reader
+	timer  *time.Timer
+	idle   time.Duration
+}
+
+func newStallTimeoutReader(reader io.Reader, idle time.Duration, cancel func()) *stallTimeoutReader {
+	return &stallTimeoutReader{reader: reader, timer: time.AfterFunc(idle, cancel), idle: idle}
+}
+
+func (r *stallTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.idle)
+	}
+	if err != nil {
+		r.timer.Stop()
+	}
+	return n, err
+}
+
+// remoteFileName picks a local file name for a download from the Content-Disposition header or the URL path
+func remoteFileName(rawURL, contentDisposition string) string {
+	var name string
+	if _, params, err := mime.ParseMediaType(contentDisposition); err == nil {
+		name = filepath.Base(params["filename"])
+	}
+
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		if parsed, err := url.Parse(rawURL); err == nil {
+			name = path.Base(parsed.Path)
+		}
+	}
+
+	if name == "" || name == "." || name == "/" {
+		name = "download"
+	}
+	if !IsMKVFile(name) {
+		name += ".mkv"
+	}
+	return name
+}
+
+// downloadProgressReader reports download progress through the shared progress bar
+type downloadProgressReader struct {
+	reader      io.Reader
+	total       int64
+	read        int64
+	lastPercent int
+}
+
+func (r *downloadProgressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+
+	percentage := int(r.read * 100 / r.total)
+	if percentage > 100 {
+		percentage = 100
+	}
+	if percentage != r.lastPercent {
+		r.lastPercent = percentage
+		ShowProgressBar(percentage)
+	}
+	return n, err
+}
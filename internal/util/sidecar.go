@@ -0,0 +1,273 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/naming"
+)
+
+// FindExistingSidecar looks for an external subtitle file that already covers the track's language
+// and format. Besides this tool's own output name, common naming patterns such as movie.en.srt,
+// movie.eng.forced.srt, movie_English.srt and movie-en.srt are recognized, but not the files of
+// other titles such as movie 2.en.srt. Returns the path of the first match or an empty string.
+func FindExistingSidecar(inputFileName string, track model.MKVTrack, outputConfig model.OutputConfig) string {
+	if outFileName := BuildSubtitlesFileNameWithConfig(inputFileName, track, outputConfig); fileExists(outFileName) {
+		return outFileName
+	}
+
+	dirs := []string{filepath.Dir(inputFileName)}
+	if outputConfig.OutputDir != "" {
+		outputDir := naming.OutputDirectory(inputFileName, outputConfig)
+		if filepath.Clean(outputDir) != filepath.Clean(dirs[0]) {
+			dirs = append(dirs, outputDir)
+		}
+	}
+
+	baseName := strings.ToLower(naming.TrimExtension(filepath.Base(inputFileName)))
+	trackFormat := model.GetSubtitleFormatFromCodec(track.Properties.CodecId)
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			name := entry.Name()
+			if !sidecarFormatMatches(filepath.Ext(name), trackFormat) {
+				continue
+			}
+
+			tokens, ok := sidecarTokens(baseName, naming.TrimExtension(name))
+			if ok && sidecarTokensMatch(tokens, track) {
+				return filepath.Join(dir, name)
+			}
+		}
+	}
+
+	return ""
+}
+
+// sidecarFormatMatches checks whether a sidecar extension holds the given subtitle format
+func sidecarFormatMatches(ext, trackFormat string) bool {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	if ext == trackFormat {
+		return true
+	}
+	// VOBSUB tracks are stored as an .idx/.sub pair
+	return trackFormat == "sub" && ext == "idx"
+}
+
+// sidecarFlags are the name segments besides languages that mark a subtitle file's kind
+var sidecarFlags = map[string]bool{"forced": true, "default": true, "sdh": true, "hi": true, "cc": true}
+
+// sidecarTokens splits the part of a file stem after the MKV's basename into its segments. It reports
+// false unless the stem continues the basename with a ., - or _ and every segment is a language or
+// one of sidecarFlags, so Movie 2.en.srt or Movie (Director's Cut).en.srt do not belong to Movie.mkv.
+func sidecarTokens(baseName, stem string) ([]string, bool) {
+	stem = strings.ToLower(stem)
+	rest, found := strings.CutPrefix(stem, baseName)
+	if !found || rest == "" || !strings.ContainsRune(".-_", rune(rest[0])) {
+		return nil, false
+	}
+
+	var tokens []string
+	for _, segment := range strings.FieldsFunc(rest, func(r rune) bool { return r == '.' || r == '_' }) {
+		// Keep tags such as pt-br whole, otherwise - separates segments too, as in movie-en-forced
+		if isSidecarToken(segment) {
+			tokens = append(tokens, segment)
+			continue
+		}
+		for _, part := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' }) {
+			if !isSidecarToken(part) {
+				return nil, false
+			}
+			tokens = append(tokens, part)
+		}
+	}
+	return tokens, len(tokens) > 0
+}
+
+// isSidecarToken reports whether a lowercase name segment is a language code or name, or a flag
+func isSidecarToken(token string) bool {
+	if sidecarFlags[token] || model.IsLanguageCode(token) {
+		return true
+	}
+	for _, name := range model.LanguageNames {
+		if strings.EqualFold(name, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// sidecarTokensMatch checks the name segments after the basename for the track's language and forced flag
+func sidecarTokensMatch(tokens []string, track model.MKVTrack) bool {
+	languageFound := false
+	forced := false
+	for _, token := range tokens {
+		if token == "forced" {
+			forced = true
+			continue
+		}
+		if matchesLanguageToken(track.LanguageTag(), token) {
+			languageFound = true
+		}
+	}
+
+	return languageFound && forced == track.Properties.Forced
+}
+
+// matchesLanguageToken checks if a file name segment names the given track language
+func matchesLanguageToken(trackLanguage, token string) bool {
+	if trackLanguage == "" || trackLanguage == "und" {
+		return false
+	}
+	if model.IsLanguageCode(token) && model.MatchesLanguageFilter(trackLanguage, token) {
+		return true
+	}
+	return strings.EqualFold(model.GetLanguageName(trackLanguage), token)
+}
+
+// ExistingOutput returns the file at a track's output path, which extracting the track would
+// overwrite, or an empty string. For VobSub tracks the .idx written beside the .sub counts too.
+func ExistingOutput(inputFileName string, track model.MKVTrack, outputConfig model.OutputConfig) string {
+	outFileName := BuildSubtitlesFileNameWithConfig(inputFileName, track, outputConfig)
+	if fileExists(outFileName) {
+		return outFileName
+	}
+	if track.Properties.CodecId == "S_VOBSUB" {
+		if idxFileName := naming.TrimExtension(outFileName) + ".idx"; fileExists(idxFileName) {
+			return idxFileName
+		}
+	}
+	return ""
+}
+
+// BackupExistingOutput moves the existing files at a track's output path aside before extraction
+// overwrites them: to <name>.bak beside them, or into outputConfig.BackupDir. An earlier backup is
+// replaced, so exactly one previous version is kept. Returns the backups made.
+func BackupExistingOutput(inputFileName string, track model.MKVTrack, outputConfig model.OutputConfig) ([]string, error) {
+	outFileName := BuildSubtitlesFileNameWithConfig(inputFileName, track, outputConfig)
+	paths := []string{outFileName}
+	if track.Properties.CodecId == "S_VOBSUB" {
+		paths = append(paths, naming.TrimExtension(outFileName)+".idx")
+	}
+
+	var backups []string
+	for _, path := range paths {
+		if !fileExists(path) {
+			continue
+		}
+		backup := path + ".bak"
+		if outputConfig.BackupDir != "" {
+			if err := os.MkdirAll(outputConfig.BackupDir, 0755); err != nil {
+				return backups, fmt.Errorf("could not create backup directory %s: %v", outputConfig.BackupDir, err)
+			}
+			backup = filepath.Join(outputConfig.BackupDir, filepath.Base(path))
+		}
+		if err := moveFile(path, backup); err != nil {
+			return backups, fmt.Errorf("could not back up %s: %v", filepath.Base(path), err)
+		}
+		backups = append(backups, backup)
+	}
+	return backups, nil
+}
+
+// moveFile renames a file, copying it when the destination is on another filesystem. The copy goes
+// to a temporary file beside the destination first, so a failed copy leaves an earlier file there intact.
+func moveFile(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+	source, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	info, err := source.Stat()
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(to), "."+filepath.Base(to)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(temp, source); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+	if err := os.Chmod(temp.Name(), info.Mode().Perm()); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+	if err := os.Rename(temp.Name(), to); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+	source.Close()
+	return os.Remove(from)
+}
+
+// fileExists reports whether a regular file exists at the given path
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// FindLanguageSidecar looks next to the MKV for an external subtitle file of any format whose name
+// carries the language, such as movie.en.srt or movie.German.forced.ass. Returns its path or an empty string.
+func FindLanguageSidecar(inputFileName, language string) string {
+	dir := filepath.Dir(inputFileName)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	baseName := strings.ToLower(naming.TrimExtension(filepath.Base(inputFileName)))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !isSubtitleExtension(filepath.Ext(name)) {
+			continue
+		}
+
+		tokens, ok := sidecarTokens(baseName, naming.TrimExtension(name))
+		if !ok {
+			continue
+		}
+		for _, token := range tokens {
+			if matchesLanguageToken(language, token) {
+				return filepath.Join(dir, name)
+			}
+		}
+	}
+	return ""
+}
+
+// isSubtitleExtension reports whether a file extension belongs to a subtitle format
+func isSubtitleExtension(ext string) bool {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	if ext == "idx" {
+		return true
+	}
+	for _, subtitleExt := range model.SubtitleExtensionByCodec {
+		if ext == subtitleExt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"subscalpelmkv/internal/i18n"
+	"subscalpelmkv/internal/progress"
+)
+
+// stableWaitWindows is how many stability windows WaitUntilStable waits for a file that keeps changing
+const stableWaitWindows = 10
+
+// WaitUntilStable waits until a file has not been modified for window (writes to it change its
+// modification time) and, where the system can tell, no other process has it open for writing, so
+// files still being copied or downloaded are not analyzed half-written. Files that settled long ago
+// return at once. It gives up once the file has kept changing for ten windows.
+func WaitUntilStable(ctx context.Context, path string, window time.Duration) error {
+	deadline := time.Now().Add(stableWaitWindows * window)
+	var spinner *progress.Spinner
+	defer func() {
+		if spinner != nil {
+			spinner.Stop()
+		}
+	}()
+
+	for {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		age := time.Since(info.ModTime())
+		if age >= window && !fileInUse(path) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("file is still being written after %v", stableWaitWindows*window)
+		}
+		if spinner == nil {
+			spinner = StartSpinner(i18n.T("Waiting for the file to finish copying..."))
+		}
+
+		// Check again once the file would be old enough, and at least every second
+		wait := window - age
+		if wait <= 0 || wait > time.Second {
+			wait = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
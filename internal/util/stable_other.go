@@ -0,0 +1,9 @@
+//go:build !windows
+
+package util
+
+// fileInUse reports whether another process holds the file open for writing. Unix systems do not
+// lock files being written, so only the modification time tells.
+func fileInUse(path string) bool {
+	return false
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package util
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// errorSharingViolation is ERROR_SHARING_VIOLATION, returned when another process opened the file
+// without sharing write access, as copies and downloads in progress do
+const errorSharingViolation syscall.Errno = 32
+
+// fileInUse reports whether another process holds the file open for writing
+func fileInUse(path string) bool {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return errors.Is(err, errorSharingViolation)
+	}
+	file.Close()
+	return false
+}
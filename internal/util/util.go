@@ -4,16 +4,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"subscalpelmkv/internal/format"
 	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/naming"
 	"subscalpelmkv/internal/progress"
 )
 
-// IsMKVFile checks if the given filename is an MKV file
+// IsMKVFile checks if the given filename is an MKV file (including WebM, a Matroska subset)
 func IsMKVFile(inputFileName string) bool {
 	lower := strings.ToLower(inputFileName)
-	return strings.HasSuffix(lower, ".mkv") || strings.HasSuffix(lower, ".mks")
+	return strings.HasSuffix(lower, ".mkv") || strings.HasSuffix(lower, ".mks") || strings.HasSuffix(lower, ".webm")
 }
 
 // BuildSubtitlesFileName builds the output filename for extracted subtitles
@@ -27,129 +30,14 @@ func BuildSubtitlesFileName(inputFileName string, track model.MKVTrack) string {
 	return BuildSubtitlesFileNameWithConfig(inputFileName, track, config)
 }
 
-// BuildSubtitlesFileNameWithConfig builds the output filename using custom configuration
+// BuildSubtitlesFileNameWithConfig builds the output filename using custom configuration, warning
+// when it falls back to the input file's directory or the template name
 func BuildSubtitlesFileNameWithConfig(inputFileName string, track model.MKVTrack, config model.OutputConfig) string {
-	var outputDir string
-	if config.OutputDir != "" {
-		// Handle special case for batch mode with -o flag without arguments
-		if config.OutputDir == "BATCH_BASENAME_SUBTITLES" {
-			baseName := strings.TrimSuffix(filepath.Base(inputFileName), filepath.Ext(inputFileName))
-			outputDir = filepath.Join(filepath.Dir(inputFileName), baseName+"-subtitles")
-		} else {
-			outputDir = config.OutputDir
-		}
-	} else {
-		outputDir = filepath.Dir(inputFileName)
-	}
-
-	// Always create output directory if it doesn't exist and a custom output directory is specified
-	if config.OutputDir != "" {
-		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			fmt.Printf("Warning: Could not create output directory %s: %v\n", outputDir, err)
-			// Fall back to input file directory
-			outputDir = filepath.Dir(inputFileName)
-		}
-	}
-
-	fileName := BuildFileNameFromTemplate(inputFileName, track, config.Template)
-
-	return filepath.Join(outputDir, fileName)
-}
-
-// BuildFileNameFromTemplate builds a filename using a template with placeholders
-func BuildFileNameFromTemplate(inputFileName string, track model.MKVTrack, template string) string {
-	if template == "" {
-		template = model.DefaultOutputTemplate
-	}
-
-	fileName := filepath.Base(inputFileName)
-	extension := filepath.Ext(fileName)
-	baseName := strings.TrimSuffix(fileName, extension)
-
-	subtitleExt := model.SubtitleExtensionByCodec[track.Properties.CodecId]
-	if subtitleExt == "" {
-		subtitleExt = "srt" // fallback
-	}
-
-	// Special handling for S_VOBSUB: ensure we use .sub extension
-	// (mkvextract will create both .idx and .sub files automatically)
-	if track.Properties.CodecId == "S_VOBSUB" {
-		subtitleExt = "sub"
-	}
-
-	// Format track number with leading zeros
-	trackNo := fmt.Sprintf("%03d", track.Properties.Number)
-
-	replacements := map[string]string{
-		"{basename}":  baseName,
-		"{language}":  track.Properties.Language,
-		"{trackno}":   trackNo,
-		"{trackname}": sanitizeFileName(track.Properties.TrackName),
-		"{forced}":    "",
-		"{default}":   "",
-		"{extension}": subtitleExt,
-	}
-
-	if track.Properties.Forced {
-		replacements["{forced}"] = "forced"
-	}
-	if track.Properties.Default {
-		replacements["{default}"] = "default"
-	}
-
-	result := template
-	for placeholder, value := range replacements {
-		result = strings.ReplaceAll(result, placeholder, value)
-	}
-
-	// Clean up multiple consecutive dots and trailing dots
-	result = cleanupFileName(result)
-
-	return result
-}
-
-// sanitizeFileName removes or replaces characters that are invalid in filenames
-func sanitizeFileName(filename string) string {
-	if filename == "" {
-		return ""
-	}
-	
-	// Replace problematic characters with safe alternatives
-	replacements := map[string]string{
-		"/": "-",     // Forward slash
-		"\\": "-",    // Backslash
-		":": "-",     // Colon
-		"*": "",      // Asterisk
-		"?": "",      // Question mark
-		"\"": "",     // Double quote
-		"<": "",      // Less than
-		">": "",      // Greater than
-		"|": "-",     // Pipe
-	}
-	
-	result := filename
-	for invalid, replacement := range replacements {
-		result = strings.ReplaceAll(result, invalid, replacement)
-	}
-	
-	// Remove leading/trailing spaces and dots
-	result = strings.Trim(result, " .")
-	
-	return result
-}
-
-// cleanupFileName removes empty segments and cleans up the filename
-func cleanupFileName(filename string) string {
-	parts := strings.Split(filename, ".")
-	var cleanParts []string
-
-	for _, part := range parts {
-		if part != "" {
-			cleanParts = append(cleanParts, part)
-		}
+	fileName, err := naming.SubtitlesFileName(inputFileName, track, config)
+	if err != nil {
+		format.PrintWarning(err.Error())
 	}
-
-	return strings.Join(cleanParts, ".")
+	return fileName
 }
 
 // MatchesTrackSelection checks if a track matches the user's selection criteria
@@ -159,8 +47,13 @@ func MatchesTrackSelection(track model.MKVTrack, selection model.TrackSelection)
 		return false
 	}
 
+	// A filter expression must hold in addition to the selection lists
+	if selection.Filter != nil && !selection.Filter.Matches(track) {
+		return false
+	}
+
 	// If no selection criteria, match all (after exclusions)
-	if len(selection.LanguageCodes) == 0 && len(selection.TrackNumbers) == 0 && len(selection.FormatFilters) == 0 {
+	if len(selection.LanguageCodes) == 0 && len(selection.TrackNumbers) == 0 && len(selection.FormatFilters) == 0 && len(selection.StatusFilters) == 0 {
 		return true
 	}
 
@@ -173,10 +66,13 @@ func MatchesTrackSelection(track model.MKVTrack, selection model.TrackSelection)
 
 	// Check if language matches (additive OR logic)
 	for _, langCode := range selection.LanguageCodes {
-		if model.MatchesLanguageFilter(track.Properties.Language, langCode) {
+		if model.MatchesLanguageFilter(track.LanguageTag(), langCode) {
 			return true
 		}
 	}
+	if selection.IncludeUntagged && len(selection.LanguageCodes) > 0 && track.IsUntagged() {
+		return true
+	}
 
 	// Check if format matches (additive OR logic)
 	for _, formatFilter := range selection.FormatFilters {
@@ -185,13 +81,41 @@ func MatchesTrackSelection(track model.MKVTrack, selection model.TrackSelection)
 		}
 	}
 
+	// Check if enabled/disabled status matches (additive OR logic)
+	for _, statusFilter := range selection.StatusFilters {
+		if model.MatchesStatusFilter(track, statusFilter) {
+			return true
+		}
+	}
+
 	return false
 }
 
+// MatchesUntaggedTrack checks if a track without a language tag would match the selection were
+// untagged tracks included in its language codes, as --und-policy include and prompt do
+func MatchesUntaggedTrack(track model.MKVTrack, selection model.TrackSelection) bool {
+	selection.IncludeUntagged = true
+	return track.IsUntagged() && MatchesTrackSelection(track, selection)
+}
+
+// SelectionMissReason explains why MatchesTrackSelection rejects a track
+func SelectionMissReason(track model.MKVTrack, selection model.TrackSelection) string {
+	if MatchesTrackExclusion(track, selection.Exclusions) {
+		return "excluded"
+	}
+	if selection.Filter != nil && !selection.Filter.Matches(track) {
+		return "does not match the filter"
+	}
+	if !selection.IncludeUntagged && MatchesUntaggedTrack(track, selection) {
+		return "has no language tag (see --und-policy)"
+	}
+	return "not selected"
+}
+
 // MatchesTrackExclusion checks if a track matches any of the exclusion criteria
 func MatchesTrackExclusion(track model.MKVTrack, exclusion model.TrackExclusion) bool {
 	// If no exclusion criteria, don't exclude any tracks
-	if len(exclusion.LanguageCodes) == 0 && len(exclusion.TrackNumbers) == 0 && len(exclusion.FormatFilters) == 0 {
+	if len(exclusion.LanguageCodes) == 0 && len(exclusion.TrackNumbers) == 0 && len(exclusion.FormatFilters) == 0 && len(exclusion.StatusFilters) == 0 {
 		return false
 	}
 
@@ -204,7 +128,7 @@ func MatchesTrackExclusion(track model.MKVTrack, exclusion model.TrackExclusion)
 
 	// Check if language matches exclusion
 	for _, langCode := range exclusion.LanguageCodes {
-		if model.MatchesLanguageFilter(track.Properties.Language, langCode) {
+		if model.MatchesLanguageFilter(track.LanguageTag(), langCode) {
 			return true
 		}
 	}
@@ -216,9 +140,72 @@ func MatchesTrackExclusion(track model.MKVTrack, exclusion model.TrackExclusion)
 		}
 	}
 
+	// Check if enabled/disabled status matches exclusion
+	for _, statusFilter := range exclusion.StatusFilters {
+		if model.MatchesStatusFilter(track, statusFilter) {
+			return true
+		}
+	}
+
 	return false
 }
 
+// PickPerLanguage keeps one subtitle track of each language by a --per-language rule: the first,
+// the last or the Nth (from 1) in file order. Languages with fewer than N tracks keep none. Other
+// track types are always kept.
+func PickPerLanguage(tracks []model.MKVTrack, rule string) (kept, dropped []model.MKVTrack) {
+	byLanguage := make(map[string][]int)
+	for i, track := range tracks {
+		if track.Type == "subtitles" {
+			key := model.LanguageKey(track.LanguageTag())
+			byLanguage[key] = append(byLanguage[key], i)
+		}
+	}
+
+	keep := make(map[int]bool)
+	for _, indexes := range byLanguage {
+		switch rule {
+		case model.PerLanguageFirst:
+			keep[indexes[0]] = true
+		case model.PerLanguageLast:
+			keep[indexes[len(indexes)-1]] = true
+		default:
+			if position, err := strconv.Atoi(rule); err == nil && position > 0 && position <= len(indexes) {
+				keep[indexes[position-1]] = true
+			}
+		}
+	}
+
+	for i, track := range tracks {
+		if track.Type != "subtitles" || keep[i] {
+			kept = append(kept, track)
+		} else {
+			dropped = append(dropped, track)
+		}
+	}
+	return kept, dropped
+}
+
+// CapPerLanguage keeps at most limit subtitle tracks of each language, the first ones in file order.
+// Other track types are always kept.
+func CapPerLanguage(tracks []model.MKVTrack, limit int) (kept, dropped []model.MKVTrack) {
+	counts := make(map[string]int)
+	for _, track := range tracks {
+		if track.Type != "subtitles" {
+			kept = append(kept, track)
+			continue
+		}
+		key := model.LanguageKey(track.LanguageTag())
+		if counts[key] < limit {
+			counts[key]++
+			kept = append(kept, track)
+		} else {
+			dropped = append(dropped, track)
+		}
+	}
+	return kept, dropped
+}
+
 // MatchesAnyLanguageFilter checks if a track language matches any of the specified filters
 func MatchesAnyLanguageFilter(trackLanguage string, languageFilters []string) bool {
 	if len(languageFilters) == 0 {
@@ -239,6 +226,11 @@ func ShowProgressBar(percentage int) {
 	progress.ShowProgressBar(percentage)
 }
 
+// StartSpinner shows an activity spinner for work without measurable progress
+func StartSpinner(label string) *progress.Spinner {
+	return progress.StartSpinner(label)
+}
+
 // UpdateElapsedTime updates only the elapsed time without changing the percentage
 func UpdateElapsedTime() {
 	progress.UpdateElapsedTime()
@@ -249,9 +241,9 @@ func ResetProgressBar() {
 	progress.ResetProgressBar()
 }
 
-// ParseProgressLine extracts percentage from mkvmerge progress output
-func ParseProgressLine(line string) (int, bool) {
-	return progress.ParseProgressLine(line)
+// SetProgressTotalBytes sets the size of the input read by the operation shown in the progress bar
+func SetProgressTotalBytes(bytes int64) {
+	progress.SetTotalBytes(bytes)
 }
 
 // FindMKVFilesInDirectory recursively finds all MKV files in a directory
@@ -276,3 +268,19 @@ func FindMKVFilesInDirectory(dir string) ([]string, error) {
 	
 	return mkvFiles, nil
 }
+
+// FormatSize formats a byte count with a binary unit, e.g. "512 B" or "1.4 MiB"
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value := float64(bytes) / unit
+	units := []string{"KiB", "MiB", "GiB"}
+	i := 0
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
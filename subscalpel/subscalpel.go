@@ -0,0 +1,268 @@
+// Package subscalpel extracts subtitle tracks from Matroska files for programs embedding
+// SubScalpelMKV. An Extractor is configured once with functional options and reused:
+//
+//	extractor, err := subscalpel.New(
+//		subscalpel.WithSelection("eng,all-forced"),
+//		subscalpel.WithOutputTemplate("{basename}.{language}.{extension}"),
+//	)
+//	jobs, err := extractor.Extract(ctx, "Movie.mkv")
+package subscalpel
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"subscalpelmkv/internal/mkv"
+	"subscalpelmkv/internal/model"
+	"subscalpelmkv/internal/naming"
+	"subscalpelmkv/internal/util"
+)
+
+// Track is a track of a Matroska file as reported by mkvmerge
+type Track = model.MKVTrack
+
+// Job is an extracted subtitle track and the file it was written to
+type Job = model.ExtractionJob
+
+// Event reports the progress of an extraction
+type Event = mkv.Event
+
+// Namer names output files in place of the filename template
+type Namer interface {
+	// Name returns the file name, without directory, of a track's output. source is the input file
+	// and templateName the name the filename template gives the track. An error falls back to templateName.
+	Name(source string, track Track, templateName string) (string, error)
+}
+
+// namerAdapter lets a Namer name files where the output configuration expects a model.Namer
+type namerAdapter struct {
+	namer Namer
+}
+
+// Name names the file with the adapted Namer, which does not need the output configuration
+func (a namerAdapter) Name(inputFileName string, track model.MKVTrack, templateName string, _ model.OutputConfig) (string, error) {
+	return a.namer.Name(inputFileName, track, templateName)
+}
+
+// Tools names the MKVToolNix programs to run; empty fields are looked up in PATH
+type Tools struct {
+	MKVMerge    string
+	MKVExtract  string
+	MKVPropEdit string
+}
+
+// Extractor extracts the subtitle tracks selected by its options
+type Extractor struct {
+	selection    model.TrackSelection
+	filter       string
+	outputConfig model.OutputConfig
+	tools        Tools
+	logger       *slog.Logger
+	progress     func(Event)
+	unknownItems []string // Selection and exclusion items that are no language, format, status or track ID
+}
+
+// Option configures an Extractor
+type Option func(*Extractor)
+
+// WithSelection selects tracks with the syntax of -s, e.g. "eng,jpn", "3-5", "srt" or "eng,!sup"
+func WithSelection(selection string) Option {
+	return func(e *Extractor) {
+		exclusions := e.selection.Exclusions
+		var unknownItems []string
+		e.selection, unknownItems = model.ParseTrackSelection(selection)
+		e.selection.Exclusions = model.MergeTrackExclusions(exclusions, e.selection.Exclusions)
+		e.unknownItems = append(e.unknownItems, unknownItems...)
+	}
+}
+
+// WithExclusion excludes tracks with the syntax of -e, in addition to items negated with ! in the selection
+func WithExclusion(exclusion string) Option {
+	return func(e *Extractor) {
+		parsed, unknownItems := model.ParseTrackExclusion(exclusion)
+		e.selection.Exclusions = model.MergeTrackExclusions(e.selection.Exclusions, parsed)
+		for _, item := range unknownItems {
+			e.unknownItems = append(e.unknownItems, "!"+item)
+		}
+	}
+}
+
+// WithFilter only extracts tracks matching a filter expression (see --filter), e.g. "lang==eng && !forced"
+func WithFilter(expression string) Option {
+	return func(e *Extractor) {
+		e.filter = expression
+	}
+}
+
+// WithOutputTemplate names the extracted files with a filename template (see --format)
+func WithOutputTemplate(template string) Option {
+	return func(e *Extractor) {
+		e.outputConfig.Template = template
+	}
+}
+
+// WithNamer names the extracted files with a Namer instead of the filename template
+func WithNamer(namer Namer) Option {
+	return func(e *Extractor) {
+		if namer == nil {
+			e.outputConfig.Namer = nil
+			return
+		}
+		e.outputConfig.Namer = namerAdapter{namer: namer}
+	}
+}
+
+// WithOutputDir writes the extracted files to dir instead of beside the input file
+func WithOutputDir(dir string) Option {
+	return func(e *Extractor) {
+		e.outputConfig.OutputDir = dir
+		e.outputConfig.CreateDir = true
+	}
+}
+
+// WithTools runs the given MKVToolNix programs
+func WithTools(tools Tools) Option {
+	return func(e *Extractor) {
+		e.tools = tools
+	}
+}
+
+// WithLogger logs the extraction's messages and extracted tracks; stage changes are logged at debug level
+func WithLogger(logger *slog.Logger) Option {
+	return func(e *Extractor) {
+		e.logger = logger
+	}
+}
+
+// WithProgress passes every extraction event, including progress percentages, to fn
+func WithProgress(fn func(Event)) Option {
+	return func(e *Extractor) {
+		e.progress = fn
+	}
+}
+
+// New creates an Extractor. Without options it extracts every subtitle track beside the input
+// file, named with the default template.
+func New(options ...Option) (*Extractor, error) {
+	e := &Extractor{
+		outputConfig: model.OutputConfig{Template: model.DefaultOutputTemplate},
+	}
+	for _, option := range options {
+		option(e)
+	}
+
+	if len(e.unknownItems) > 0 {
+		return nil, fmt.Errorf("invalid selection: unknown language code, format or track ID '%s'", strings.Join(e.unknownItems, "', '"))
+	}
+	if err := model.ValidateOutputTemplate(e.outputConfig.Template); err != nil {
+		return nil, fmt.Errorf("invalid output template: %v", err)
+	}
+	if e.filter != "" {
+		filter, err := model.ParseTrackFilter(e.filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter: %v", err)
+		}
+		e.selection.Filter = filter
+	}
+	return e, nil
+}
+
+// Tracks returns the subtitle tracks of a file
+func (e *Extractor) Tracks(ctx context.Context, inputFileName string) ([]Track, error) {
+	mkvInfo, err := mkv.GetTrackInfo(e.context(ctx), inputFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []Track
+	for _, track := range mkvInfo.Tracks {
+		if track.Type == "subtitles" {
+			tracks = append(tracks, track)
+		}
+	}
+	return tracks, nil
+}
+
+// Extract writes the selected subtitle tracks of a file and returns where each one went
+func (e *Extractor) Extract(ctx context.Context, inputFileName string) ([]Job, error) {
+	tracks, err := e.Tracks(ctx, inputFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var selectedTracks []Track
+	for _, track := range tracks {
+		if util.MatchesTrackSelection(track, e.selection) {
+			selectedTracks = append(selectedTracks, track)
+		}
+	}
+	if len(selectedTracks) == 0 {
+		return nil, nil
+	}
+
+	ctx = e.context(ctx)
+
+	// Like the CLI, copy the selected tracks into a temporary .mks first, which is much faster to extract from
+	mksFileName, mksTracks, err := mkv.CreateSubtitlesMKS(ctx, inputFileName, selectedTracks, e.outputConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer mkv.CleanupTempFile(mksFileName)
+
+	var jobs []Job
+	for i, originalTrack := range selectedTracks {
+		outFileName, err := naming.SubtitlesFileName(inputFileName, originalTrack, e.outputConfig)
+		if err != nil {
+			e.handleEvent(Event{Kind: mkv.EventWarning, Stage: mkv.StagePrepare, File: inputFileName, Message: err.Error()})
+		}
+		jobs = append(jobs, Job{
+			Track:         mksTracks[i],
+			OriginalTrack: originalTrack,
+			OutFileName:   outFileName,
+			MksFileName:   mksFileName,
+		})
+	}
+
+	if err := mkv.ProcessTracks(ctx, jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// context returns a context whose mkv calls run the Extractor's tools and report to its handleEvent,
+// leaving the package defaults to other Extractors and the CLI
+func (e *Extractor) context(ctx context.Context) context.Context {
+	ctx = mkv.WithToolPaths(ctx, map[string]string{
+		"mkvmerge":    e.tools.MKVMerge,
+		"mkvextract":  e.tools.MKVExtract,
+		"mkvpropedit": e.tools.MKVPropEdit,
+	})
+	return mkv.WithProgressFunc(ctx, e.handleEvent)
+}
+
+// handleEvent passes an event to the progress function and logs it
+func (e *Extractor) handleEvent(event Event) {
+	if e.progress != nil {
+		e.progress(event)
+	}
+	if e.logger == nil {
+		return
+	}
+
+	switch event.Kind {
+	case mkv.EventStart:
+		e.logger.Debug("stage started", "stage", event.Stage, "file", event.File)
+	case mkv.EventEnd:
+		e.logger.Debug("stage finished", "stage", event.Stage, "file", event.File, "error", event.Err)
+	case mkv.EventTrack:
+		e.logger.Info("extracted track", "track", event.TrackNumber, "language", event.Track.Properties.Language, "output", event.OutFileName)
+	case mkv.EventInfo, mkv.EventSuccess:
+		e.logger.Info(event.Message, "file", event.File)
+	case mkv.EventWarning:
+		e.logger.Warn(event.Message, "file", event.File)
+	case mkv.EventError:
+		e.logger.Error(event.Message, "file", event.File)
+	}
+}